@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/shuvava/go-logging/logger"
+
+	"github.com/shuvava/ota-tuf-server/pkg/encryption/remote"
+	"github.com/shuvava/ota-tuf-server/pkg/version"
+)
+
+// tuf-signer is the reference remote signer daemon keeping private keys in local file store
+func main() {
+	socketPath := flag.String("socket", "/run/tuf-signer/signer.sock", "path of Unix socket to listen on")
+	keysDir := flag.String("keys", "/var/lib/tuf-signer/keys", "directory of the key store")
+	flag.Parse()
+
+	log := logger.NewLogrusLogger(logrus.InfoLevel)
+	log.Info(fmt.Sprintf("Starting tuf-signer/%s", version.Version))
+	log.Info(fmt.Sprintf("	Build date: %s", version.BuildDate))
+	log.Info(fmt.Sprintf("	Commit hash: %s", version.CommitHash))
+
+	store, err := remote.NewFileKeyStore(*keysDir)
+	if err != nil {
+		log.WithError(err).
+			Fatal("Error on key store creating")
+	}
+	// socket of previous run is left if the process was killed
+	if err = os.Remove(*socketPath); err != nil && !os.IsNotExist(err) {
+		log.WithError(err).
+			Fatal("Error on stale socket removing")
+	}
+	listener, err := net.Listen("unix", *socketPath)
+	if err != nil {
+		log.WithError(err).
+			Fatal("Error on socket listening")
+	}
+	// only processes of the same user are allowed to sign
+	if err = os.Chmod(*socketPath, 0600); err != nil {
+		log.WithError(err).
+			Fatal("Error on socket permissions setting")
+	}
+
+	server := &http.Server{
+		Handler:           remote.NewHandler(log, store),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			log.WithError(err).
+				Fatal("Fatal error in signer server")
+		}
+	}()
+	log.WithField("Socket", *socketPath).
+		Info("Signer start listening")
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, os.Interrupt)
+	<-quit
+	ctx, cancelShutdown := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancelShutdown()
+	if err = server.Shutdown(ctx); err != nil {
+		log.WithError(err).
+			Fatal("Error shutting down signer server")
+	}
+}
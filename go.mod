@@ -14,6 +14,7 @@ require (
 require (
 	github.com/labstack/echo-contrib v0.12.0
 	github.com/labstack/echo/v4 v4.6.3
+	github.com/miekg/pkcs11 v1.1.1
 )
 
 require (
@@ -0,0 +1,50 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	cmnapi "github.com/shuvava/go-ota-svc-common/api"
+	"github.com/shuvava/go-ota-svc-common/apperrors"
+
+	"github.com/shuvava/ota-tuf-server/pkg/errcodes"
+)
+
+// newErrorResponse sends error response with HTTP status code matching to the error code
+func newErrorResponse(ctx echo.Context, err error) error {
+	c := cmnapi.GetRequestContext(ctx)
+	statusCode := errorStatusCode(err)
+	return ctx.JSON(statusCode, cmnapi.NewErrorResponse(c, statusCode, err))
+}
+
+// errorStatusCode returns HTTP status code matching to apperrors.AppError code
+func errorStatusCode(err error) int {
+	var typedErr apperrors.AppError
+	if !errors.As(err, &typedErr) {
+		return http.StatusInternalServerError
+	}
+	code := string(typedErr.ErrorCode)
+	switch {
+	case strings.HasPrefix(code, apperrors.ErrorDbNoDocumentFound),
+		strings.HasPrefix(code, errcodes.ErrorSvcRepoNotFound),
+		strings.HasPrefix(code, errcodes.ErrorSvcTargetNotFound),
+		strings.HasPrefix(code, errcodes.ErrorFsBlobNotFound):
+		return http.StatusNotFound
+	case strings.HasPrefix(code, apperrors.ErrorDbAlreadyExist),
+		strings.HasPrefix(code, apperrors.ErrorSvcEntityExists),
+		strings.HasPrefix(code, errcodes.ErrorSvcKeyGenStatus):
+		return http.StatusConflict
+	case strings.HasPrefix(code, errcodes.ErrorSvcKeyExported):
+		return http.StatusGone
+	case strings.HasPrefix(code, errcodes.ErrorDataSigningNoPrivateKey),
+		strings.HasPrefix(code, errcodes.ErrorSvcChecksumMismatch):
+		return http.StatusPreconditionFailed
+	case strings.HasPrefix(code, apperrors.ErrorDataValidation),
+		strings.HasPrefix(code, apperrors.ErrorDataSerialization):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
@@ -1,31 +1,89 @@
 package api
 
 import (
+	"encoding/json"
+	"io"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/labstack/echo/v4"
 
 	cmnapi "github.com/shuvava/go-ota-svc-common/api"
+	"github.com/shuvava/go-ota-svc-common/apperrors"
 
 	"github.com/shuvava/ota-tuf-server/pkg/data"
 	"github.com/shuvava/ota-tuf-server/pkg/services"
 )
 
 const (
-	pathRepoID = "repoID"
-	//PathCreateRoot is the path to create a new key repository
-	PathCreateRoot = "/root/:" + pathRepoID
+	pathRepoID  = "repoID"
+	pathVersion = "version"
+	pathKeyID   = "keyID"
+	// pathRole shares the path segment with pathVersion,
+	// echo router keeps the only param name for routes of the same path
+	pathRole = pathVersion
+	//PathRoot is the path of a key repository root role
+	PathRoot = "/root/:" + pathRepoID
+	//PathRootVersion is the path of published version of a key repository root role
+	PathRootVersion = PathRoot + "/:" + pathVersion
+	//PathRootRole is the path to sign payload with keys of a key repository role
+	PathRootRole = PathRoot + "/:" + pathRole
+	//PathRootRoleRotate is the path to rotate keys of a key repository role
+	PathRootRoleRotate = PathRootRole + "/rotate"
+	//PathRootUnsigned is the path of the next version of a key repository root role to be signed offline
+	PathRootUnsigned = PathRoot + "/unsigned"
+	//PathRootPrivateKey is the path of private part of a key repository key
+	PathRootPrivateKey = PathRoot + "/private_keys/:" + pathKeyID
+	//PathRootKeyGen is the path of the key generation request of a key repository
+	PathRootKeyGen = PathRoot + "/key_gen"
+	//PathRootKeyGenRetry is the path to retry failed key generation request of a key repository
+	PathRootKeyGenRetry = PathRootKeyGen + "/retry"
+	//PathRootVersions is the path of the list of published versions of a key repository root role
+	PathRootVersions = PathRoot + "/versions"
 )
 
 type (
 	rootGenRequest struct {
+		// Threshold is the default threshold of roles missing in Roles
 		Threshold int          `json:"threshold,omitempty"`
 		KeyType   data.KeyType `json:"keyType,omitempty"`
+		// Backend is the default key backend of roles, keys are stored in the service database if empty
+		Backend data.KeyBackend                  `json:"backend,omitempty"`
+		Roles   map[data.RoleType]roleGenRequest `json:"roles,omitempty"`
+		// KeyEncoding is the encoding of keys in published metadata: tuf (default), aktualizr or python-tuf
+		KeyEncoding data.KeyEncoding `json:"keyEncoding,omitempty"`
+	}
+	roleGenRequest struct {
+		Threshold int `json:"threshold,omitempty"`
+		// KeyCount is the number of keys generated for the role, defaults to Threshold
+		KeyCount int `json:"keyCount,omitempty"`
+		// KeyType is the type of keys generated for the role, e.g. rsa-4096 or ecdsa-p384, overrides KeyType of the repository
+		KeyType data.KeyType `json:"keyType,omitempty"`
+		// Backend is the key backend of the role, overrides default backend of the repository
+		Backend data.KeyBackend `json:"backend,omitempty"`
+	}
+	rotateKeysRequest struct {
+		// KeyType is the type of generated keys, used if Keys is empty
+		KeyType data.KeyType `json:"keyType,omitempty"`
+		// Keys is the list of keys replacing current role keys
+		Keys []data.Key `json:"keys,omitempty"`
+	}
+	keyGenResponse struct {
+		RepoID    data.RepoID       `json:"repoId"`
+		Status    data.KeyGenStatus `json:"status"`
+		Attempts  int               `json:"attempts"`
+		Error     string            `json:"error,omitempty"`
+		CreatedAt time.Time         `json:"createdAt"`
+		UpdatedAt time.Time         `json:"updatedAt"`
+	}
+	rootVersionsResponse struct {
+		Versions []int `json:"versions"`
 	}
 )
 
-// CreateRoot creates a new TUF key repository
-func CreateRoot(ctx echo.Context, svc *services.RepositoryService) error {
+// CreateRoot requests creation of a new TUF key repository, keys are generated in background
+func CreateRoot(ctx echo.Context, svc *services.KeyGenService) error {
 	c := cmnapi.GetRequestContext(ctx)
 	repoID, err := getRepoID(ctx)
 	if err != nil {
@@ -38,14 +96,271 @@ func CreateRoot(ctx echo.Context, svc *services.RepositoryService) error {
 	if err = ctx.Bind(genReq); err != nil {
 		return ctx.JSON(http.StatusBadRequest, cmnapi.NewErrorResponse(c, http.StatusBadRequest, err))
 	}
-	err = svc.CreateNewRepository(c, repoID, genReq.KeyType)
+	repo, err := genReq.toRepo(repoID)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, cmnapi.NewErrorResponse(c, http.StatusBadRequest, err))
+	}
+	req, err := svc.RequestKeyGeneration(c, repo)
+	if err != nil {
+		return newErrorResponse(ctx, err)
+	}
+	ctx.Response().Header().Set(echo.HeaderLocation, ctx.Request().URL.Path+"/key_gen")
+	return ctx.JSON(http.StatusAccepted, newKeyGenResponse(req))
+}
+
+// GetKeyGenRequest returns status of the key generation request of TUF key repository
+func GetKeyGenRequest(ctx echo.Context, svc *services.KeyGenService) error {
+	c := cmnapi.GetRequestContext(ctx)
+	repoID, err := getRepoID(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, cmnapi.NewErrorResponse(c, http.StatusBadRequest, err))
+	}
+	req, err := svc.GetKeyGenRequest(c, repoID)
+	if err != nil {
+		return newErrorResponse(ctx, err)
+	}
+	return ctx.JSON(http.StatusOK, newKeyGenResponse(req))
+}
+
+// RetryKeyGenRequest requeues failed key generation request of TUF key repository
+func RetryKeyGenRequest(ctx echo.Context, svc *services.KeyGenService) error {
+	c := cmnapi.GetRequestContext(ctx)
+	repoID, err := getRepoID(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, cmnapi.NewErrorResponse(c, http.StatusBadRequest, err))
+	}
+	req, err := svc.RetryKeyGeneration(c, repoID)
+	if err != nil {
+		return newErrorResponse(ctx, err)
+	}
+	return ctx.JSON(http.StatusAccepted, newKeyGenResponse(req))
+}
+
+// GetRoot returns the latest version of signed root role metadata of TUF key repository
+func GetRoot(ctx echo.Context, svc *services.RootRoleService) error {
+	c := cmnapi.GetRequestContext(ctx)
+	repoID, err := getRepoID(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, cmnapi.NewErrorResponse(c, http.StatusBadRequest, err))
+	}
+	root, err := svc.GetSignedRoot(c, repoID)
+	if err != nil {
+		return newErrorResponse(ctx, err)
+	}
+	return ctx.JSON(http.StatusOK, root.Content)
+}
+
+// GetRootVersion returns published version of signed root role metadata of TUF key repository
+func GetRootVersion(ctx echo.Context, svc *services.RootRoleService) error {
+	c := cmnapi.GetRequestContext(ctx)
+	repoID, err := getRepoID(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, cmnapi.NewErrorResponse(c, http.StatusBadRequest, err))
+	}
+	version, err := getVersion(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, cmnapi.NewErrorResponse(c, http.StatusBadRequest, err))
+	}
+	root, err := svc.GetSignedRootVersion(c, repoID, version)
+	if err != nil {
+		return newErrorResponse(ctx, err)
+	}
+	return ctx.JSON(http.StatusOK, root.Content)
+}
+
+// ListRootVersions returns the list of published versions of root role metadata of TUF key repository
+func ListRootVersions(ctx echo.Context, svc *services.RootRoleService) error {
+	c := cmnapi.GetRequestContext(ctx)
+	repoID, err := getRepoID(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, cmnapi.NewErrorResponse(c, http.StatusBadRequest, err))
+	}
+	versions, err := svc.ListRootVersions(c, repoID)
+	if err != nil {
+		return newErrorResponse(ctx, err)
+	}
+	return ctx.JSON(http.StatusOK, rootVersionsResponse{Versions: versions})
+}
+
+// GetUnsignedRoot returns signed portion of the next version of root role metadata of TUF key repository
+func GetUnsignedRoot(ctx echo.Context, svc *services.RootRoleService) error {
+	c := cmnapi.GetRequestContext(ctx)
+	repoID, err := getRepoID(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, cmnapi.NewErrorResponse(c, http.StatusBadRequest, err))
+	}
+	root, err := svc.GetUnsignedRoot(c, repoID)
+	if err != nil {
+		return newErrorResponse(ctx, err)
+	}
+	return ctx.JSON(http.StatusOK, root)
+}
+
+// PublishSignedRoot publishes the next version of root role metadata of TUF key repository signed offline
+func PublishSignedRoot(ctx echo.Context, svc *services.RootRoleService) error {
+	c := cmnapi.GetRequestContext(ctx)
+	repoID, err := getRepoID(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, cmnapi.NewErrorResponse(c, http.StatusBadRequest, err))
+	}
+	payload := &data.SignedPayload{}
+	if err = ctx.Bind(payload); err != nil {
+		return ctx.JSON(http.StatusBadRequest, cmnapi.NewErrorResponse(c, http.StatusBadRequest, err))
+	}
+	if len(payload.Signed) == 0 {
+		err = apperrors.NewAppError(apperrors.ErrorDataValidation, "signed portion of root role is missing")
+		return ctx.JSON(http.StatusBadRequest, cmnapi.NewErrorResponse(c, http.StatusBadRequest, err))
+	}
+	root, err := svc.PublishSignedRoot(c, repoID, *payload)
+	if err != nil {
+		return newErrorResponse(ctx, err)
+	}
+	return ctx.JSON(http.StatusOK, root.Content)
+}
+
+// DeletePrivateKey removes private part of TUF key repository key
+func DeletePrivateKey(ctx echo.Context, svc *services.RepositoryService) error {
+	c := cmnapi.GetRequestContext(ctx)
+	repoID, err := getRepoID(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, cmnapi.NewErrorResponse(c, http.StatusBadRequest, err))
+	}
+	keyID, err := data.KeyIDFromString(ctx.Param(pathKeyID))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, cmnapi.NewErrorResponse(c, http.StatusBadRequest, err))
+	}
+	if err = svc.DeletePrivateKey(c, repoID, keyID); err != nil {
+		return newErrorResponse(ctx, err)
+	}
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// ExportPrivateKey returns TUF key repository key including private part, the key can be exported only once
+func ExportPrivateKey(ctx echo.Context, svc *services.RepositoryService) error {
+	c := cmnapi.GetRequestContext(ctx)
+	repoID, err := getRepoID(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, cmnapi.NewErrorResponse(c, http.StatusBadRequest, err))
+	}
+	keyID, err := data.KeyIDFromString(ctx.Param(pathKeyID))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, cmnapi.NewErrorResponse(c, http.StatusBadRequest, err))
+	}
+	key, err := svc.ExportPrivateKey(c, repoID, keyID)
 	if err != nil {
-		return ctx.JSON(http.StatusInternalServerError, cmnapi.NewErrorResponse(c, http.StatusInternalServerError, err))
+		return newErrorResponse(ctx, err)
+	}
+	ctx.Response().Header().Set("Cache-Control", "no-store")
+	return ctx.JSON(http.StatusOK, key)
+}
+
+// toRepo converts the request to data.Repo
+func (r *rootGenRequest) toRepo(repoID data.RepoID) (data.Repo, error) {
+	repo := data.NewRepo(repoID, r.KeyType)
+	repo.KeyEncoding = r.KeyEncoding
+	for role := range repo.Roles {
+		repo.Roles[role] = data.RoleConfig{Threshold: r.Threshold, KeyCount: r.Threshold, Backend: r.Backend}
+	}
+	for name, roleReq := range r.Roles {
+		role, err := data.NewRoleType(string(name))
+		if err != nil {
+			return repo, err
+		}
+		cfg := repo.Roles[role]
+		if roleReq.Threshold != 0 {
+			cfg.Threshold = roleReq.Threshold
+			cfg.KeyCount = roleReq.Threshold
+		}
+		if roleReq.KeyCount != 0 {
+			cfg.KeyCount = roleReq.KeyCount
+		}
+		if roleReq.KeyType != "" {
+			cfg.KeyType = roleReq.KeyType
+		}
+		if roleReq.Backend != "" {
+			cfg.Backend = roleReq.Backend
+		}
+		repo.Roles[role] = cfg
+	}
+	return repo, nil
+}
+
+// SignRolePayload signs JSON payload with private keys of TUF key repository role
+func SignRolePayload(ctx echo.Context, svc *services.RepositoryService) error {
+	c := cmnapi.GetRequestContext(ctx)
+	repoID, err := getRepoID(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, cmnapi.NewErrorResponse(c, http.StatusBadRequest, err))
+	}
+	role, err := data.NewRoleType(ctx.Param(pathRole))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, cmnapi.NewErrorResponse(c, http.StatusBadRequest, err))
+	}
+	payload, err := io.ReadAll(ctx.Request().Body)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, cmnapi.NewErrorResponse(c, http.StatusBadRequest, err))
+	}
+	if !json.Valid(payload) {
+		err = apperrors.NewAppError(apperrors.ErrorDataValidation, "payload is not valid JSON document")
+		return ctx.JSON(http.StatusBadRequest, cmnapi.NewErrorResponse(c, http.StatusBadRequest, err))
+	}
+	sigs, err := svc.SignRolePayload(c, repoID, role, payload)
+	if err != nil {
+		return newErrorResponse(ctx, err)
+	}
+	return ctx.JSON(http.StatusOK, sigs)
+}
+
+// RotateRoleKeys replaces keys of TUF key repository role and returns the next version of signed root role metadata,
+// metadata of the role and roles describing it is re-signed by the new keys
+func RotateRoleKeys(ctx echo.Context, svc *services.RootRoleService, targetsSvc *services.TargetsService) error {
+	c := cmnapi.GetRequestContext(ctx)
+	repoID, err := getRepoID(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, cmnapi.NewErrorResponse(c, http.StatusBadRequest, err))
+	}
+	role, err := data.NewRoleType(ctx.Param(pathRole))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, cmnapi.NewErrorResponse(c, http.StatusBadRequest, err))
+	}
+	rotateReq := &rotateKeysRequest{}
+	if err = ctx.Bind(rotateReq); err != nil {
+		return ctx.JSON(http.StatusBadRequest, cmnapi.NewErrorResponse(c, http.StatusBadRequest, err))
+	}
+	root, err := svc.RotateKeys(c, repoID, services.RotateKeysRequest{
+		Role:    role,
+		KeyType: rotateReq.KeyType,
+		Keys:    rotateReq.Keys,
+	})
+	if err != nil {
+		return newErrorResponse(ctx, err)
+	}
+	if err = targetsSvc.RefreshRotatedRole(c, repoID, role); err != nil {
+		return newErrorResponse(ctx, err)
+	}
+	return ctx.JSON(http.StatusOK, root.Content)
+}
+
+func newKeyGenResponse(req *data.KeyGenRequest) keyGenResponse {
+	return keyGenResponse{
+		RepoID:    req.Repo.RepoID,
+		Status:    req.Status,
+		Attempts:  req.Attempts,
+		Error:     req.Error,
+		CreatedAt: req.CreatedAt,
+		UpdatedAt: req.UpdatedAt,
 	}
-	return ctx.NoContent(http.StatusOK)
 }
 
 func getRepoID(ctx echo.Context) (data.RepoID, error) {
 	repoID := ctx.Param(pathRepoID)
 	return data.RepoIDFromString(repoID)
 }
+
+func getVersion(ctx echo.Context) (int, error) {
+	version, err := strconv.Atoi(ctx.Param(pathVersion))
+	if err != nil || version < 1 {
+		return 0, apperrors.NewAppError(apperrors.ErrorDataValidation, "invalid version '"+ctx.Param(pathVersion)+"'")
+	}
+	return version, nil
+}
@@ -0,0 +1,28 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	cmnapi "github.com/shuvava/go-ota-svc-common/api"
+
+	"github.com/shuvava/ota-tuf-server/pkg/services"
+)
+
+//PathRepoResignRecords is the path of outcomes of background re-signing of a TUF repository metadata
+const PathRepoResignRecords = PathRepo + "/resign_records"
+
+// GetResignRecords returns outcomes of background re-signing of TUF repository metadata, the newest records first
+func GetResignRecords(ctx echo.Context, svc *services.ResignService) error {
+	c := cmnapi.GetRequestContext(ctx)
+	repoID, err := getRepoID(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, cmnapi.NewErrorResponse(c, http.StatusBadRequest, err))
+	}
+	records, err := svc.GetResignRecords(c, repoID)
+	if err != nil {
+		return newErrorResponse(ctx, err)
+	}
+	return ctx.JSON(http.StatusOK, records)
+}
@@ -0,0 +1,191 @@
+package api
+
+import (
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	cmnapi "github.com/shuvava/go-ota-svc-common/api"
+	"github.com/shuvava/go-ota-svc-common/apperrors"
+
+	"github.com/shuvava/ota-tuf-server/pkg/data"
+	"github.com/shuvava/ota-tuf-server/pkg/services"
+)
+
+const (
+	// pathTarget is the wildcard param of target path, target paths may contain slashes
+	pathTarget   = "*"
+	pathFileName = "filename"
+	// headers of conditional requests, they are not defined by echo
+	headerETag    = "ETag"
+	headerIfMatch = "If-Match"
+	//PathRepo is the path of a TUF repository
+	PathRepo = "/repo/:" + pathRepoID
+	//PathRepoMetadata is the path of the latest version of signed top-level role metadata file of a TUF repository,
+	//e.g. timestamp.json
+	PathRepoMetadata = PathRepo + "/:" + pathFileName
+	//PathRepoTargetsMetadata is the path of targets role metadata file of a TUF repository signed offline
+	PathRepoTargetsMetadata = PathRepo + "/targets.json"
+	//PathRepoTargetsUnsigned is the path of signed portion of targets role metadata of a TUF repository to sign offline,
+	//it is not under target files path, so any target path is available
+	PathRepoTargetsUnsigned = PathRepoTargetsMetadata + "/unsigned"
+	//PathRepoTarget is the path of a target file of a TUF repository and its description in targets role metadata
+	PathRepoTarget = PathRepo + "/targets/" + pathTarget
+)
+
+// GetMetadata returns the latest version of signed top-level role metadata file of TUF repository,
+// the file content is served as it is described by snapshot and timestamp metadata
+func GetMetadata(ctx echo.Context, rootSvc *services.RootRoleService, svc *services.TargetsService) error {
+	c := cmnapi.GetRequestContext(ctx)
+	repoID, err := getRepoID(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, cmnapi.NewErrorResponse(c, http.StatusBadRequest, err))
+	}
+	role, err := data.RoleTypeFromMetaFileName(ctx.Param(pathFileName))
+	if err != nil {
+		return ctx.JSON(http.StatusNotFound, cmnapi.NewErrorResponse(c, http.StatusNotFound, err))
+	}
+	var signed *data.SignedRole
+	switch role {
+	case data.RoleTypeRoot:
+		signed, err = rootSvc.GetSignedRoot(c, repoID)
+	case data.RoleTypeTargets:
+		signed, err = svc.GetSignedTargets(c, repoID)
+	case data.RoleTypeSnapshot:
+		signed, err = svc.GetSignedSnapshot(c, repoID)
+	case data.RoleTypeTimestamp:
+		signed, err = svc.GetSignedTimestamp(c, repoID)
+	}
+	if err != nil {
+		return newErrorResponse(ctx, err)
+	}
+	content, err := signed.Content.FileContent()
+	if err != nil {
+		return newErrorResponse(ctx, err)
+	}
+	return ctx.JSONBlob(http.StatusOK, content)
+}
+
+// PutTarget adds or replaces target file of TUF repository and returns the next version of signed targets role metadata.
+// JSON request body is the description of the target file, any other request body is the content of the target file,
+// it is stored and described by its length and digests.
+func PutTarget(ctx echo.Context, svc *services.TargetsService, fileSvc *services.TargetFileService) error {
+	c := cmnapi.GetRequestContext(ctx)
+	repoID, err := getRepoID(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, cmnapi.NewErrorResponse(c, http.StatusBadRequest, err))
+	}
+	targetPath, err := getTargetPath(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, cmnapi.NewErrorResponse(c, http.StatusBadRequest, err))
+	}
+	var targets *data.SignedRole
+	if strings.HasPrefix(ctx.Request().Header.Get(echo.HeaderContentType), echo.MIMEApplicationJSON) {
+		meta := &data.TargetFileMeta{}
+		if err = ctx.Bind(meta); err != nil {
+			return ctx.JSON(http.StatusBadRequest, cmnapi.NewErrorResponse(c, http.StatusBadRequest, err))
+		}
+		targets, err = svc.PutTarget(c, repoID, targetPath, *meta)
+	} else {
+		req := ctx.Request()
+		targets, err = fileSvc.UploadTarget(c, repoID, targetPath, req.Body, req.ContentLength)
+	}
+	if err != nil {
+		return newErrorResponse(ctx, err)
+	}
+	return ctx.JSON(http.StatusOK, targets.Content)
+}
+
+// GetTarget returns content of target file of TUF repository, range requests are supported
+func GetTarget(ctx echo.Context, svc *services.TargetFileService) error {
+	c := cmnapi.GetRequestContext(ctx)
+	repoID, err := getRepoID(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, cmnapi.NewErrorResponse(c, http.StatusBadRequest, err))
+	}
+	targetPath, err := getTargetPath(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, cmnapi.NewErrorResponse(c, http.StatusBadRequest, err))
+	}
+	blob, err := svc.OpenTarget(c, repoID, targetPath)
+	if err != nil {
+		return newErrorResponse(ctx, err)
+	}
+	defer blob.Close()
+	ctx.Response().Header().Set(echo.HeaderContentType, echo.MIMEOctetStream)
+	http.ServeContent(ctx.Response(), ctx.Request(), path.Base(targetPath), blob.ModTime(), blob)
+	return nil
+}
+
+// DeleteTarget removes target file of TUF repository and returns the next version of signed targets role metadata
+func DeleteTarget(ctx echo.Context, svc *services.TargetFileService) error {
+	c := cmnapi.GetRequestContext(ctx)
+	repoID, err := getRepoID(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, cmnapi.NewErrorResponse(c, http.StatusBadRequest, err))
+	}
+	targetPath, err := getTargetPath(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, cmnapi.NewErrorResponse(c, http.StatusBadRequest, err))
+	}
+	targets, err := svc.DeleteTarget(c, repoID, targetPath)
+	if err != nil {
+		return newErrorResponse(ctx, err)
+	}
+	return ctx.JSON(http.StatusOK, targets.Content)
+}
+
+// GetUnsignedTargets returns signed portion of the latest version of targets role metadata of TUF repository
+// and its checksum, the checksum is also returned in ETag header
+func GetUnsignedTargets(ctx echo.Context, svc *services.TargetsService) error {
+	c := cmnapi.GetRequestContext(ctx)
+	repoID, err := getRepoID(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, cmnapi.NewErrorResponse(c, http.StatusBadRequest, err))
+	}
+	targets, err := svc.GetUnsignedTargets(c, repoID)
+	if err != nil {
+		return newErrorResponse(ctx, err)
+	}
+	ctx.Response().Header().Set(headerETag, `"`+targets.Checksum+`"`)
+	return ctx.JSON(http.StatusOK, targets)
+}
+
+// PublishSignedTargets publishes the next version of targets role metadata of TUF repository signed offline,
+// If-Match header is the checksum of the version the metadata is based on
+func PublishSignedTargets(ctx echo.Context, svc *services.TargetsService) error {
+	c := cmnapi.GetRequestContext(ctx)
+	repoID, err := getRepoID(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, cmnapi.NewErrorResponse(c, http.StatusBadRequest, err))
+	}
+	checksum := strings.Trim(strings.TrimPrefix(ctx.Request().Header.Get(headerIfMatch), "W/"), `"`)
+	if checksum == "" {
+		err = apperrors.NewAppError(apperrors.ErrorDataValidation, "If-Match header with targets checksum is required")
+		return ctx.JSON(http.StatusPreconditionRequired, cmnapi.NewErrorResponse(c, http.StatusPreconditionRequired, err))
+	}
+	payload := &data.SignedPayload{}
+	if err = ctx.Bind(payload); err != nil {
+		return ctx.JSON(http.StatusBadRequest, cmnapi.NewErrorResponse(c, http.StatusBadRequest, err))
+	}
+	if len(payload.Signed) == 0 {
+		err = apperrors.NewAppError(apperrors.ErrorDataValidation, "signed portion of targets role is missing")
+		return ctx.JSON(http.StatusBadRequest, cmnapi.NewErrorResponse(c, http.StatusBadRequest, err))
+	}
+	targets, err := svc.PublishSignedTargets(c, repoID, *payload, checksum)
+	if err != nil {
+		return newErrorResponse(ctx, err)
+	}
+	return ctx.JSON(http.StatusOK, targets.Content)
+}
+
+func getTargetPath(ctx echo.Context) (string, error) {
+	targetPath, err := url.PathUnescape(ctx.Param(pathTarget))
+	if err != nil {
+		return "", apperrors.CreateError(apperrors.ErrorDataValidation, "invalid target path", err)
+	}
+	return targetPath, data.ValidateTargetPath(targetPath)
+}
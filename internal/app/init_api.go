@@ -2,6 +2,8 @@ package app
 
 import (
 	"context"
+	"crypto/subtle"
+	"net/http"
 
 	"github.com/labstack/echo-contrib/prometheus"
 	"github.com/labstack/echo/v4"
@@ -30,12 +32,18 @@ func (s *Server) initWebServer() {
 	e.Use(middleware.LoggerWithConfig(middleware.LoggerConfig{
 		Format: "method=${method}, uri=${uri}, status=${status}\n",
 	}))
-	e.Use(middleware.Gzip())
+	e.Use(middleware.GzipWithConfig(middleware.GzipConfig{
+		// target files are served as they are stored to keep range requests valid
+		Skipper: func(c echo.Context) bool {
+			return c.Request().Method == http.MethodGet && c.Path() == routeAPIVer1+api.PathRepoTarget
+		},
+	}))
 	// Server header
 	e.Use(cmnapi.ServerHeader(version.AppName, version.Version))
 	initHealthRoutes(s, e)
 	v1Group := e.Group(routeAPIVer1, middleware.RequestID())
 	initKeyRepoRoutes(s, v1Group)
+	initRepoRoutes(s, v1Group)
 
 	// Enable metrics middleware
 	p := prometheus.NewPrometheus("echo", nil)
@@ -45,9 +53,84 @@ func (s *Server) initWebServer() {
 }
 
 func initKeyRepoRoutes(s *Server, group *echo.Group) {
-	group.POST(api.PathCreateRoot, func(c echo.Context) error {
-		return api.CreateRoot(c, s.svc.KeySvc)
+	group.POST(api.PathRoot, func(c echo.Context) error {
+		return api.CreateRoot(c, s.svc.KeyGenSvc)
+	})
+	group.GET(api.PathRootKeyGen, func(c echo.Context) error {
+		return api.GetKeyGenRequest(c, s.svc.KeyGenSvc)
+	})
+	group.POST(api.PathRootKeyGenRetry, func(c echo.Context) error {
+		return api.RetryKeyGenRequest(c, s.svc.KeyGenSvc)
+	})
+	group.GET(api.PathRoot, func(c echo.Context) error {
+		return api.GetRoot(c, s.svc.RootSvc)
+	})
+	group.PUT(api.PathRoot, func(c echo.Context) error {
+		return api.PublishSignedRoot(c, s.svc.RootSvc)
+	})
+	group.GET(api.PathRootUnsigned, func(c echo.Context) error {
+		return api.GetUnsignedRoot(c, s.svc.RootSvc)
 	})
+	group.DELETE(api.PathRootPrivateKey, func(c echo.Context) error {
+		return api.DeletePrivateKey(c, s.svc.KeySvc)
+	})
+	group.GET(api.PathRootPrivateKey, func(c echo.Context) error {
+		return api.ExportPrivateKey(c, s.svc.KeySvc)
+	}, s.keyExportAuth)
+	group.GET(api.PathRootVersions, func(c echo.Context) error {
+		return api.ListRootVersions(c, s.svc.RootSvc)
+	})
+	group.GET(api.PathRootVersion, func(c echo.Context) error {
+		return api.GetRootVersion(c, s.svc.RootSvc)
+	})
+	group.POST(api.PathRootRole, func(c echo.Context) error {
+		return api.SignRolePayload(c, s.svc.KeySvc)
+	})
+	group.POST(api.PathRootRoleRotate, func(c echo.Context) error {
+		return api.RotateRoleKeys(c, s.svc.RootSvc, s.svc.TargetsSvc)
+	})
+}
+
+func initRepoRoutes(s *Server, group *echo.Group) {
+	group.GET(api.PathRepoMetadata, func(c echo.Context) error {
+		return api.GetMetadata(c, s.svc.RootSvc, s.svc.TargetsSvc)
+	})
+	group.GET(api.PathRepoTargetsUnsigned, func(c echo.Context) error {
+		return api.GetUnsignedTargets(c, s.svc.TargetsSvc)
+	})
+	group.PUT(api.PathRepoTargetsMetadata, func(c echo.Context) error {
+		return api.PublishSignedTargets(c, s.svc.TargetsSvc)
+	})
+	group.GET(api.PathRepoResignRecords, func(c echo.Context) error {
+		return api.GetResignRecords(c, s.svc.ResignSvc)
+	})
+	group.GET(api.PathRepoTarget, func(c echo.Context) error {
+		return api.GetTarget(c, s.svc.TargetFileSvc)
+	})
+	group.PUT(api.PathRepoTarget, func(c echo.Context) error {
+		return api.PutTarget(c, s.svc.TargetsSvc, s.svc.TargetFileSvc)
+	})
+	group.DELETE(api.PathRepoTarget, func(c echo.Context) error {
+		return api.DeleteTarget(c, s.svc.TargetFileSvc)
+	})
+}
+
+// keyExportAuth guards private key export by bearer token,
+// the export is not available if token is not configured
+func (s *Server) keyExportAuth(next echo.HandlerFunc) echo.HandlerFunc {
+	auth := middleware.KeyAuth(s.validateKeyExportToken)(next)
+	return func(c echo.Context) error {
+		if s.config.Security.KeyExportToken == "" {
+			return echo.ErrNotFound
+		}
+		return auth(c)
+	}
+}
+
+// validateKeyExportToken checks bearer token of private key export requests
+func (s *Server) validateKeyExportToken(key string, _ echo.Context) (bool, error) {
+	token := s.config.Security.KeyExportToken
+	return subtle.ConstantTimeCompare([]byte(key), []byte(token)) == 1, nil
 }
 
 func initHealthRoutes(s *Server, e *echo.Echo) {
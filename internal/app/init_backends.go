@@ -0,0 +1,65 @@
+package app
+
+import (
+	"github.com/shuvava/ota-tuf-server/pkg/encryption"
+	"github.com/shuvava/ota-tuf-server/pkg/encryption/remote"
+	"github.com/shuvava/ota-tuf-server/pkg/encryption/vault"
+)
+
+// initKeyBackends registers key backends enabled in config
+func (s *Server) initKeyBackends() {
+	s.closeKeyBackends()
+	s.initPKCS11Backend()
+	s.initRemoteBackend()
+	s.initVaultBackend()
+}
+
+// initRemoteBackend registers remote signer key backend if it is configured
+func (s *Server) initRemoteBackend() {
+	cfg := s.config.Signing.Remote
+	if cfg.Socket == "" {
+		return
+	}
+	client := remote.NewClient(cfg.Socket)
+	encryption.RegisterBackend(remote.BackendName, client)
+	s.svc.KeyBackends = append(s.svc.KeyBackends, client)
+	s.log.SetOperation("server-init-remote-signer").
+		WithField("Socket", cfg.Socket).
+		Info("Remote signer key backend registered")
+}
+
+// initVaultBackend registers Vault transit key backend if it is configured
+func (s *Server) initVaultBackend() {
+	log := s.log.SetOperation("server-init-vault")
+	cfg := s.config.Signing.Vault
+	if cfg.Address == "" {
+		return
+	}
+	backend, err := vault.New(vault.Config{
+		Address:    cfg.Address,
+		Token:      cfg.Token,
+		Namespace:  cfg.Namespace,
+		Mount:      cfg.Mount,
+		RSAKeySize: cfg.RSAKeySize,
+	})
+	if err != nil {
+		log.WithError(err).
+			Fatal("Error on Vault backend creating")
+	}
+	encryption.RegisterBackend(vault.BackendName, backend)
+	s.svc.KeyBackends = append(s.svc.KeyBackends, backend)
+	log.WithField("Address", cfg.Address).
+		Info("Vault key backend registered")
+}
+
+// closeKeyBackends releases resources of registered key backends
+func (s *Server) closeKeyBackends() {
+	log := s.log.SetOperation("server-close-backends")
+	for _, backend := range s.svc.KeyBackends {
+		if err := backend.Close(); err != nil {
+			log.WithError(err).
+				Error("Error on key backend closing")
+		}
+	}
+	s.svc.KeyBackends = nil
+}
@@ -0,0 +1,30 @@
+//go:build pkcs11
+
+package app
+
+import (
+	"github.com/shuvava/ota-tuf-server/pkg/encryption"
+	"github.com/shuvava/ota-tuf-server/pkg/encryption/pkcs11"
+)
+
+// initPKCS11Backend registers PKCS#11 key backend if it is configured
+func (s *Server) initPKCS11Backend() {
+	log := s.log.SetOperation("server-init-pkcs11")
+	cfg := s.config.Signing.PKCS11
+	if cfg.Module == "" {
+		return
+	}
+	backend, err := pkcs11.New(pkcs11.Config{
+		Module:     cfg.Module,
+		TokenLabel: cfg.TokenLabel,
+		Pin:        cfg.Pin,
+	})
+	if err != nil {
+		log.WithError(err).
+			Fatal("Error on PKCS#11 backend creating")
+	}
+	encryption.RegisterBackend(pkcs11.BackendName, backend)
+	s.svc.KeyBackends = append(s.svc.KeyBackends, backend)
+	log.WithField("Token", cfg.TokenLabel).
+		Info("PKCS#11 key backend registered")
+}
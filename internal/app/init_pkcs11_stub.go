@@ -0,0 +1,11 @@
+//go:build !pkcs11
+
+package app
+
+// initPKCS11Backend fails if PKCS#11 key backend is configured, the binary is built without PKCS#11 support
+func (s *Server) initPKCS11Backend() {
+	if s.config.Signing.PKCS11.Module != "" {
+		s.log.SetOperation("server-init-pkcs11").
+			Fatal("PKCS#11 key backend is configured, but the service is built without `pkcs11` tag")
+	}
+}
@@ -0,0 +1,66 @@
+package app
+
+import (
+	"io"
+	"strings"
+
+	"github.com/shuvava/ota-tuf-server/pkg/storage/local"
+	"github.com/shuvava/ota-tuf-server/pkg/storage/s3"
+)
+
+const (
+	storageTypeLocal = "local"
+	storageTypeS3    = "s3"
+)
+
+// initStorage creates blob storage of target files content
+func (s *Server) initStorage() {
+	log := s.log.SetOperation("server-init-storage")
+	s.closeStorage()
+	cfg := s.config.Storage
+	switch strings.ToLower(cfg.Type) {
+	case storageTypeLocal, "":
+		store, err := local.New(cfg.Local.Root)
+		if err != nil {
+			log.WithError(err).
+				Fatal("Error on local storage creating")
+		}
+		s.svc.Storage = store
+		log.WithField("Root", cfg.Local.Root).
+			Info("Local storage created")
+	case storageTypeS3:
+		store, err := s3.New(s3.Config{
+			Endpoint:  cfg.S3.Endpoint,
+			Region:    cfg.S3.Region,
+			Bucket:    cfg.S3.Bucket,
+			AccessKey: cfg.S3.AccessKey,
+			SecretKey: cfg.S3.SecretKey,
+			PathStyle: cfg.S3.PathStyle,
+		})
+		if err != nil {
+			log.WithError(err).
+				Fatal("Error on S3 storage creating")
+		}
+		s.svc.Storage = store
+		log.WithField("Endpoint", cfg.S3.Endpoint).
+			WithField("Bucket", cfg.S3.Bucket).
+			Info("S3 storage created")
+	default:
+		log.WithField("type", cfg.Type).
+			Fatal("Unsupported storage type")
+	}
+}
+
+// closeStorage releases resources of blob storage
+func (s *Server) closeStorage() {
+	closer, ok := s.svc.Storage.(io.Closer)
+	if !ok {
+		return
+	}
+	if err := closer.Close(); err != nil {
+		s.log.SetOperation("server-close-storage").
+			WithError(err).
+			Error("Error on storage closing")
+	}
+	s.svc.Storage = nil
+}
@@ -2,12 +2,18 @@ package app
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"os"
 	"strings"
 
+	"github.com/shuvava/ota-tuf-server/internal/db"
 	intDb "github.com/shuvava/ota-tuf-server/internal/db/mongo"
+	"github.com/shuvava/ota-tuf-server/internal/keyring"
+	"github.com/shuvava/ota-tuf-server/pkg/data"
 	"github.com/shuvava/ota-tuf-server/pkg/services"
 
-	"github.com/shuvava/go-ota-svc-common/db"
+	cmnDb "github.com/shuvava/go-ota-svc-common/db"
 	intCmnDb "github.com/shuvava/go-ota-svc-common/db/mongo"
 )
 
@@ -19,23 +25,125 @@ func (s *Server) initDbService() {
 				Fatal("Error on Db service distracting")
 		}
 	}
-	switch db.Type(strings.ToLower(s.config.Db.Type)) {
-	case db.MongoDb:
+	switch cmnDb.Type(strings.ToLower(s.config.Db.Type)) {
+	case cmnDb.MongoDb:
 		mongoDB, err := intCmnDb.NewMongoDB(context.Background(), s.log, s.config.Db.ConnectionString)
 		if err != nil {
 			log.WithError(err).
 				Fatal("Error on Db service creating")
 		}
+		if err = intDb.Migrate(context.Background(), s.log, mongoDB); err != nil {
+			log.WithError(err).
+				Fatal("Error on Db migration")
+		}
+		if err = intDb.EnsureIndexes(context.Background(), s.log, mongoDB); err != nil {
+			log.WithError(err).
+				Fatal("Error on Db indexes creation")
+		}
 		s.svc.Db = mongoDB
-		s.svc.KeyRepo = intDb.NewKeyMongoRepository(s.log, mongoDB)
+		s.svc.KeyRepo = s.sealKeyRepo(intDb.NewKeyMongoRepository(s.log, mongoDB))
+		s.svc.RepoRepo = intDb.NewRepoMongoRepository(s.log, mongoDB)
+		s.svc.SignedRoleRepo = intDb.NewSignedRoleMongoRepository(s.log, mongoDB)
+		s.svc.KeyGenRepo = intDb.NewKeyGenRequestMongoRepository(s.log, mongoDB)
+		s.svc.ResignRepo = intDb.NewResignRecordMongoRepository(s.log, mongoDB)
+		s.svc.LeaseRepo = intDb.NewLeaseMongoRepository(s.log, mongoDB)
 	default:
 		log.WithField("type", s.config.Db.Type).
 			Fatal("Unsupported mongoDB type")
 	}
 }
 
+// sealKeyRepo wraps key repository by decorator encrypting private keys if master key is configured
+func (s *Server) sealKeyRepo(repo db.KeyRepository) db.KeyRepository {
+	log := s.log.SetOperation("server-init-keyring")
+	cfg := s.config.Security.Encryption
+	if cfg.ActiveKeyID == "" {
+		log.Warn("Master key is not configured, private keys are stored in clear")
+		return repo
+	}
+	encoded := make(map[string]string, len(cfg.Keys))
+	for id, key := range cfg.Keys {
+		encoded[id] = key
+	}
+	if cfg.KeysFile != "" {
+		fileKeys, err := keyring.LoadKeysFile(cfg.KeysFile)
+		if err != nil {
+			log.WithError(err).
+				Fatal("Error on master keys loading")
+		}
+		for id, key := range fileKeys {
+			// config keys are case-insensitive
+			encoded[strings.ToLower(id)] = key
+		}
+	}
+	keys, err := keyring.ParseKeys(encoded)
+	if err != nil {
+		log.WithError(err).
+			Fatal("Error on master keys parsing")
+	}
+	kr, err := keyring.New(strings.ToLower(cfg.ActiveKeyID), keys)
+	if err != nil {
+		log.WithError(err).
+			Fatal("Error on keyring creating")
+	}
+	sealed := db.NewSealedKeyRepository(s.log, repo, kr)
+	// rewraps data keys after master key change and seals keys stored in clear
+	if _, err = sealed.RotateMasterKey(context.Background()); err != nil {
+		log.WithError(err).
+			Fatal("Error on master key rotation")
+	}
+	return sealed
+}
+
 // create all application services
 func (s *Server) initServices() {
+	if s.svc.KeyGenSvc != nil {
+		// workers use Db service, they have to be stopped before it is recreated
+		s.svc.KeyGenSvc.Stop()
+	}
+	if s.svc.ResignSvc != nil {
+		s.svc.ResignSvc.Stop()
+	}
 	s.initDbService()
-	s.svc.KeySvc = services.NewRepositoryService(s.log, s.svc.KeyRepo)
+	s.initKeyBackends()
+	s.initExpires()
+	s.initStorage()
+	s.svc.RootSvc = services.NewRootRoleService(s.log, s.svc.KeyRepo, s.svc.RepoRepo, s.svc.SignedRoleRepo)
+	s.svc.KeySvc = services.NewRepositoryService(s.log, s.svc.KeyRepo, s.svc.RepoRepo, s.svc.RootSvc)
+	s.svc.TargetsSvc = services.NewTargetsService(s.log, s.svc.SignedRoleRepo, s.svc.KeySvc)
+	s.svc.TargetFileSvc = services.NewTargetFileService(s.log, s.svc.TargetsSvc, s.svc.Storage)
+	s.svc.KeyGenSvc = services.NewKeyGenService(s.log, s.svc.KeyGenRepo, s.svc.KeySvc,
+		s.config.KeyGen.Workers, data.KeyBackend(s.config.Signing.DefaultBackend))
+	s.svc.KeyGenSvc.Start()
+	resign := s.config.Metadata.Resign
+	s.svc.ResignSvc = services.NewResignService(s.log, s.svc.SignedRoleRepo, s.svc.ResignRepo, s.svc.LeaseRepo,
+		s.svc.TargetsSvc, s.instanceID(), services.ResignSchedule{
+			Interval:  resign.Interval,
+			Threshold: resign.Threshold,
+			LeaseTTL:  resign.LeaseTTL,
+		})
+	s.svc.ResignSvc.Start()
+}
+
+// initExpires sets expiration periods of published role metadata
+func (s *Server) initExpires() {
+	cfg := s.config.Metadata.Expires
+	data.SetExpiresPeriod(data.RoleTypeRoot, cfg.Root)
+	data.SetExpiresPeriod(data.RoleTypeTargets, cfg.Targets)
+	data.SetExpiresPeriod(data.RoleTypeSnapshot, cfg.Snapshot)
+	data.SetExpiresPeriod(data.RoleTypeTimestamp, cfg.Timestamp)
+}
+
+// instanceID returns id of the server instance among instances sharing Db service
+func (s *Server) instanceID() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "tuf-server"
+	}
+	suffix := make([]byte, 4)
+	if _, err = rand.Read(suffix); err != nil {
+		s.log.WithError(err).
+			Fatal("Error on instance id generation")
+	}
+	return host + "-" + hex.EncodeToString(suffix)
 }
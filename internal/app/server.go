@@ -3,6 +3,7 @@ package app
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"os/signal"
 	"sync"
@@ -17,6 +18,7 @@ import (
 	"github.com/shuvava/ota-tuf-server/internal/config"
 	"github.com/shuvava/ota-tuf-server/internal/db"
 	"github.com/shuvava/ota-tuf-server/pkg/services"
+	"github.com/shuvava/ota-tuf-server/pkg/storage"
 )
 
 // Server is main application servers
@@ -26,9 +28,21 @@ type Server struct {
 	config *config.AppConfig
 	mu     sync.Mutex
 	svc    struct {
-		Db      intCmnDb.BaseRepository
-		KeyRepo db.KeyRepository
-		KeySvc  *services.RepositoryService
+		Db             intCmnDb.BaseRepository
+		KeyRepo        db.KeyRepository
+		RepoRepo       db.RepoRepository
+		SignedRoleRepo db.SignedRoleRepository
+		KeyGenRepo     db.KeyGenRequestRepository
+		ResignRepo     db.ResignRecordRepository
+		LeaseRepo      db.LeaseRepository
+		KeySvc         *services.RepositoryService
+		RootSvc        *services.RootRoleService
+		KeyGenSvc      *services.KeyGenService
+		TargetsSvc     *services.TargetsService
+		ResignSvc      *services.ResignService
+		TargetFileSvc  *services.TargetFileService
+		Storage        storage.Storage
+		KeyBackends    []io.Closer
 	}
 }
 
@@ -84,4 +98,8 @@ func (s *Server) Start() {
 		s.log.WithError(err).
 			Fatal("Error shutting down API server")
 	}
+	s.svc.KeyGenSvc.Stop()
+	s.svc.ResignSvc.Stop()
+	s.closeKeyBackends()
+	s.closeStorage()
 }
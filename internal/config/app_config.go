@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/fsnotify/fsnotify"
 	"github.com/shuvava/go-logging/logger"
@@ -23,11 +24,128 @@ type DbConfig struct {
 	ConnectionString string `mapstructure:"connectionString"`
 }
 
+// EncryptionConfig private keys encryption at rest configuration
+type EncryptionConfig struct {
+	// ActiveKeyID is the id of the master key wrapping new data keys, encryption is disabled if empty
+	ActiveKeyID string `mapstructure:"activeKeyId"`
+	// Keys is the list of base64 encoded 256-bit master keys by id
+	Keys map[string]string `mapstructure:"keys"`
+	// KeysFile is the path of JSON file with master keys by id, the keys are merged with Keys
+	KeysFile string `mapstructure:"keysFile"`
+}
+
+// SecurityConfig service security configuration
+type SecurityConfig struct {
+	// KeyExportToken is the bearer token required to export private keys, export is disabled if empty
+	KeyExportToken string           `mapstructure:"keyExportToken"`
+	Encryption     EncryptionConfig `mapstructure:"encryption"`
+}
+
+// PKCS11Config PKCS#11 key backend configuration
+type PKCS11Config struct {
+	// Module is the path of PKCS#11 module shared library, the backend is disabled if empty
+	Module string `mapstructure:"module"`
+	// TokenLabel is the label of the token keeping keys
+	TokenLabel string `mapstructure:"tokenLabel"`
+	// Pin is the user PIN of the token
+	Pin string `mapstructure:"pin"`
+}
+
+// RemoteSignerConfig remote signer key backend configuration
+type RemoteSignerConfig struct {
+	// Socket is the path of Unix socket of the signer daemon, the backend is disabled if empty
+	Socket string `mapstructure:"socket"`
+}
+
+// VaultConfig Vault transit key backend configuration
+type VaultConfig struct {
+	// Address is the Vault server address, the backend is disabled if empty
+	Address string `mapstructure:"address"`
+	// Token is the Vault token allowed to use transit keys
+	Token string `mapstructure:"token"`
+	// Namespace is the Vault Enterprise namespace
+	Namespace string `mapstructure:"namespace"`
+	// Mount is the mount path of transit engine
+	Mount string `mapstructure:"mount"`
+	// RSAKeySize is the size of generated RSA keys, 2048 or 4096
+	RSAKeySize int `mapstructure:"rsaKeySize"`
+}
+
+// SigningConfig key backends configuration
+type SigningConfig struct {
+	// DefaultBackend is the key backend of repository roles created without backend, keys are stored in Db if empty
+	DefaultBackend string             `mapstructure:"defaultBackend"`
+	PKCS11         PKCS11Config       `mapstructure:"pkcs11"`
+	Remote         RemoteSignerConfig `mapstructure:"remote"`
+	Vault          VaultConfig        `mapstructure:"vault"`
+}
+
+// KeyGenConfig background key generation configuration
+type KeyGenConfig struct {
+	// Workers is the number of concurrent key generation workers
+	Workers int `mapstructure:"workers"`
+}
+
+// ExpiresConfig expiration periods of published role metadata, default period of the role is used if zero
+type ExpiresConfig struct {
+	Root      time.Duration `mapstructure:"root"`
+	Targets   time.Duration `mapstructure:"targets"`
+	Snapshot  time.Duration `mapstructure:"snapshot"`
+	Timestamp time.Duration `mapstructure:"timestamp"`
+}
+
+// ResignConfig background re-signing of snapshot and timestamp role metadata configuration
+type ResignConfig struct {
+	// Interval is the interval of scanning repositories for expiring metadata
+	Interval time.Duration `mapstructure:"interval"`
+	// Threshold is the fraction of metadata lifetime which may elapse before it is re-signed
+	Threshold float64 `mapstructure:"threshold"`
+	// LeaseTTL is the time a repository is locked for by the server instance re-signing its metadata
+	LeaseTTL time.Duration `mapstructure:"leaseTtl"`
+}
+
+// MetadataConfig published role metadata configuration
+type MetadataConfig struct {
+	Expires ExpiresConfig `mapstructure:"expires"`
+	Resign  ResignConfig  `mapstructure:"resign"`
+}
+
+// LocalStorageConfig local filesystem blob storage configuration
+type LocalStorageConfig struct {
+	// Root is the directory keeping content of target files
+	Root string `mapstructure:"root"`
+}
+
+// S3StorageConfig S3 compatible blob storage configuration
+type S3StorageConfig struct {
+	// Endpoint is the S3 server address, e.g. https://s3.eu-west-1.amazonaws.com or http://minio:9000
+	Endpoint  string `mapstructure:"endpoint"`
+	Region    string `mapstructure:"region"`
+	Bucket    string `mapstructure:"bucket"`
+	AccessKey string `mapstructure:"accessKey"`
+	SecretKey string `mapstructure:"secretKey"`
+	// PathStyle addresses the bucket in the request path, it is required by most S3 compatible servers
+	PathStyle bool `mapstructure:"pathStyle"`
+}
+
+// StorageConfig target files content storage configuration
+type StorageConfig struct {
+	// Type is the type of blob storage: local or s3
+	Type  string             `mapstructure:"type"`
+	Local LocalStorageConfig `mapstructure:"local"`
+	S3    S3StorageConfig    `mapstructure:"s3"`
+}
+
 // AppConfig root app config
 type AppConfig struct {
-	Port     int      `mapstructure:"port"`
-	LogLevel string   `mapstructure:"logLevel"`
-	Db       DbConfig `mapstructure:"db"`
+	Port     int            `mapstructure:"port"`
+	LogLevel string         `mapstructure:"logLevel"`
+	Db       DbConfig       `mapstructure:"db"`
+	Security SecurityConfig `mapstructure:"security"`
+	KeyGen   KeyGenConfig   `mapstructure:"keyGen"`
+	Signing  SigningConfig  `mapstructure:"signing"`
+	Metadata MetadataConfig `mapstructure:"metadata"`
+	Storage  StorageConfig  `mapstructure:"storage"`
 }
 
 // OnConfigChange callback for config changes
@@ -98,6 +216,16 @@ func (cfg *AppConfig) PrintConfig(log logger.Logger) {
 	log.Info("    Port         :", cfg.Port)
 	log.Info("    LogLevel     :", cfg.LogLevel)
 	log.Info("    Db.Type      :", cfg.Db.Type)
+	log.Info("    KeyExport    :", cfg.Security.KeyExportToken != "")
+	log.Info("    MasterKey    :", cfg.Security.Encryption.ActiveKeyID)
+	log.Info("    KeyGen.Workers:", cfg.KeyGen.Workers)
+	log.Info("    PKCS11.Token :", cfg.Signing.PKCS11.TokenLabel)
+	log.Info("    Remote.Socket:", cfg.Signing.Remote.Socket)
+	log.Info("    Vault.Address:", cfg.Signing.Vault.Address)
+	log.Info("    KeyBackend   :", cfg.Signing.DefaultBackend)
+	log.Info("    Expires      :", cfg.Metadata.Expires)
+	log.Info("    Resign       :", cfg.Metadata.Resign)
+	log.Info("    Storage.Type :", cfg.Storage.Type)
 }
 
 // isPathExist checks if path exist
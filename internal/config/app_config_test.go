@@ -0,0 +1,51 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shuvava/go-logging/logger"
+
+	"github.com/shuvava/ota-tuf-server/internal/config"
+)
+
+func TestNewConfig(t *testing.T) {
+	t.Run("should load shipped appsettings.yml", func(t *testing.T) {
+		wd, err := os.Getwd()
+		if err != nil {
+			t.Fatalf("unable to get working directory: %v", err)
+		}
+		// config is looked up in the working directory, the server is started from the repository root
+		if err = os.Chdir(filepath.Join("..", "..")); err != nil {
+			t.Fatalf("unable to change working directory: %v", err)
+		}
+		t.Cleanup(func() { _ = os.Chdir(wd) })
+
+		// decoding errors are fatal, the test binary exits on them
+		cfg := config.NewConfig(logger.NewNopLogger(), nil)
+		expires := map[string][2]time.Duration{
+			"Root":      {cfg.Metadata.Expires.Root, 8760 * time.Hour},
+			"Targets":   {cfg.Metadata.Expires.Targets, 2160 * time.Hour},
+			"Snapshot":  {cfg.Metadata.Expires.Snapshot, 168 * time.Hour},
+			"Timestamp": {cfg.Metadata.Expires.Timestamp, 24 * time.Hour},
+			"Resign":    {cfg.Metadata.Resign.Interval, 10 * time.Minute},
+			"LeaseTTL":  {cfg.Metadata.Resign.LeaseTTL, 5 * time.Minute},
+		}
+		for name, v := range expires {
+			if v[0] != v[1] {
+				t.Errorf("expected %s %s, got %s", name, v[1], v[0])
+			}
+		}
+		if cfg.Metadata.Resign.Threshold != 0.5 {
+			t.Errorf("expected resign threshold 0.5, got %v", cfg.Metadata.Resign.Threshold)
+		}
+		if cfg.Storage.Type != "local" || cfg.Storage.Local.Root == "" {
+			t.Errorf("expected local storage, got %+v", cfg.Storage)
+		}
+		if cfg.Storage.S3.Region != "us-east-1" {
+			t.Errorf("expected S3 region us-east-1, got %s", cfg.Storage.S3.Region)
+		}
+	})
+}
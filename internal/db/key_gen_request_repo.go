@@ -0,0 +1,20 @@
+package db
+
+import (
+	"context"
+
+	"github.com/shuvava/ota-tuf-server/pkg/data"
+)
+
+// KeyGenRequestRepository is the interface for the data.KeyGenRequest repository.
+// Repository keeps one request per data.Repo.
+type KeyGenRequestRepository interface {
+	// Create persist new data.KeyGenRequest in database
+	Create(ctx context.Context, obj data.KeyGenRequest) error
+	// FindByRepoID returns data.KeyGenRequest of the repository
+	FindByRepoID(ctx context.Context, repoID data.RepoID) (*data.KeyGenRequest, error)
+	// FindByStatus returns all data.KeyGenRequest in the status
+	FindByStatus(ctx context.Context, status data.KeyGenStatus) ([]data.KeyGenRequest, error)
+	// Update replaces status, attempts and error of existing data.KeyGenRequest
+	Update(ctx context.Context, obj data.KeyGenRequest) error
+}
@@ -10,10 +10,21 @@ import (
 type KeyRepository interface {
 	// Create persist new data.Object in database
 	Create(ctx context.Context, obj data.RepoKey) error
+	// FindAll returns data.RepoKey of all repositories
+	FindAll(ctx context.Context) ([]data.RepoKey, error)
 	// FindByRepoId returns data.RepoKey by repoId
 	FindByRepoId(ctx context.Context, repoID data.RepoID) ([]data.RepoKey, error)
+	// FindByRole returns all data.RepoKey of the repository role
+	FindByRole(ctx context.Context, repoID data.RepoID, role data.RoleType) ([]data.RepoKey, error)
 	// FindByKeyID returns data.RepoKey by keyID
 	FindByKeyID(ctx context.Context, repoID data.RepoID, keyID data.KeyID) (*data.RepoKey, error)
+	// Update replaces role, key data and export status of existing data.RepoKey
+	Update(ctx context.Context, obj data.RepoKey) error
+	// MarkExported sets export status of data.RepoKey which was not exported yet and returns the updated key,
+	// apperrors.ErrorDbNoDocumentFound is returned if the key does not exist or was already exported
+	MarkExported(ctx context.Context, repoID data.RepoID, keyID data.KeyID) (*data.RepoKey, error)
+	// Delete removes data.RepoKey from database
+	Delete(ctx context.Context, repoID data.RepoID, keyID data.KeyID) error
 	// Exists checks if data.RepoKey exists in database
 	Exists(ctx context.Context, repoID data.RepoID, keyID data.KeyID) (bool, error)
 }
@@ -0,0 +1,16 @@
+package db
+
+import (
+	"context"
+	"time"
+)
+
+// LeaseRepository is the interface for named leases shared by server instances.
+// A lease is held by a single owner until it is released or expired.
+type LeaseRepository interface {
+	// Acquire takes the lease for ttl or extends it if the owner already holds it,
+	// false is returned if the lease is held by another owner
+	Acquire(ctx context.Context, name, owner string, ttl time.Duration) (bool, error)
+	// Release releases the lease if the owner holds it
+	Release(ctx context.Context, name, owner string) error
+}
@@ -5,4 +5,10 @@ import "github.com/shuvava/go-ota-svc-common/apperrors"
 const (
 	// ErrorRepoKeyErrorDbAlreadyExist s is the error message for the error when RepoKey is already exist
 	ErrorRepoKeyErrorDbAlreadyExist = apperrors.ErrorDbAlreadyExist + ":RepoKey"
+	// ErrorRepoErrorDbAlreadyExist is the error message for the error when Repo is already exist
+	ErrorRepoErrorDbAlreadyExist = apperrors.ErrorDbAlreadyExist + ":Repo"
+	// ErrorSignedRoleErrorDbAlreadyExist is the error message for the error when SignedRole version is already exist
+	ErrorSignedRoleErrorDbAlreadyExist = apperrors.ErrorDbAlreadyExist + ":SignedRole"
+	// ErrorKeyGenRequestErrorDbAlreadyExist is the error message for the error when KeyGenRequest is already exist
+	ErrorKeyGenRequestErrorDbAlreadyExist = apperrors.ErrorDbAlreadyExist + ":KeyGenRequest"
 )
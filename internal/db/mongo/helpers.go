@@ -0,0 +1,33 @@
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/shuvava/go-logging/logger"
+	"github.com/shuvava/go-ota-svc-common/apperrors"
+	intMongo "github.com/shuvava/go-ota-svc-common/db/mongo"
+)
+
+// insertOne inserts a single document into the collection,
+// violation of unique index is reported as error with dupCode
+func insertOne(ctx context.Context, log logger.Logger, db *intMongo.Db, coll *mongo.Collection, document interface{}, dupCode apperrors.AppErrorCode) error {
+	defer log.TrackFuncTime(time.Now())
+	ctxIns, cancel := context.WithTimeout(ctx, db.Timeout)
+	defer cancel()
+
+	_, err := coll.InsertOne(ctxIns, document)
+	if mongo.IsDuplicateKeyError(err) {
+		return apperrors.CreateErrorAndLogIt(log,
+			dupCode,
+			"Failed to add new DB record", err)
+	}
+	if err != nil {
+		return apperrors.CreateErrorAndLogIt(log,
+			apperrors.ErrorDbOperation,
+			"Failed to add new DB record", err)
+	}
+	return nil
+}
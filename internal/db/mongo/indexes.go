@@ -0,0 +1,93 @@
+package mongo
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/shuvava/go-logging/logger"
+	"github.com/shuvava/go-ota-svc-common/apperrors"
+	intMongo "github.com/shuvava/go-ota-svc-common/db/mongo"
+)
+
+// collectionIndexes is the list of indexes of service collections,
+// unique indexes guarantee uniqueness of documents on insert
+var collectionIndexes = map[string][]mongo.IndexModel{
+	objectTableName: {
+		{
+			Keys:    bson.D{primitive.E{Key: "repo_id", Value: 1}, primitive.E{Key: "key_id", Value: 1}},
+			Options: options.Index().SetName("repo_id_key_id").SetUnique(true),
+		},
+		{
+			Keys:    bson.D{primitive.E{Key: "repo_id", Value: 1}, primitive.E{Key: "role", Value: 1}},
+			Options: options.Index().SetName("repo_id_role"),
+		},
+	},
+	repoTableName: {
+		{
+			Keys:    bson.D{primitive.E{Key: "repo_id", Value: 1}},
+			Options: options.Index().SetName("repo_id").SetUnique(true),
+		},
+	},
+	signedRoleTableName: {
+		{
+			Keys: bson.D{
+				primitive.E{Key: "repo_id", Value: 1},
+				primitive.E{Key: "role", Value: 1},
+				primitive.E{Key: "version", Value: 1},
+			},
+			Options: options.Index().SetName("repo_id_role_version").SetUnique(true),
+		},
+		{
+			// expiring metadata is looked up among latest versions of roles only
+			Keys: bson.D{
+				primitive.E{Key: "role", Value: 1},
+				primitive.E{Key: "expires_at", Value: 1},
+			},
+			Options: options.Index().SetName("role_expires_at_latest").
+				SetPartialFilterExpression(bson.D{primitive.E{Key: "latest", Value: true}}),
+		},
+	},
+	keyGenRequestTableName: {
+		{
+			Keys:    bson.D{primitive.E{Key: "repo_id", Value: 1}},
+			Options: options.Index().SetName("repo_id").SetUnique(true),
+		},
+		{
+			Keys:    bson.D{primitive.E{Key: "status", Value: 1}},
+			Options: options.Index().SetName("status"),
+		},
+	},
+	resignRecordTableName: {
+		{
+			Keys:    bson.D{primitive.E{Key: "repo_id", Value: 1}, primitive.E{Key: "created_at", Value: -1}},
+			Options: options.Index().SetName("repo_id_created_at"),
+		},
+		{
+			Keys:    bson.D{primitive.E{Key: "created_at", Value: 1}},
+			Options: options.Index().SetName("created_at_ttl").SetExpireAfterSeconds(resignRecordTTL),
+		},
+	},
+}
+
+// EnsureIndexes creates indexes of service collections if they do not exist
+func EnsureIndexes(ctx context.Context, logger logger.Logger, db *intMongo.Db) error {
+	log := logger.SetOperation("EnsureIndexes").
+		WithContext(ctx)
+	for name, indexes := range collectionIndexes {
+		ctxIdx, cancel := context.WithTimeout(ctx, db.Timeout)
+		_, err := db.GetCollection(name).Indexes().CreateMany(ctxIdx, indexes)
+		cancel()
+		if err != nil {
+			return apperrors.CreateErrorAndLogIt(log.WithField("Collection", name),
+				apperrors.ErrorDbOperation,
+				"Failed to create indexes", err)
+		}
+		log.WithField("Collection", name).
+			Debug("Indexes are up to date")
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/shuvava/go-logging/logger"
+	"github.com/shuvava/go-ota-svc-common/apperrors"
+	intMongo "github.com/shuvava/go-ota-svc-common/db/mongo"
+)
+
+const (
+	migrationTableName = "tuf_migrations"
+	// errCodeNamespaceNotFound is the error code of command on collection which does not exist
+	errCodeNamespaceNotFound = 26
+	// errCodeIndexNotFound is the error code of command on index which does not exist
+	errCodeIndexNotFound = 27
+)
+
+// migration is a named one-time change of stored documents
+type migration struct {
+	name  string
+	apply func(ctx context.Context, log logger.Logger, db *intMongo.Db) error
+}
+
+type migrationDTO struct {
+	Name      string    `bson:"_id"`
+	AppliedAt time.Time `bson:"applied_at"`
+}
+
+// migrations is the ordered list of migrations, applied migrations are never changed
+var migrations = []migration{
+	{name: "001_tuf_keys_bson_schema", apply: migrateKeysBsonSchema},
+	{name: "002_tuf_signed_roles_latest", apply: migrateSignedRolesLatest},
+}
+
+// Migrate applies migrations which were not applied yet, it has to be called before EnsureIndexes
+func Migrate(ctx context.Context, logger logger.Logger, db *intMongo.Db) error {
+	log := logger.SetOperation("Migrate").
+		WithContext(ctx)
+	coll := db.GetCollection(migrationTableName)
+	for _, m := range migrations {
+		mLog := log.WithField("Migration", m.name)
+		cnt, err := db.Count(ctx, coll, bson.D{primitive.E{Key: "_id", Value: m.name}})
+		if err != nil {
+			return err
+		}
+		if cnt > 0 {
+			continue
+		}
+		mLog.Info("Applying migration")
+		if err = m.apply(ctx, mLog, db); err != nil {
+			return err
+		}
+		rec := migrationDTO{Name: m.name, AppliedAt: time.Now().UTC()}
+		if _, err = db.InsertOne(ctx, coll, rec); err != nil {
+			return err
+		}
+		mLog.Info("Migration applied")
+	}
+	return nil
+}
+
+// migrateKeysBsonSchema renames fields of tuf_keys documents stored with default bson field names
+// and replaces legacy uuid key ids by TUF key ids computed from public keys
+func migrateKeysBsonSchema(ctx context.Context, log logger.Logger, db *intMongo.Db) error {
+	coll := db.GetCollection(objectTableName)
+	ctxUpd, cancel := context.WithTimeout(ctx, db.Timeout)
+	defer cancel()
+	filter := bson.D{primitive.E{Key: "repoid", Value: bson.D{primitive.E{Key: "$exists", Value: true}}}}
+	rename := bson.D{primitive.E{Key: "$rename", Value: bson.D{
+		primitive.E{Key: "repoid", Value: "repo_id"},
+		primitive.E{Key: "keyid", Value: "key_id"},
+		primitive.E{Key: "key.type", Value: "key.keytype"},
+		primitive.E{Key: "key.value", Value: "key.keyval"},
+	}}}
+	res, err := coll.UpdateMany(ctxUpd, filter, rename)
+	if err != nil {
+		return apperrors.CreateErrorAndLogIt(log,
+			apperrors.ErrorDbOperation,
+			"Failed to rename fields of RepoKey documents", err)
+	}
+	log.WithField("Count", res.ModifiedCount).
+		Info("RepoKey documents fields renamed")
+
+	var docs []repoKeyDTO
+	filter = bson.D{primitive.E{Key: "key_id", Value: bson.D{
+		primitive.E{Key: "$not", Value: primitive.Regex{Pattern: "^[0-9a-f]{64}$"}},
+	}}}
+	if err = db.Find(ctx, coll, filter, &docs); err != nil {
+		return err
+	}
+	for _, doc := range docs {
+		// toModel computes TUF key id of legacy documents
+		obj, err := toModel(doc)
+		if err != nil {
+			return err
+		}
+		internalID := doc.InternalID
+		if internalID == "" {
+			internalID = doc.KeyID
+		}
+		update := bson.D{primitive.E{Key: "$set", Value: bson.D{
+			primitive.E{Key: "key_id", Value: obj.KeyID.String()},
+			primitive.E{Key: "internal_id", Value: internalID},
+		}}}
+		if err = db.UpdateOne(ctx, coll, bson.D{primitive.E{Key: "_id", Value: doc.ID}}, update); err != nil {
+			return err
+		}
+	}
+	log.WithField("Count", len(docs)).
+		Info("Legacy key ids of RepoKey documents replaced")
+	return nil
+}
+
+// migrateSignedRolesLatest marks the highest versions of roles latest
+// and drops index of looking up the highest versions by aggregation
+func migrateSignedRolesLatest(ctx context.Context, log logger.Logger, db *intMongo.Db) error {
+	coll := db.GetCollection(signedRoleTableName)
+	ctxUpd, cancel := context.WithTimeout(ctx, db.Timeout)
+	defer cancel()
+	pipeline := mongo.Pipeline{
+		{primitive.E{Key: "$sort", Value: bson.D{
+			primitive.E{Key: "repo_id", Value: 1},
+			primitive.E{Key: "role", Value: 1},
+			primitive.E{Key: "version", Value: -1},
+		}}},
+		{primitive.E{Key: "$group", Value: bson.D{
+			primitive.E{Key: "_id", Value: bson.D{
+				primitive.E{Key: "repo_id", Value: "$repo_id"},
+				primitive.E{Key: "role", Value: "$role"},
+			}},
+			primitive.E{Key: "latest", Value: bson.D{primitive.E{Key: "$first", Value: "$_id"}}},
+		}}},
+	}
+	cur, err := coll.Aggregate(ctxUpd, pipeline, options.Aggregate().SetAllowDiskUse(true))
+	if err != nil {
+		return apperrors.CreateErrorAndLogIt(log,
+			apperrors.ErrorDbOperation,
+			"Failed to look up latest SignedRole documents", err)
+	}
+	var groups []struct {
+		Latest primitive.ObjectID `bson:"latest"`
+	}
+	if err = cur.All(ctxUpd, &groups); err != nil {
+		return apperrors.CreateErrorAndLogIt(log,
+			apperrors.ErrorDbOperation,
+			"Failed to fetch latest SignedRole documents", err)
+	}
+	ids := make(bson.A, len(groups))
+	for i, group := range groups {
+		ids[i] = group.Latest
+	}
+	filter := bson.D{primitive.E{Key: "_id", Value: bson.D{primitive.E{Key: "$in", Value: ids}}}}
+	update := bson.D{primitive.E{Key: "$set", Value: bson.D{primitive.E{Key: "latest", Value: true}}}}
+	res, err := coll.UpdateMany(ctxUpd, filter, update)
+	if err != nil {
+		return apperrors.CreateErrorAndLogIt(log,
+			apperrors.ErrorDbOperation,
+			"Failed to mark SignedRole documents latest", err)
+	}
+	log.WithField("Count", res.ModifiedCount).
+		Info("Latest SignedRole documents marked")
+
+	_, err = coll.Indexes().DropOne(ctxUpd, "role_repo_id_version")
+	var cmdErr mongo.CommandError
+	if err != nil && !(errors.As(err, &cmdErr) && (cmdErr.Code == errCodeNamespaceNotFound || cmdErr.Code == errCodeIndexNotFound)) {
+		return apperrors.CreateErrorAndLogIt(log,
+			apperrors.ErrorDbOperation,
+			"Failed to drop index of SignedRole documents", err)
+	}
+	return nil
+}
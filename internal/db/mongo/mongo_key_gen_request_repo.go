@@ -0,0 +1,162 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/shuvava/go-logging/logger"
+	"github.com/shuvava/go-ota-svc-common/apperrors"
+	intMongo "github.com/shuvava/go-ota-svc-common/db/mongo"
+
+	"github.com/shuvava/ota-tuf-server/internal/db"
+	"github.com/shuvava/ota-tuf-server/pkg/data"
+)
+
+const keyGenRequestTableName = "tuf_key_gen_requests"
+
+type keyGenRequestDTO struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	RepoID    string             `bson:"repo_id"`
+	Repo      repoDTO            `bson:"repo"`
+	Status    string             `bson:"status"`
+	Attempts  int                `bson:"attempts"`
+	Error     string             `bson:"error,omitempty"`
+	CreatedAt time.Time          `bson:"created_at"`
+	UpdatedAt time.Time          `bson:"updated_at"`
+}
+
+// KeyGenRequestMongoRepository implementations of db.KeyGenRequestRepository for MongoDb repo
+type KeyGenRequestMongoRepository struct {
+	db   *intMongo.Db
+	coll *mongo.Collection
+	log  logger.Logger
+	db.KeyGenRequestRepository
+}
+
+// NewKeyGenRequestMongoRepository creates new instance of KeyGenRequestMongoRepository
+func NewKeyGenRequestMongoRepository(logger logger.Logger, db *intMongo.Db) *KeyGenRequestMongoRepository {
+	log := logger.SetOperation("KeyGenRequestRepo")
+	return &KeyGenRequestMongoRepository{
+		db:   db,
+		coll: db.GetCollection(keyGenRequestTableName),
+		log:  log,
+	}
+}
+
+// Create persist new data.KeyGenRequest in database
+func (store *KeyGenRequestMongoRepository) Create(ctx context.Context, obj data.KeyGenRequest) error {
+	log := store.log.WithContext(ctx).
+		WithField("RepoID", obj.Repo.RepoID)
+	defer log.TrackFuncTime(time.Now())
+	log.Debug("Creating new KeyGenRequest")
+
+	if err := insertOne(ctx, log, store.db, store.coll, keyGenRequestToDTO(obj), ErrorKeyGenRequestErrorDbAlreadyExist); err != nil {
+		log.Warn("KeyGenRequest creation failed")
+		return err
+	}
+	log.Info("KeyGenRequest created successful")
+	return nil
+}
+
+// FindByRepoID returns data.KeyGenRequest of the repository
+func (store *KeyGenRequestMongoRepository) FindByRepoID(ctx context.Context, repoID data.RepoID) (*data.KeyGenRequest, error) {
+	log := store.log.WithContext(ctx).
+		WithField("RepoID", repoID)
+	defer log.TrackFuncTime(time.Now())
+	log.Debug("Looking up KeyGenRequest")
+
+	var dto keyGenRequestDTO
+	err := store.db.GetOne(ctx, store.coll, getKeyGenRequestFilter(repoID), &dto)
+	if err != nil {
+		var typedErr apperrors.AppError
+		if errors.As(err, &typedErr) && typedErr.ErrorCode == apperrors.ErrorDbNoDocumentFound {
+			log.Warn("KeyGenRequest not found")
+		}
+		return nil, err
+	}
+	return keyGenRequestToModel(dto)
+}
+
+// FindByStatus returns all data.KeyGenRequest in the status
+func (store *KeyGenRequestMongoRepository) FindByStatus(ctx context.Context, status data.KeyGenStatus) ([]data.KeyGenRequest, error) {
+	log := store.log.WithContext(ctx).
+		WithField("Status", status)
+	defer log.TrackFuncTime(time.Now())
+	log.Debug("Looking up KeyGenRequests")
+
+	var docs []keyGenRequestDTO
+	filter := bson.D{primitive.E{Key: "status", Value: string(status)}}
+	if err := store.db.Find(ctx, store.coll, filter, &docs); err != nil {
+		return nil, err
+	}
+	res := make([]data.KeyGenRequest, 0, len(docs))
+	for _, doc := range docs {
+		obj, err := keyGenRequestToModel(doc)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, *obj)
+	}
+	log.WithField("Count", len(res)).
+		Debug("Lookup completed successful")
+	return res, nil
+}
+
+// Update replaces status, attempts and error of existing data.KeyGenRequest
+func (store *KeyGenRequestMongoRepository) Update(ctx context.Context, obj data.KeyGenRequest) error {
+	log := store.log.WithContext(ctx).
+		WithField("RepoID", obj.Repo.RepoID).
+		WithField("Status", obj.Status)
+	defer log.TrackFuncTime(time.Now())
+	log.Debug("Updating KeyGenRequest")
+
+	update := bson.D{primitive.E{Key: "$set", Value: bson.D{
+		primitive.E{Key: "status", Value: string(obj.Status)},
+		primitive.E{Key: "attempts", Value: obj.Attempts},
+		primitive.E{Key: "error", Value: obj.Error},
+		primitive.E{Key: "updated_at", Value: obj.UpdatedAt},
+	}}}
+	if err := store.db.UpdateOne(ctx, store.coll, getKeyGenRequestFilter(obj.Repo.RepoID), update); err != nil {
+		log.Warn("KeyGenRequest update failed")
+		return err
+	}
+	log.Debug("KeyGenRequest updated successful")
+	return nil
+}
+
+func keyGenRequestToDTO(obj data.KeyGenRequest) keyGenRequestDTO {
+	return keyGenRequestDTO{
+		ID:        primitive.NewObjectID(),
+		RepoID:    obj.Repo.RepoID.String(),
+		Repo:      repoToDTO(obj.Repo),
+		Status:    string(obj.Status),
+		Attempts:  obj.Attempts,
+		Error:     obj.Error,
+		CreatedAt: obj.CreatedAt,
+		UpdatedAt: obj.UpdatedAt,
+	}
+}
+
+func keyGenRequestToModel(dto keyGenRequestDTO) (*data.KeyGenRequest, error) {
+	repo, err := repoToModel(dto.Repo)
+	if err != nil {
+		return nil, err
+	}
+	return &data.KeyGenRequest{
+		Repo:      *repo,
+		Status:    data.KeyGenStatus(dto.Status),
+		Attempts:  dto.Attempts,
+		Error:     dto.Error,
+		CreatedAt: dto.CreatedAt.UTC(),
+		UpdatedAt: dto.UpdatedAt.UTC(),
+	}, nil
+}
+
+func getKeyGenRequestFilter(repoID data.RepoID) bson.D {
+	return bson.D{primitive.E{Key: "repo_id", Value: repoID.String()}}
+}
@@ -4,36 +4,50 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
-	"fmt"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 
 	"github.com/shuvava/go-logging/logger"
 	"github.com/shuvava/go-ota-svc-common/apperrors"
+	cmnData "github.com/shuvava/go-ota-svc-common/data"
 	intMongo "github.com/shuvava/go-ota-svc-common/db/mongo"
 
 	"github.com/shuvava/ota-tuf-server/internal/db"
 	"github.com/shuvava/ota-tuf-server/pkg/data"
+	"github.com/shuvava/ota-tuf-server/pkg/encryption"
 )
 
 const objectTableName = "tuf_keys"
 
 type keyDTO struct {
 	// Type is key type
-	Type string `json:"keytype"`
+	Type string `bson:"keytype"`
 	// Value is key value
-	Value json.RawMessage `json:"keyval"`
+	Value json.RawMessage `bson:"keyval"`
+}
+
+type keyHandleDTO struct {
+	Backend string `bson:"backend"`
+	Ref     string `bson:"ref"`
 }
 
 type repoKeyDTO struct {
-	ID     primitive.ObjectID `bson:"_id,omitempty"`
-	RepoID string             `json:"repo_id"`
-	Role   string             `json:"role"`
-	KeyID  string             `json:"key_id"`
-	Key    keyDTO             `json:"key"`
+	ID primitive.ObjectID `bson:"_id,omitempty"`
+	// InternalID is the storage id of the key, it is unique within the repository namespace
+	InternalID string `bson:"internal_id"`
+	RepoID     string `bson:"repo_id"`
+	Role       string `bson:"role"`
+	// KeyID is TUF key id
+	KeyID string `bson:"key_id"`
+	Key   keyDTO `bson:"key"`
+	// Handle is the reference to the key held by a key backend
+	Handle *keyHandleDTO `bson:"handle,omitempty"`
+	// Exported indicates that private part of the key was exported
+	Exported bool `bson:"exported"`
 }
 
 // RepoKeyMongoRepository implementations of db.KeyRepository for MongoDb repo
@@ -63,18 +77,7 @@ func (store *RepoKeyMongoRepository) Create(ctx context.Context, obj data.RepoKe
 		WithField("Role", obj.Role).
 		Debug("Creating new Key")
 
-	dto := toDTO(obj)
-	exists, err := store.Exists(ctx, obj.RepoID, obj.KeyID)
-	if err != nil {
-		return err
-	}
-	if exists {
-		err = fmt.Errorf("document(Key) with id='%s' role='%s' already exist in database", obj.KeyID, obj.Role)
-		return apperrors.CreateErrorAndLogIt(log,
-			ErrorRepoKeyErrorDbAlreadyExist,
-			"Failed to add new DB record", err)
-	}
-	_, err = store.db.InsertOne(ctx, store.coll, dto)
+	err := insertOne(ctx, log, store.db, store.coll, toDTO(obj), ErrorRepoKeyErrorDbAlreadyExist)
 	if err == nil {
 		log.WithField("RepoID", obj.RepoID).
 			WithField("KeyID", obj.KeyID).
@@ -115,6 +118,21 @@ func (store *RepoKeyMongoRepository) FindByKeyID(ctx context.Context, repoID dat
 	return &model, err
 }
 
+// FindAll returns data.RepoKey of all repositories
+func (store *RepoKeyMongoRepository) FindAll(ctx context.Context) ([]data.RepoKey, error) {
+	log := store.log.WithContext(ctx)
+	defer log.TrackFuncTime(time.Now())
+	log.Debug("Looking up all RepoKeys")
+
+	res, err := store.find(ctx, bson.D{})
+	if err != nil {
+		return nil, err
+	}
+	log.WithField("Count", len(res)).
+		Debug("Lookup completed successful")
+	return res, nil
+}
+
 // FindByRepoId returns data.RepoKey by repoId
 func (store *RepoKeyMongoRepository) FindByRepoId(ctx context.Context, repoID data.RepoID) ([]data.RepoKey, error) {
 	log := store.log.WithContext(ctx)
@@ -128,30 +146,128 @@ func (store *RepoKeyMongoRepository) FindByRepoId(ctx context.Context, repoID da
 			bson.D{primitive.E{Key: "repo_id", Value: repoID.String()}},
 		},
 	}}
-	var docs []repoKeyDTO
-	err := store.db.Find(ctx, store.coll, filter, &docs)
+	res, err := store.find(ctx, filter)
 	if err != nil {
 		log.WithField("RepoID", repoID).
 			Debug("Not Found")
 		return nil, err
 	}
 
-	var res []data.RepoKey
-	for _, doc := range docs {
-		obj, err := toModel(doc)
-		if err != nil {
-			return nil, err
-		}
-		res = append(res, obj)
+	log.WithField("RepoID", repoID).
+		WithField("Count", len(res)).
+		Debug("Lookup completed successful")
+
+	return res, nil
+}
+
+// FindByRole returns all data.RepoKey of the repository role
+func (store *RepoKeyMongoRepository) FindByRole(ctx context.Context, repoID data.RepoID, role data.RoleType) ([]data.RepoKey, error) {
+	log := store.log.WithContext(ctx)
+	defer log.TrackFuncTime(time.Now())
+	log.WithField("RepoID", repoID).
+		WithField("Role", role).
+		Debug("Looking up RepoKeys")
+
+	filter := bson.D{primitive.E{
+		Key: "$and",
+		Value: bson.A{
+			bson.D{primitive.E{Key: "repo_id", Value: repoID.String()}},
+			bson.D{primitive.E{Key: "role", Value: string(role)}},
+		},
+	}}
+	res, err := store.find(ctx, filter)
+	if err != nil {
+		log.WithField("RepoID", repoID).
+			WithField("Role", role).
+			Debug("Not Found")
+		return nil, err
 	}
 
 	log.WithField("RepoID", repoID).
+		WithField("Role", role).
 		WithField("Count", len(res)).
 		Debug("Lookup completed successful")
 
 	return res, nil
 }
 
+// Update replaces role, key data and export status of existing data.RepoKey
+func (store *RepoKeyMongoRepository) Update(ctx context.Context, obj data.RepoKey) error {
+	log := store.log.WithContext(ctx).
+		WithField("RepoID", obj.RepoID).
+		WithField("KeyID", obj.KeyID)
+	defer log.TrackFuncTime(time.Now())
+	log.Debug("Updating RepoKey")
+
+	dto := toDTO(obj)
+	update := bson.D{primitive.E{Key: "$set", Value: bson.D{
+		primitive.E{Key: "role", Value: dto.Role},
+		primitive.E{Key: "key", Value: dto.Key},
+		primitive.E{Key: "handle", Value: dto.Handle},
+		primitive.E{Key: "exported", Value: dto.Exported},
+	}}}
+	if err := store.db.UpdateOne(ctx, store.coll, getOneRepoKeyFilter(obj.RepoID, obj.KeyID), update); err != nil {
+		log.Warn("Key update failed")
+		return err
+	}
+	log.Info("Key updated successful")
+	return nil
+}
+
+// MarkExported sets export status of data.RepoKey which was not exported yet and returns the updated key,
+// the status is checked and set by one update, so the key is marked exported once
+func (store *RepoKeyMongoRepository) MarkExported(ctx context.Context, repoID data.RepoID, keyID data.KeyID) (*data.RepoKey, error) {
+	log := store.log.WithContext(ctx).
+		WithField("RepoID", repoID).
+		WithField("KeyID", keyID)
+	defer log.TrackFuncTime(time.Now())
+	log.Debug("Marking RepoKey exported")
+
+	ctxUpd, cancel := context.WithTimeout(ctx, store.db.Timeout)
+	defer cancel()
+	filter := bson.D{
+		primitive.E{Key: "repo_id", Value: repoID.String()},
+		primitive.E{Key: "key_id", Value: keyID.String()},
+		// keys stored before export status was introduced have no exported field
+		primitive.E{Key: "exported", Value: bson.D{primitive.E{Key: "$ne", Value: true}}},
+	}
+	update := bson.D{primitive.E{Key: "$set", Value: bson.D{
+		primitive.E{Key: "exported", Value: true},
+	}}}
+	var dto repoKeyDTO
+	err := store.coll.FindOneAndUpdate(ctxUpd, filter, update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&dto)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		log.Warn("RepoKey not found or already exported")
+		return nil, apperrors.NewAppError(apperrors.ErrorDbNoDocumentFound, "key not found or already exported")
+	}
+	if err != nil {
+		return nil, apperrors.CreateErrorAndLogIt(log,
+			apperrors.ErrorDbOperation,
+			"Failed to update DB record", err)
+	}
+	log.Info("Key marked exported")
+	model, err := toModel(dto)
+	return &model, err
+}
+
+// Delete removes data.RepoKey from database
+func (store *RepoKeyMongoRepository) Delete(ctx context.Context, repoID data.RepoID, keyID data.KeyID) error {
+	log := store.log.WithContext(ctx)
+	defer log.TrackFuncTime(time.Now())
+	log.WithField("RepoID", repoID).
+		WithField("KeyID", keyID).
+		Debug("Deleting RepoKey")
+	err := store.db.Delete(ctx, store.coll, getOneRepoKeyFilter(repoID, keyID))
+	if err != nil {
+		return err
+	}
+	log.WithField("RepoID", repoID).
+		WithField("KeyID", keyID).
+		Info("Key deleted successful")
+	return nil
+}
+
 // Exists checks if data.RepoKey exists in database
 func (store *RepoKeyMongoRepository) Exists(ctx context.Context, repoID data.RepoID, keyID data.KeyID) (bool, error) {
 	log := store.log.WithContext(ctx)
@@ -167,17 +283,50 @@ func (store *RepoKeyMongoRepository) Exists(ctx context.Context, repoID data.Rep
 	return cnt > 0, nil
 }
 
-// toDTO converts data.RepoKey to DTO
+// find returns all data.RepoKey matching to the filter
+func (store *RepoKeyMongoRepository) find(ctx context.Context, filter bson.D) ([]data.RepoKey, error) {
+	var docs []repoKeyDTO
+	err := store.db.Find(ctx, store.coll, filter, &docs)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []data.RepoKey
+	for _, doc := range docs {
+		obj, err := toModel(doc)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, obj)
+	}
+	return res, nil
+}
+
+// toDTO converts data.RepoKey to DTO, storage id is generated for keys which are not stored yet
 func toDTO(obj data.RepoKey) repoKeyDTO {
+	internalID := obj.InternalID
+	if internalID == "" {
+		internalID = cmnData.NewChildCorrelationID(cmnData.CorrelationID(obj.RepoID), "").String()
+	}
+	var handle *keyHandleDTO
+	if obj.Handle != nil {
+		handle = &keyHandleDTO{
+			Backend: string(obj.Handle.Backend),
+			Ref:     obj.Handle.Ref,
+		}
+	}
 	return repoKeyDTO{
-		ID:     primitive.NewObjectID(),
-		RepoID: obj.RepoID.String(),
-		Role:   string(obj.Role),
-		KeyID:  obj.KeyID.String(),
+		ID:         primitive.NewObjectID(),
+		InternalID: internalID,
+		RepoID:     obj.RepoID.String(),
+		Role:       string(obj.Role),
+		KeyID:      obj.KeyID.String(),
 		Key: keyDTO{
 			Type:  string(obj.Key.Type),
 			Value: obj.Key.Value,
 		},
+		Handle:   handle,
+		Exported: obj.Exported,
 	}
 }
 
@@ -186,18 +335,33 @@ func toModel(dto repoKeyDTO) (data.RepoKey, error) {
 	if err != nil {
 		return data.RepoKey{}, err
 	}
+	key := data.Key{
+		Type:  data.KeyType(dto.Key.Type),
+		Value: dto.Key.Value,
+	}
 	keyID, err := data.KeyIDFromString(dto.KeyID)
+	if err != nil {
+		// keys created before TUF key ids were introduced store internal id in key_id
+		keyID, err = encryption.ComputeKeyID(&key)
+	}
 	if err != nil {
 		return data.RepoKey{}, err
 	}
+	var handle *data.KeyHandle
+	if dto.Handle != nil {
+		handle = &data.KeyHandle{
+			Backend: data.KeyBackend(dto.Handle.Backend),
+			Ref:     dto.Handle.Ref,
+		}
+	}
 	return data.RepoKey{
-		RepoID: repoID,
-		Role:   data.RoleType(dto.Role),
-		KeyID:  keyID,
-		Key: data.Key{
-			Type:  data.KeyType(dto.Key.Type),
-			Value: dto.Key.Value,
-		},
+		RepoID:     repoID,
+		Role:       data.RoleType(dto.Role),
+		KeyID:      keyID,
+		InternalID: dto.InternalID,
+		Key:        key,
+		Handle:     handle,
+		Exported:   dto.Exported,
 	}, nil
 }
 
@@ -205,8 +369,8 @@ func getOneRepoKeyFilter(repoID data.RepoID, keyID data.KeyID) bson.D {
 	return bson.D{primitive.E{
 		Key: "$and",
 		Value: bson.A{
-			bson.D{primitive.E{Key: "repo_id", Value: repoID}},
-			bson.D{primitive.E{Key: "key_id", Value: keyID}},
+			bson.D{primitive.E{Key: "repo_id", Value: repoID.String()}},
+			bson.D{primitive.E{Key: "key_id", Value: keyID.String()}},
 		},
 	}}
 }
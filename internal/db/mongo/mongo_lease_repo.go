@@ -0,0 +1,97 @@
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/shuvava/go-logging/logger"
+	"github.com/shuvava/go-ota-svc-common/apperrors"
+	intMongo "github.com/shuvava/go-ota-svc-common/db/mongo"
+
+	"github.com/shuvava/ota-tuf-server/internal/db"
+)
+
+const leaseTableName = "tuf_leases"
+
+// LeaseMongoRepository implementations of db.LeaseRepository for MongoDb repo.
+// Lease is a document with the lease name as id, so only one owner can hold it.
+type LeaseMongoRepository struct {
+	db   *intMongo.Db
+	coll *mongo.Collection
+	log  logger.Logger
+	db.LeaseRepository
+}
+
+// NewLeaseMongoRepository creates new instance of LeaseMongoRepository
+func NewLeaseMongoRepository(logger logger.Logger, db *intMongo.Db) *LeaseMongoRepository {
+	log := logger.SetOperation("LeaseRepo")
+	return &LeaseMongoRepository{
+		db:   db,
+		coll: db.GetCollection(leaseTableName),
+		log:  log,
+	}
+}
+
+// Acquire takes the lease for ttl or extends it if the owner already holds it,
+// false is returned if the lease is held by another owner
+func (store *LeaseMongoRepository) Acquire(ctx context.Context, name, owner string, ttl time.Duration) (bool, error) {
+	log := store.log.WithContext(ctx).
+		WithField("Lease", name).
+		WithField("Owner", owner)
+	defer log.TrackFuncTime(time.Now())
+
+	ctxUpd, cancel := context.WithTimeout(ctx, store.db.Timeout)
+	defer cancel()
+	now := time.Now().UTC()
+	// lease held by another owner does not match the filter, upsert of the same id fails then
+	filter := bson.D{
+		primitive.E{Key: "_id", Value: name},
+		primitive.E{Key: "$or", Value: bson.A{
+			bson.D{primitive.E{Key: "expires_at", Value: bson.D{primitive.E{Key: "$lte", Value: now}}}},
+			bson.D{primitive.E{Key: "owner", Value: owner}},
+		}},
+	}
+	update := bson.D{primitive.E{Key: "$set", Value: bson.D{
+		primitive.E{Key: "owner", Value: owner},
+		primitive.E{Key: "expires_at", Value: now.Add(ttl)},
+	}}}
+	_, err := store.coll.UpdateOne(ctxUpd, filter, update, options.Update().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		log.Debug("Lease is held by another owner")
+		return false, nil
+	}
+	if err != nil {
+		return false, apperrors.CreateErrorAndLogIt(log,
+			apperrors.ErrorDbOperation,
+			"Failed to update DB record", err)
+	}
+	log.Debug("Lease acquired")
+	return true, nil
+}
+
+// Release releases the lease if the owner holds it
+func (store *LeaseMongoRepository) Release(ctx context.Context, name, owner string) error {
+	log := store.log.WithContext(ctx).
+		WithField("Lease", name).
+		WithField("Owner", owner)
+	defer log.TrackFuncTime(time.Now())
+
+	ctxDel, cancel := context.WithTimeout(ctx, store.db.Timeout)
+	defer cancel()
+	filter := bson.D{
+		primitive.E{Key: "_id", Value: name},
+		primitive.E{Key: "owner", Value: owner},
+	}
+	if _, err := store.coll.DeleteOne(ctxDel, filter); err != nil {
+		return apperrors.CreateErrorAndLogIt(log,
+			apperrors.ErrorDbOperation,
+			"Failed to delete DB record", err)
+	}
+	log.Debug("Lease released")
+	return nil
+}
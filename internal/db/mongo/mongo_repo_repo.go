@@ -0,0 +1,178 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/shuvava/go-logging/logger"
+	"github.com/shuvava/go-ota-svc-common/apperrors"
+	intMongo "github.com/shuvava/go-ota-svc-common/db/mongo"
+
+	"github.com/shuvava/ota-tuf-server/internal/db"
+	"github.com/shuvava/ota-tuf-server/pkg/data"
+)
+
+const repoTableName = "tuf_repos"
+
+type roleConfigDTO struct {
+	Threshold int    `bson:"threshold"`
+	KeyCount  int    `bson:"key_count"`
+	KeyType   string `bson:"key_type,omitempty"`
+	Backend   string `bson:"backend,omitempty"`
+}
+
+type repoDTO struct {
+	ID          primitive.ObjectID       `bson:"_id,omitempty"`
+	RepoID      string                   `bson:"repo_id"`
+	KeyType     string                   `bson:"key_type"`
+	Roles       map[string]roleConfigDTO `bson:"roles"`
+	KeyEncoding string                   `bson:"key_encoding,omitempty"`
+}
+
+// RepoMongoRepository implementations of db.RepoRepository for MongoDb repo
+type RepoMongoRepository struct {
+	db   *intMongo.Db
+	coll *mongo.Collection
+	log  logger.Logger
+	db.RepoRepository
+}
+
+// NewRepoMongoRepository creates new instance of RepoMongoRepository
+func NewRepoMongoRepository(logger logger.Logger, db *intMongo.Db) *RepoMongoRepository {
+	log := logger.SetOperation("RepoRepo")
+	return &RepoMongoRepository{
+		db:   db,
+		coll: db.GetCollection(repoTableName),
+		log:  log,
+	}
+}
+
+// Create persist new data.Repo in database
+func (store *RepoMongoRepository) Create(ctx context.Context, obj data.Repo) error {
+	log := store.log.WithContext(ctx).
+		WithField("RepoID", obj.RepoID)
+	defer log.TrackFuncTime(time.Now())
+	log.Debug("Creating new Repo")
+
+	if err := insertOne(ctx, log, store.db, store.coll, repoToDTO(obj), ErrorRepoErrorDbAlreadyExist); err != nil {
+		log.Warn("Repo creation failed")
+		return err
+	}
+	log.Info("Repo created successful")
+	return nil
+}
+
+// FindByID returns data.Repo by repoID
+func (store *RepoMongoRepository) FindByID(ctx context.Context, repoID data.RepoID) (*data.Repo, error) {
+	log := store.log.WithContext(ctx).
+		WithField("RepoID", repoID)
+	defer log.TrackFuncTime(time.Now())
+	log.Debug("Looking up Repo")
+
+	var dto repoDTO
+	err := store.db.GetOne(ctx, store.coll, getRepoFilter(repoID), &dto)
+	if err != nil {
+		var typedErr apperrors.AppError
+		if errors.As(err, &typedErr) && typedErr.ErrorCode == apperrors.ErrorDbNoDocumentFound {
+			log.Warn("Repo not found")
+		}
+		return nil, err
+	}
+	return repoToModel(dto)
+}
+
+// Update replaces configuration of existing data.Repo
+func (store *RepoMongoRepository) Update(ctx context.Context, obj data.Repo) error {
+	log := store.log.WithContext(ctx).
+		WithField("RepoID", obj.RepoID)
+	defer log.TrackFuncTime(time.Now())
+	log.Debug("Updating Repo")
+
+	dto := repoToDTO(obj)
+	update := bson.D{primitive.E{Key: "$set", Value: bson.D{
+		primitive.E{Key: "key_type", Value: dto.KeyType},
+		primitive.E{Key: "roles", Value: dto.Roles},
+		primitive.E{Key: "key_encoding", Value: dto.KeyEncoding},
+	}}}
+	if err := store.db.UpdateOne(ctx, store.coll, getRepoFilter(obj.RepoID), update); err != nil {
+		log.Warn("Repo update failed")
+		return err
+	}
+	log.Info("Repo updated successful")
+	return nil
+}
+
+// Delete removes data.Repo from database
+func (store *RepoMongoRepository) Delete(ctx context.Context, repoID data.RepoID) error {
+	log := store.log.WithContext(ctx).
+		WithField("RepoID", repoID)
+	defer log.TrackFuncTime(time.Now())
+	log.Debug("Deleting Repo")
+
+	if err := store.db.Delete(ctx, store.coll, getRepoFilter(repoID)); err != nil {
+		return err
+	}
+	log.Info("Repo deleted successful")
+	return nil
+}
+
+// Exists checks if data.Repo exists in database
+func (store *RepoMongoRepository) Exists(ctx context.Context, repoID data.RepoID) (bool, error) {
+	log := store.log.WithContext(ctx)
+	defer log.TrackFuncTime(time.Now())
+	cnt, err := store.db.Count(ctx, store.coll, getRepoFilter(repoID))
+	if err != nil {
+		return false, err
+	}
+	return cnt > 0, nil
+}
+
+func repoToDTO(obj data.Repo) repoDTO {
+	roles := make(map[string]roleConfigDTO, len(obj.Roles))
+	for role, cfg := range obj.Roles {
+		roles[string(role)] = roleConfigDTO{
+			Threshold: cfg.Threshold,
+			KeyCount:  cfg.KeyCount,
+			KeyType:   string(cfg.KeyType),
+			Backend:   string(cfg.Backend),
+		}
+	}
+	return repoDTO{
+		ID:          primitive.NewObjectID(),
+		RepoID:      obj.RepoID.String(),
+		KeyType:     string(obj.KeyType),
+		Roles:       roles,
+		KeyEncoding: string(obj.KeyEncoding),
+	}
+}
+
+func repoToModel(dto repoDTO) (*data.Repo, error) {
+	repoID, err := data.RepoIDFromString(dto.RepoID)
+	if err != nil {
+		return nil, err
+	}
+	roles := make(map[data.RoleType]data.RoleConfig, len(dto.Roles))
+	for role, cfg := range dto.Roles {
+		roles[data.RoleType(role)] = data.RoleConfig{
+			Threshold: cfg.Threshold,
+			KeyCount:  cfg.KeyCount,
+			KeyType:   data.KeyType(cfg.KeyType),
+			Backend:   data.KeyBackend(cfg.Backend),
+		}
+	}
+	return &data.Repo{
+		RepoID:      repoID,
+		KeyType:     data.KeyType(dto.KeyType),
+		Roles:       roles,
+		KeyEncoding: data.KeyEncoding(dto.KeyEncoding),
+	}, nil
+}
+
+func getRepoFilter(repoID data.RepoID) bson.D {
+	return bson.D{primitive.E{Key: "repo_id", Value: repoID.String()}}
+}
@@ -0,0 +1,129 @@
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/shuvava/go-logging/logger"
+	"github.com/shuvava/go-ota-svc-common/apperrors"
+	intMongo "github.com/shuvava/go-ota-svc-common/db/mongo"
+
+	"github.com/shuvava/ota-tuf-server/internal/db"
+	"github.com/shuvava/ota-tuf-server/pkg/data"
+)
+
+const (
+	resignRecordTableName = "tuf_resign_records"
+	// resignRecordTTL is the number of seconds records are kept for
+	resignRecordTTL = int32(30 * 24 * 60 * 60)
+)
+
+type resignRecordDTO struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	RepoID    string             `bson:"repo_id"`
+	Role      string             `bson:"role"`
+	Version   int                `bson:"version"`
+	Owner     string             `bson:"owner"`
+	Error     string             `bson:"error,omitempty"`
+	CreatedAt time.Time          `bson:"created_at"`
+}
+
+// ResignRecordMongoRepository implementations of db.ResignRecordRepository for MongoDb repo
+type ResignRecordMongoRepository struct {
+	db   *intMongo.Db
+	coll *mongo.Collection
+	log  logger.Logger
+	db.ResignRecordRepository
+}
+
+// NewResignRecordMongoRepository creates new instance of ResignRecordMongoRepository
+func NewResignRecordMongoRepository(logger logger.Logger, db *intMongo.Db) *ResignRecordMongoRepository {
+	log := logger.SetOperation("ResignRecordRepo")
+	return &ResignRecordMongoRepository{
+		db:   db,
+		coll: db.GetCollection(resignRecordTableName),
+		log:  log,
+	}
+}
+
+// Create persist new data.ResignRecord in database
+func (store *ResignRecordMongoRepository) Create(ctx context.Context, obj data.ResignRecord) error {
+	log := store.log.WithContext(ctx).
+		WithField("RepoID", obj.RepoID).
+		WithField("Role", obj.Role)
+	defer log.TrackFuncTime(time.Now())
+	log.Debug("Creating new ResignRecord")
+
+	if err := insertOne(ctx, log, store.db, store.coll, resignRecordToDTO(obj), apperrors.ErrorDbAlreadyExist); err != nil {
+		log.Warn("ResignRecord creation failed")
+		return err
+	}
+	log.Debug("ResignRecord created successful")
+	return nil
+}
+
+// FindByRepoID returns data.ResignRecord of the repository, the newest records first
+func (store *ResignRecordMongoRepository) FindByRepoID(ctx context.Context, repoID data.RepoID) ([]data.ResignRecord, error) {
+	log := store.log.WithContext(ctx).
+		WithField("RepoID", repoID)
+	defer log.TrackFuncTime(time.Now())
+	log.Debug("Looking up ResignRecords")
+
+	ctxFind, cancel := context.WithTimeout(ctx, store.db.Timeout)
+	defer cancel()
+	opt := options.Find().SetSort(bson.D{primitive.E{Key: "created_at", Value: -1}})
+	filter := bson.D{primitive.E{Key: "repo_id", Value: repoID.String()}}
+	cur, err := store.coll.Find(ctxFind, filter, opt)
+	if err != nil {
+		return nil, apperrors.CreateErrorAndLogIt(log,
+			apperrors.ErrorDbOperation,
+			"Failed to find DB records", err)
+	}
+	var docs []resignRecordDTO
+	if err = cur.All(ctxFind, &docs); err != nil {
+		return nil, apperrors.CreateErrorAndLogIt(log,
+			apperrors.ErrorDbOperation,
+			"Failed to fetch DB records", err)
+	}
+	res := make([]data.ResignRecord, 0, len(docs))
+	for _, doc := range docs {
+		obj, err := resignRecordToModel(doc)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, *obj)
+	}
+	return res, nil
+}
+
+func resignRecordToDTO(obj data.ResignRecord) resignRecordDTO {
+	return resignRecordDTO{
+		ID:        primitive.NewObjectID(),
+		RepoID:    obj.RepoID.String(),
+		Role:      string(obj.Role),
+		Version:   obj.Version,
+		Owner:     obj.Owner,
+		Error:     obj.Error,
+		CreatedAt: obj.CreatedAt,
+	}
+}
+
+func resignRecordToModel(dto resignRecordDTO) (*data.ResignRecord, error) {
+	repoID, err := data.RepoIDFromString(dto.RepoID)
+	if err != nil {
+		return nil, err
+	}
+	return &data.ResignRecord{
+		RepoID:    repoID,
+		Role:      data.RoleType(dto.Role),
+		Version:   dto.Version,
+		Owner:     dto.Owner,
+		Error:     dto.Error,
+		CreatedAt: dto.CreatedAt.UTC(),
+	}, nil
+}
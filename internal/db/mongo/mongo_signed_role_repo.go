@@ -0,0 +1,239 @@
+package mongo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/shuvava/go-logging/logger"
+	"github.com/shuvava/go-ota-svc-common/apperrors"
+	intMongo "github.com/shuvava/go-ota-svc-common/db/mongo"
+
+	"github.com/shuvava/ota-tuf-server/internal/db"
+	"github.com/shuvava/ota-tuf-server/pkg/data"
+)
+
+const signedRoleTableName = "tuf_signed_roles"
+
+type signedRoleDTO struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	RepoID    string             `bson:"repo_id"`
+	Role      string             `bson:"role"`
+	Version   int                `bson:"version"`
+	ExpiresAt time.Time          `bson:"expires_at"`
+	// Latest marks the highest version of the role, expiring metadata is looked up among latest versions only
+	Latest bool `bson:"latest"`
+	// Content is serialized data.SignedPayload, it is stored as is to keep published metadata byte-exact
+	Content string `bson:"content"`
+}
+
+// SignedRoleMongoRepository implementations of db.SignedRoleRepository for MongoDb repo
+type SignedRoleMongoRepository struct {
+	db   *intMongo.Db
+	coll *mongo.Collection
+	log  logger.Logger
+	db.SignedRoleRepository
+}
+
+// NewSignedRoleMongoRepository creates new instance of SignedRoleMongoRepository
+func NewSignedRoleMongoRepository(logger logger.Logger, db *intMongo.Db) *SignedRoleMongoRepository {
+	log := logger.SetOperation("SignedRoleRepo")
+	return &SignedRoleMongoRepository{
+		db:   db,
+		coll: db.GetCollection(signedRoleTableName),
+		log:  log,
+	}
+}
+
+// Create persist new version of data.SignedRole in database
+func (store *SignedRoleMongoRepository) Create(ctx context.Context, obj data.SignedRole) error {
+	log := store.log.WithContext(ctx).
+		WithField("RepoID", obj.RepoID).
+		WithField("Role", obj.Role).
+		WithField("Version", obj.Version)
+	defer log.TrackFuncTime(time.Now())
+	log.Debug("Creating new SignedRole")
+
+	dto, err := signedRoleToDTO(obj)
+	if err != nil {
+		return err
+	}
+	dto.Latest = true
+	if err = insertOne(ctx, log, store.db, store.coll, dto, ErrorSignedRoleErrorDbAlreadyExist); err != nil {
+		log.Warn("SignedRole creation failed")
+		return err
+	}
+	log.Info("SignedRole created successful")
+	// failure leaves previous version marked latest, re-signing of expiring metadata re-checks the latest version
+	ctxUpd, cancel := context.WithTimeout(ctx, store.db.Timeout)
+	defer cancel()
+	filter := append(getSignedRoleFilter(obj.RepoID, obj.Role),
+		primitive.E{Key: "version", Value: bson.D{primitive.E{Key: "$lt", Value: obj.Version}}},
+		primitive.E{Key: "latest", Value: true},
+	)
+	update := bson.D{primitive.E{Key: "$set", Value: bson.D{primitive.E{Key: "latest", Value: false}}}}
+	if _, err = store.coll.UpdateMany(ctxUpd, filter, update); err != nil {
+		log.WithError(err).
+			Warn("Failed to unmark previous SignedRole versions latest")
+	}
+	return nil
+}
+
+// FindVersion returns data.SignedRole of the role by version
+func (store *SignedRoleMongoRepository) FindVersion(ctx context.Context, repoID data.RepoID, role data.RoleType, version int) (*data.SignedRole, error) {
+	log := store.log.WithContext(ctx).
+		WithField("RepoID", repoID).
+		WithField("Role", role).
+		WithField("Version", version)
+	defer log.TrackFuncTime(time.Now())
+	log.Debug("Looking up SignedRole")
+
+	var dto signedRoleDTO
+	err := store.db.GetOne(ctx, store.coll, getSignedRoleVersionFilter(repoID, role, version), &dto)
+	if err != nil {
+		var typedErr apperrors.AppError
+		if errors.As(err, &typedErr) && typedErr.ErrorCode == apperrors.ErrorDbNoDocumentFound {
+			log.Warn("SignedRole not found")
+		}
+		return nil, err
+	}
+	return signedRoleToModel(dto)
+}
+
+// FindLatest returns data.SignedRole of the role with the highest version
+func (store *SignedRoleMongoRepository) FindLatest(ctx context.Context, repoID data.RepoID, role data.RoleType) (*data.SignedRole, error) {
+	log := store.log.WithContext(ctx).
+		WithField("RepoID", repoID).
+		WithField("Role", role)
+	defer log.TrackFuncTime(time.Now())
+	log.Debug("Looking up latest SignedRole")
+
+	ctxGet, cancel := context.WithTimeout(ctx, store.db.Timeout)
+	defer cancel()
+	opt := options.FindOne().SetSort(bson.D{primitive.E{Key: "version", Value: -1}})
+	var dto signedRoleDTO
+	err := store.coll.FindOne(ctxGet, getSignedRoleFilter(repoID, role), opt).Decode(&dto)
+	if err == mongo.ErrNoDocuments {
+		log.Debug("SignedRole not found")
+		return nil, apperrors.NewAppError(apperrors.ErrorDbNoDocumentFound, "document not found")
+	}
+	if err != nil {
+		return nil, apperrors.CreateErrorAndLogIt(log,
+			apperrors.ErrorDbOperation,
+			"Failed to get DB record", err)
+	}
+	return signedRoleToModel(dto)
+}
+
+// ListVersions returns all published versions of the role in ascending order
+func (store *SignedRoleMongoRepository) ListVersions(ctx context.Context, repoID data.RepoID, role data.RoleType) ([]int, error) {
+	log := store.log.WithContext(ctx).
+		WithField("RepoID", repoID).
+		WithField("Role", role)
+	defer log.TrackFuncTime(time.Now())
+	log.Debug("Looking up SignedRole versions")
+
+	ctxFind, cancel := context.WithTimeout(ctx, store.db.Timeout)
+	defer cancel()
+	opt := options.Find().
+		SetSort(bson.D{primitive.E{Key: "version", Value: 1}}).
+		SetProjection(bson.D{primitive.E{Key: "version", Value: 1}})
+	cur, err := store.coll.Find(ctxFind, getSignedRoleFilter(repoID, role), opt)
+	if err != nil {
+		return nil, apperrors.CreateErrorAndLogIt(log,
+			apperrors.ErrorDbOperation,
+			"Failed to find DB records", err)
+	}
+	var docs []signedRoleDTO
+	if err = cur.All(ctxFind, &docs); err != nil {
+		return nil, apperrors.CreateErrorAndLogIt(log,
+			apperrors.ErrorDbOperation,
+			"Failed to fetch DB records", err)
+	}
+	res := make([]int, len(docs))
+	for i, doc := range docs {
+		res[i] = doc.Version
+	}
+	return res, nil
+}
+
+// FindExpiring returns data.SignedRole with the highest version of the role of all repositories
+// which expires before the time
+func (store *SignedRoleMongoRepository) FindExpiring(ctx context.Context, role data.RoleType, before time.Time) ([]data.SignedRole, error) {
+	log := store.log.WithContext(ctx).
+		WithField("Role", role).
+		WithField("Before", before)
+	defer log.TrackFuncTime(time.Now())
+	log.Debug("Looking up expiring SignedRoles")
+
+	filter := bson.D{
+		primitive.E{Key: "role", Value: string(role)},
+		primitive.E{Key: "latest", Value: true},
+		primitive.E{Key: "expires_at", Value: bson.D{primitive.E{Key: "$lt", Value: before}}},
+	}
+	var docs []signedRoleDTO
+	if err := store.db.Find(ctx, store.coll, filter, &docs); err != nil {
+		return nil, err
+	}
+	res := make([]data.SignedRole, 0, len(docs))
+	for _, doc := range docs {
+		obj, err := signedRoleToModel(doc)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, *obj)
+	}
+	log.WithField("Count", len(res)).
+		Debug("Lookup completed successful")
+	return res, nil
+}
+
+func signedRoleToDTO(obj data.SignedRole) (signedRoleDTO, error) {
+	content, err := json.Marshal(obj.Content)
+	if err != nil {
+		return signedRoleDTO{}, apperrors.CreateError(apperrors.ErrorDataSerialization, "failed to marshal signed role", err)
+	}
+	return signedRoleDTO{
+		ID:        primitive.NewObjectID(),
+		RepoID:    obj.RepoID.String(),
+		Role:      string(obj.Role),
+		Version:   obj.Version,
+		ExpiresAt: obj.ExpiresAt,
+		Content:   string(content),
+	}, nil
+}
+
+func signedRoleToModel(dto signedRoleDTO) (*data.SignedRole, error) {
+	repoID, err := data.RepoIDFromString(dto.RepoID)
+	if err != nil {
+		return nil, err
+	}
+	var content data.SignedPayload
+	if err = json.Unmarshal([]byte(dto.Content), &content); err != nil {
+		return nil, apperrors.CreateError(apperrors.ErrorDataSerialization, "failed to unmarshal signed role", err)
+	}
+	return &data.SignedRole{
+		RepoID:    repoID,
+		Role:      data.RoleType(dto.Role),
+		Version:   dto.Version,
+		ExpiresAt: dto.ExpiresAt.UTC(),
+		Content:   content,
+	}, nil
+}
+
+func getSignedRoleFilter(repoID data.RepoID, role data.RoleType) bson.D {
+	return bson.D{
+		primitive.E{Key: "repo_id", Value: repoID.String()},
+		primitive.E{Key: "role", Value: string(role)},
+	}
+}
+
+func getSignedRoleVersionFilter(repoID data.RepoID, role data.RoleType, version int) bson.D {
+	return append(getSignedRoleFilter(repoID, role), primitive.E{Key: "version", Value: version})
+}
@@ -0,0 +1,59 @@
+package mongo_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/shuvava/go-logging/logger"
+
+	"github.com/shuvava/ota-tuf-server/internal/db/mongo"
+	"github.com/shuvava/ota-tuf-server/pkg/data"
+)
+
+func TestSignedRoleFindExpiring(t *testing.T) {
+	ctx := context.Background()
+	t.Run("should return only the latest version of expiring role", func(t *testing.T) {
+		db := newTestDb(t)
+		repo := mongo.NewSignedRoleMongoRepository(logger.NewNopLogger(), db)
+		repoID := data.NewRepoID()
+		t.Cleanup(func() {
+			_, _ = db.GetCollection("tuf_signed_roles").DeleteMany(ctx, bson.D{{Key: "repo_id", Value: repoID.String()}})
+		})
+		now := time.Now().UTC().Truncate(time.Millisecond)
+		for version := 1; version <= 2; version++ {
+			obj := data.SignedRole{
+				RepoID:    repoID,
+				Role:      data.RoleTypeTimestamp,
+				Version:   version,
+				ExpiresAt: now.Add(time.Duration(version) * time.Hour),
+				Content:   data.SignedPayload{Signatures: []data.Signature{}, Signed: json.RawMessage(`{}`)},
+			}
+			if err := repo.Create(ctx, obj); err != nil {
+				t.Fatalf("unable to create version %d: %v", version, err)
+			}
+		}
+		var found []data.SignedRole
+		roles, err := repo.FindExpiring(ctx, data.RoleTypeTimestamp, now.Add(3*time.Hour))
+		if err != nil {
+			t.Fatalf("unable to find expiring roles: %v", err)
+		}
+		for _, obj := range roles {
+			if obj.RepoID == repoID {
+				found = append(found, obj)
+			}
+		}
+		if len(found) != 1 || found[0].Version != 2 {
+			t.Errorf("expected latest version 2, got %v", found)
+		}
+		roles, _ = repo.FindExpiring(ctx, data.RoleTypeTimestamp, now.Add(90*time.Minute))
+		for _, obj := range roles {
+			if obj.RepoID == repoID {
+				t.Errorf("expected previous version skipped, got version %d", obj.Version)
+			}
+		}
+	})
+}
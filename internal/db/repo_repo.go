@@ -0,0 +1,21 @@
+package db
+
+import (
+	"context"
+
+	"github.com/shuvava/ota-tuf-server/pkg/data"
+)
+
+// RepoRepository is the interface for the data.Repo repository.
+type RepoRepository interface {
+	// Create persist new data.Repo in database
+	Create(ctx context.Context, obj data.Repo) error
+	// FindByID returns data.Repo by repoID
+	FindByID(ctx context.Context, repoID data.RepoID) (*data.Repo, error)
+	// Update replaces configuration of existing data.Repo
+	Update(ctx context.Context, obj data.Repo) error
+	// Delete removes data.Repo from database
+	Delete(ctx context.Context, repoID data.RepoID) error
+	// Exists checks if data.Repo exists in database
+	Exists(ctx context.Context, repoID data.RepoID) (bool, error)
+}
@@ -0,0 +1,15 @@
+package db
+
+import (
+	"context"
+
+	"github.com/shuvava/ota-tuf-server/pkg/data"
+)
+
+// ResignRecordRepository is the interface for the data.ResignRecord repository.
+type ResignRecordRepository interface {
+	// Create persist new data.ResignRecord in database
+	Create(ctx context.Context, obj data.ResignRecord) error
+	// FindByRepoID returns data.ResignRecord of the repository, the newest records first
+	FindByRepoID(ctx context.Context, repoID data.RepoID) ([]data.ResignRecord, error)
+}
@@ -0,0 +1,207 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/shuvava/go-logging/logger"
+	"github.com/shuvava/go-ota-svc-common/apperrors"
+
+	"github.com/shuvava/ota-tuf-server/internal/keyring"
+	"github.com/shuvava/ota-tuf-server/pkg/data"
+	"github.com/shuvava/ota-tuf-server/pkg/encryption"
+	"github.com/shuvava/ota-tuf-server/pkg/errcodes"
+)
+
+// sealedKeyValue is the stored value of a key which private part is sealed,
+// it is read by encryption.UnmarshalKey as a key without private part
+type sealedKeyValue struct {
+	Public json.RawMessage   `json:"public"`
+	Sealed *keyring.Envelope `json:"sealed,omitempty"`
+}
+
+// SealedKeyRepository is KeyRepository decorator encrypting private keys at rest.
+// Keys are sealed on write and opened on read, keys without private part are stored as is.
+type SealedKeyRepository struct {
+	KeyRepository
+	log     logger.Logger
+	keyring *keyring.Keyring
+}
+
+// NewSealedKeyRepository creates new instance of SealedKeyRepository storing keys in inner repository
+func NewSealedKeyRepository(l logger.Logger, inner KeyRepository, kr *keyring.Keyring) *SealedKeyRepository {
+	return &SealedKeyRepository{
+		KeyRepository: inner,
+		log:           l.SetOperation("SealedKeyRepo"),
+		keyring:       kr,
+	}
+}
+
+// Create persist new data.RepoKey with sealed private part
+func (r *SealedKeyRepository) Create(ctx context.Context, obj data.RepoKey) error {
+	sealed, err := r.seal(obj)
+	if err != nil {
+		return err
+	}
+	return r.KeyRepository.Create(ctx, sealed)
+}
+
+// Update replaces data.RepoKey, private part is sealed
+func (r *SealedKeyRepository) Update(ctx context.Context, obj data.RepoKey) error {
+	sealed, err := r.seal(obj)
+	if err != nil {
+		return err
+	}
+	return r.KeyRepository.Update(ctx, sealed)
+}
+
+// FindAll returns data.RepoKey of all repositories with opened private part
+func (r *SealedKeyRepository) FindAll(ctx context.Context) ([]data.RepoKey, error) {
+	keys, err := r.KeyRepository.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.openAll(keys)
+}
+
+// FindByRepoId returns data.RepoKey by repoId with opened private part
+func (r *SealedKeyRepository) FindByRepoId(ctx context.Context, repoID data.RepoID) ([]data.RepoKey, error) {
+	keys, err := r.KeyRepository.FindByRepoId(ctx, repoID)
+	if err != nil {
+		return nil, err
+	}
+	return r.openAll(keys)
+}
+
+// FindByRole returns all data.RepoKey of the repository role with opened private part
+func (r *SealedKeyRepository) FindByRole(ctx context.Context, repoID data.RepoID, role data.RoleType) ([]data.RepoKey, error) {
+	keys, err := r.KeyRepository.FindByRole(ctx, repoID, role)
+	if err != nil {
+		return nil, err
+	}
+	return r.openAll(keys)
+}
+
+// FindByKeyID returns data.RepoKey by keyID with opened private part
+func (r *SealedKeyRepository) FindByKeyID(ctx context.Context, repoID data.RepoID, keyID data.KeyID) (*data.RepoKey, error) {
+	key, err := r.KeyRepository.FindByKeyID(ctx, repoID, keyID)
+	if err != nil {
+		return nil, err
+	}
+	opened, err := r.open(*key)
+	if err != nil {
+		return nil, err
+	}
+	return &opened, nil
+}
+
+// MarkExported sets export status of data.RepoKey and returns the updated key with opened private part
+func (r *SealedKeyRepository) MarkExported(ctx context.Context, repoID data.RepoID, keyID data.KeyID) (*data.RepoKey, error) {
+	key, err := r.KeyRepository.MarkExported(ctx, repoID, keyID)
+	if err != nil {
+		return nil, err
+	}
+	opened, err := r.open(*key)
+	if err != nil {
+		return nil, err
+	}
+	return &opened, nil
+}
+
+// RotateMasterKey wraps data keys of all stored keys by the active master key
+// and seals private keys stored in clear. Sealed private keys are not re-encrypted.
+// It returns the number of updated keys.
+func (r *SealedKeyRepository) RotateMasterKey(ctx context.Context) (int, error) {
+	log := r.log.WithContext(ctx).
+		WithField("MasterKeyID", r.keyring.ActiveKeyID())
+	keys, err := r.KeyRepository.FindAll(ctx)
+	if err != nil {
+		return 0, err
+	}
+	cnt := 0
+	for _, key := range keys {
+		var val sealedKeyValue
+		if err = json.Unmarshal(key.Key.Value, &val); err != nil {
+			return cnt, apperrors.CreateError(apperrors.ErrorDataSerialization, "failed to unmarshal key", err)
+		}
+		switch {
+		case val.Sealed == nil && encryption.HasPrivateKey(&key.Key):
+			if key, err = r.seal(key); err != nil {
+				return cnt, err
+			}
+		case val.Sealed != nil && val.Sealed.MasterKeyID != r.keyring.ActiveKeyID():
+			if val.Sealed, err = r.keyring.Rewrap(val.Sealed); err != nil {
+				return cnt, err
+			}
+			if key.Key.Value, err = json.Marshal(val); err != nil {
+				return cnt, apperrors.CreateError(apperrors.ErrorDataSerialization, "failed to marshal key", err)
+			}
+		default:
+			continue
+		}
+		if err = r.KeyRepository.Update(ctx, key); err != nil {
+			return cnt, err
+		}
+		cnt++
+	}
+	log.WithField("Count", cnt).
+		Info("Master key rotation completed")
+	return cnt, nil
+}
+
+// seal replaces private part of the key by envelope
+func (r *SealedKeyRepository) seal(obj data.RepoKey) (data.RepoKey, error) {
+	if !encryption.HasPrivateKey(&obj.Key) {
+		return obj, nil
+	}
+	pub, err := encryption.PublicKey(&obj.Key)
+	if err != nil {
+		return obj, err
+	}
+	var val sealedKeyValue
+	if err = json.Unmarshal(pub.Value, &val); err != nil {
+		return obj, apperrors.CreateError(apperrors.ErrorDataSerialization, "failed to unmarshal key", err)
+	}
+	if val.Sealed, err = r.keyring.Seal(obj.Key.Value, additionalData(obj)); err != nil {
+		return obj, err
+	}
+	if obj.Key.Value, err = json.Marshal(val); err != nil {
+		return obj, apperrors.CreateError(apperrors.ErrorDataSerialization, "failed to marshal key", err)
+	}
+	return obj, nil
+}
+
+// open restores private part of the key sealed by seal
+func (r *SealedKeyRepository) open(obj data.RepoKey) (data.RepoKey, error) {
+	var val sealedKeyValue
+	if err := json.Unmarshal(obj.Key.Value, &val); err != nil {
+		return obj, apperrors.CreateError(apperrors.ErrorDataSerialization, "failed to unmarshal key", err)
+	}
+	if val.Sealed == nil {
+		return obj, nil
+	}
+	plain, err := r.keyring.Open(val.Sealed, additionalData(obj))
+	if err != nil {
+		return obj, apperrors.CreateError(errcodes.ErrorDataEncryption,
+			"failed to open private part of key '"+obj.KeyID.String()+"'", err)
+	}
+	obj.Key.Value = plain
+	return obj, nil
+}
+
+func (r *SealedKeyRepository) openAll(keys []data.RepoKey) ([]data.RepoKey, error) {
+	res := make([]data.RepoKey, 0, len(keys))
+	for _, key := range keys {
+		opened, err := r.open(key)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, opened)
+	}
+	return res, nil
+}
+
+// additionalData binds sealed private part to the key, so it can not be moved to another key
+func additionalData(obj data.RepoKey) []byte {
+	return []byte(obj.RepoID.String() + "/" + obj.KeyID.String())
+}
@@ -0,0 +1,24 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/shuvava/ota-tuf-server/pkg/data"
+)
+
+// SignedRoleRepository is the interface for the data.SignedRole repository.
+// Published versions are immutable, repository only allows adding new versions.
+type SignedRoleRepository interface {
+	// Create persist new version of data.SignedRole in database
+	Create(ctx context.Context, obj data.SignedRole) error
+	// FindVersion returns data.SignedRole of the role by version
+	FindVersion(ctx context.Context, repoID data.RepoID, role data.RoleType, version int) (*data.SignedRole, error)
+	// FindLatest returns data.SignedRole of the role with the highest version
+	FindLatest(ctx context.Context, repoID data.RepoID, role data.RoleType) (*data.SignedRole, error)
+	// ListVersions returns all published versions of the role in ascending order
+	ListVersions(ctx context.Context, repoID data.RepoID, role data.RoleType) ([]int, error)
+	// FindExpiring returns data.SignedRole with the highest version of the role of all repositories
+	// which expires before the time
+	FindExpiring(ctx context.Context, role data.RoleType, before time.Time) ([]data.SignedRole, error)
+}
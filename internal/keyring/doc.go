@@ -0,0 +1,3 @@
+// Package keyring implements envelope encryption of secrets stored in database.
+// Every secret is sealed by its own data key, data keys are wrapped by master keys of the keyring.
+package keyring
@@ -0,0 +1,124 @@
+package keyring
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+
+	"github.com/shuvava/go-ota-svc-common/apperrors"
+
+	"github.com/shuvava/ota-tuf-server/pkg/errcodes"
+)
+
+// dataKeySize is the size of AES-256 data key
+const dataKeySize = 32
+
+// Envelope is a secret sealed by the data key wrapped by the master key
+type Envelope struct {
+	// MasterKeyID is the id of the master key wrapping DataKey
+	MasterKeyID string `json:"mkid"`
+	// DataKey is the data key encrypted by the master key
+	DataKey []byte `json:"dek"`
+	// Ciphertext is the secret encrypted by the data key
+	Ciphertext []byte `json:"ct"`
+}
+
+// Seal encrypts plaintext by a new data key and wraps the data key by the active master key.
+// Additional data is authenticated but not encrypted, the same value has to be provided to Open.
+func (k *Keyring) Seal(plaintext, additionalData []byte) (*Envelope, error) {
+	dek := make([]byte, dataKeySize)
+	if _, err := rand.Read(dek); err != nil {
+		return nil, apperrors.CreateError(errcodes.ErrorDataEncryption, "failed to generate data key", err)
+	}
+	ciphertext, err := encrypt(dek, plaintext, additionalData)
+	if err != nil {
+		return nil, err
+	}
+	wrapped, err := encrypt(k.keys[k.active], dek, []byte(k.active))
+	if err != nil {
+		return nil, err
+	}
+	return &Envelope{
+		MasterKeyID: k.active,
+		DataKey:     wrapped,
+		Ciphertext:  ciphertext,
+	}, nil
+}
+
+// Open decrypts the secret sealed by Seal
+func (k *Keyring) Open(env *Envelope, additionalData []byte) ([]byte, error) {
+	dek, err := k.unwrap(env)
+	if err != nil {
+		return nil, err
+	}
+	return decrypt(dek, env.Ciphertext, additionalData)
+}
+
+// Rewrap wraps the data key of the envelope by the active master key, the sealed secret is not changed
+func (k *Keyring) Rewrap(env *Envelope) (*Envelope, error) {
+	dek, err := k.unwrap(env)
+	if err != nil {
+		return nil, err
+	}
+	wrapped, err := encrypt(k.keys[k.active], dek, []byte(k.active))
+	if err != nil {
+		return nil, err
+	}
+	return &Envelope{
+		MasterKeyID: k.active,
+		DataKey:     wrapped,
+		Ciphertext:  env.Ciphertext,
+	}, nil
+}
+
+// unwrap decrypts the data key of the envelope
+func (k *Keyring) unwrap(env *Envelope) ([]byte, error) {
+	master, ok := k.keys[env.MasterKeyID]
+	if !ok {
+		return nil, apperrors.NewAppError(errcodes.ErrorDataEncryptionMasterKey,
+			"master key '"+env.MasterKeyID+"' is not defined")
+	}
+	return decrypt(master, env.DataKey, []byte(env.MasterKeyID))
+}
+
+// encrypt encrypts plaintext by AES-GCM, the nonce is prepended to the ciphertext
+func encrypt(key, plaintext, additionalData []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err = rand.Read(nonce); err != nil {
+		return nil, apperrors.CreateError(errcodes.ErrorDataEncryption, "failed to generate nonce", err)
+	}
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+// decrypt decrypts ciphertext produced by encrypt
+func decrypt(key, ciphertext, additionalData []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, apperrors.NewAppError(errcodes.ErrorDataEncryption, "ciphertext is too short")
+	}
+	nonce, ciphertext := ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, additionalData)
+	if err != nil {
+		return nil, apperrors.CreateError(errcodes.ErrorDataEncryption, "failed to decrypt", err)
+	}
+	return plaintext, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, apperrors.CreateError(errcodes.ErrorDataEncryption, "failed to create cipher", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, apperrors.CreateError(errcodes.ErrorDataEncryption, "failed to create cipher", err)
+	}
+	return aead, nil
+}
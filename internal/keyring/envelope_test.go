@@ -0,0 +1,87 @@
+package keyring_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+
+	"github.com/shuvava/ota-tuf-server/internal/keyring"
+)
+
+func newMasterKey(t *testing.T) []byte {
+	t.Helper()
+	key := make([]byte, keyring.MasterKeySize)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatalf("unable to generate master key: %v", err)
+	}
+	return key
+}
+
+func TestKeyring(t *testing.T) {
+	secret := []byte(`{"private":"secret"}`)
+	aad := []byte("repo/key")
+	oldKey, newKey := newMasterKey(t), newMasterKey(t)
+	oldRing, err := keyring.New("old", map[string][]byte{"old": oldKey})
+	if err != nil {
+		t.Fatalf("unable to create keyring: %v", err)
+	}
+	newRing, err := keyring.New("new", map[string][]byte{"old": oldKey, "new": newKey})
+	if err != nil {
+		t.Fatalf("unable to create keyring: %v", err)
+	}
+
+	t.Run("should open sealed secret", func(t *testing.T) {
+		env, err := oldRing.Seal(secret, aad)
+		if err != nil {
+			t.Fatalf("unable to seal: %v", err)
+		}
+		if bytes.Contains(env.Ciphertext, secret) {
+			t.Error("ciphertext contains secret")
+		}
+		got, err := oldRing.Open(env, aad)
+		if err != nil {
+			t.Fatalf("unable to open: %v", err)
+		}
+		if !bytes.Equal(got, secret) {
+			t.Errorf("expected %s, got %s", secret, got)
+		}
+	})
+	t.Run("should fail on different additional data", func(t *testing.T) {
+		env, _ := oldRing.Seal(secret, aad)
+		if _, err := oldRing.Open(env, []byte("repo/other")); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+	t.Run("should rewrap data key without changing ciphertext", func(t *testing.T) {
+		env, _ := oldRing.Seal(secret, aad)
+		rewrapped, err := newRing.Rewrap(env)
+		if err != nil {
+			t.Fatalf("unable to rewrap: %v", err)
+		}
+		if rewrapped.MasterKeyID != "new" {
+			t.Errorf("expected master key 'new', got '%s'", rewrapped.MasterKeyID)
+		}
+		if !bytes.Equal(rewrapped.Ciphertext, env.Ciphertext) {
+			t.Error("ciphertext should not be changed")
+		}
+		onlyNew, _ := keyring.New("new", map[string][]byte{"new": newKey})
+		got, err := onlyNew.Open(rewrapped, aad)
+		if err != nil {
+			t.Fatalf("unable to open: %v", err)
+		}
+		if !bytes.Equal(got, secret) {
+			t.Errorf("expected %s, got %s", secret, got)
+		}
+		if _, err = onlyNew.Open(env, aad); err == nil {
+			t.Error("expected error on unknown master key, got nil")
+		}
+	})
+	t.Run("should reject invalid configuration", func(t *testing.T) {
+		if _, err := keyring.New("old", map[string][]byte{"old": oldKey[:16]}); err == nil {
+			t.Error("expected error on short key, got nil")
+		}
+		if _, err := keyring.New("missing", map[string][]byte{"old": oldKey}); err == nil {
+			t.Error("expected error on missing active key, got nil")
+		}
+	})
+}
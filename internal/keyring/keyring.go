@@ -0,0 +1,70 @@
+package keyring
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"os"
+
+	"github.com/shuvava/go-ota-svc-common/apperrors"
+
+	"github.com/shuvava/ota-tuf-server/pkg/errcodes"
+)
+
+// MasterKeySize is the size of AES-256 master key
+const MasterKeySize = 32
+
+// Keyring is the set of master keys, the active one wraps new data keys
+type Keyring struct {
+	active string
+	keys   map[string][]byte
+}
+
+// New creates new instance of Keyring
+func New(activeID string, keys map[string][]byte) (*Keyring, error) {
+	for id, key := range keys {
+		if len(key) != MasterKeySize {
+			return nil, apperrors.NewAppError(errcodes.ErrorDataEncryptionMasterKey,
+				"master key '"+id+"' must be 256 bits long")
+		}
+	}
+	if _, ok := keys[activeID]; !ok {
+		return nil, apperrors.NewAppError(errcodes.ErrorDataEncryptionMasterKey,
+			"active master key '"+activeID+"' is not defined")
+	}
+	return &Keyring{
+		active: activeID,
+		keys:   keys,
+	}, nil
+}
+
+// ActiveKeyID returns id of the master key wrapping new data keys
+func (k *Keyring) ActiveKeyID() string {
+	return k.active
+}
+
+// ParseKeys decodes base64 encoded master keys
+func ParseKeys(encoded map[string]string) (map[string][]byte, error) {
+	res := make(map[string][]byte, len(encoded))
+	for id, val := range encoded {
+		key, err := base64.StdEncoding.DecodeString(val)
+		if err != nil {
+			return nil, apperrors.CreateError(errcodes.ErrorDataEncryptionMasterKey,
+				"failed to decode master key '"+id+"'", err)
+		}
+		res[id] = key
+	}
+	return res, nil
+}
+
+// LoadKeysFile reads base64 encoded master keys from JSON file of format {"<id>": "<base64 key>"}
+func LoadKeysFile(path string) (map[string]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, apperrors.CreateError(apperrors.ErrorFsIOOpen, "failed to read master keys file", err)
+	}
+	var res map[string]string
+	if err = json.Unmarshal(content, &res); err != nil {
+		return nil, apperrors.CreateError(errcodes.ErrorDataEncryptionMasterKey, "failed to parse master keys file", err)
+	}
+	return res, nil
+}
@@ -0,0 +1,178 @@
+package cjson_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/shuvava/ota-tuf-server/pkg/cjson"
+)
+
+func TestCanonicalize(t *testing.T) {
+	// vectors are taken from securesystemslib (python-tuf) and go-tuf canonical JSON test suites
+	cases := []struct {
+		Name  string
+		Input string
+		Want  string
+	}{
+		{Name: "empty object", Input: `{}`, Want: `{}`},
+		{Name: "empty array", Input: `[]`, Want: `[]`},
+		{Name: "empty string", Input: `""`, Want: `""`},
+		{Name: "null", Input: `null`, Want: `null`},
+		{Name: "true", Input: `true`, Want: `true`},
+		{Name: "false", Input: `false`, Want: `false`},
+		{Name: "zero", Input: `0`, Want: `0`},
+		{Name: "negative zero", Input: `-0`, Want: `0`},
+		{Name: "negative integer", Input: `-123`, Want: `-123`},
+		{Name: "max int64", Input: `9223372036854775807`, Want: `9223372036854775807`},
+		{Name: "min int64", Input: `-9223372036854775808`, Want: `-9223372036854775808`},
+		{Name: "max uint64", Input: `18446744073709551615`, Want: `18446744073709551615`},
+		{Name: "array of integers", Input: `[1, 2, 3]`, Want: `[1,2,3]`},
+		{Name: "nested array", Input: `{"A": [99]}`, Want: `{"A":[99]}`},
+		{Name: "sorted keys", Input: `{"x": 3, "y": 2}`, Want: `{"x":3,"y":2}`},
+		{Name: "unsorted keys", Input: `{"y": 2, "x": 3}`, Want: `{"x":3,"y":2}`},
+		{Name: "null value", Input: `{"x": 3, "y": null}`, Want: `{"x":3,"y":null}`},
+		{Name: "uppercase keys go first", Input: `{"b": 1, "a": 2, "B": 3, "A": 4}`, Want: `{"A":4,"B":3,"a":2,"b":1}`},
+		{Name: "key prefix goes first", Input: `{"ab": 1, "a": 2}`, Want: `{"a":2,"ab":1}`},
+		{Name: "underscore keys", Input: `{"version": 1, "_type": "root"}`, Want: `{"_type":"root","version":1}`},
+		{Name: "keys sorted by code points", Input: `{"é": 1, "z": 2, "中": 3}`, Want: "{\"z\":2,\"é\":1,\"中\":3}"},
+		{Name: "nested objects", Input: `{"b": {"d": 1, "c": [true, false, null]}, "a": {}}`, Want: `{"a":{},"b":{"c":[true,false,null],"d":1}}`},
+		{Name: "whitespace is removed", Input: " {\n\t\"a\" : [ 1 , 2 ] \r\n} ", Want: `{"a":[1,2]}`},
+		{Name: "quote is escaped", Input: `"a\"b"`, Want: `"a\"b"`},
+		{Name: "backslash is escaped", Input: `"a\\b"`, Want: `"a\\b"`},
+		{Name: "solidus is not escaped", Input: `"a\/b"`, Want: `"a/b"`},
+		{Name: "control characters are not escaped", Input: `"a\nb\tc\u0001"`, Want: "\"a\nb\tc\x01\""},
+		{Name: "unicode escapes are decoded", Input: `"é中"`, Want: "\"é中\""},
+		{Name: "html characters are not escaped", Input: `"<a&b>"`, Want: `"<a&b>"`},
+		{Name: "surrogate pair", Input: `"😀"`, Want: "\"\U0001F600\""},
+		{Name: "tuf key", Input: `{"keyval": {"public": "abc"}, "keytype": "ed25519", "scheme": "ed25519"}`,
+			Want: `{"keytype":"ed25519","keyval":{"public":"abc"},"scheme":"ed25519"}`},
+		{Name: "tuf root", Input: `{"signed": {"roles": {"root": {"threshold": 1, "keyids": ["a"]}}, "expires": "2030-01-01T00:00:00Z", "_type": "root"}, "signatures": []}`,
+			Want: `{"signatures":[],"signed":{"_type":"root","expires":"2030-01-01T00:00:00Z","roles":{"root":{"keyids":["a"],"threshold":1}}}}`},
+	}
+	for _, test := range cases {
+		t.Run(test.Name, func(t *testing.T) {
+			got, err := cjson.Canonicalize([]byte(test.Input))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(got) != test.Want {
+				t.Errorf("expected %s, got %s", test.Want, got)
+			}
+			// raw control characters are allowed in canonical form, but not in JSON parsed by encoding/json
+			if json.Valid(got) && !cjson.IsCanonical(got) {
+				t.Errorf("canonical form %s is not canonical", got)
+			}
+		})
+	}
+}
+
+func TestCanonicalizeErrors(t *testing.T) {
+	cases := []struct {
+		Name  string
+		Input string
+	}{
+		{Name: "float", Input: `1.5`},
+		{Name: "float with zero fraction", Input: `{"a": 1.0}`},
+		{Name: "exponent", Input: `[1e3]`},
+		{Name: "negative exponent", Input: `1E-3`},
+		{Name: "integer out of range", Input: `18446744073709551616`},
+		{Name: "invalid json", Input: `{"a":`},
+		{Name: "trailing data", Input: `{} {}`},
+		{Name: "empty input", Input: ``},
+		{Name: "duplicate key", Input: `{"a": 1, "a": 2}`},
+		{Name: "duplicate key with escapes", Input: `{"a": 1, "\u0061": 2}`},
+		{Name: "nested duplicate key", Input: `{"a": [{"b": {}, "c": 1, "b": 2}]}`},
+	}
+	for _, test := range cases {
+		t.Run(test.Name, func(t *testing.T) {
+			if _, err := cjson.Canonicalize([]byte(test.Input)); err == nil {
+				t.Error("expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	type role struct {
+		Type    string    `json:"_type"`
+		Version int       `json:"version"`
+		Expires time.Time `json:"expires"`
+		Custom  string    `json:"custom,omitempty"`
+		Raw     json.RawMessage
+	}
+	t.Run("should respect json tags and sort keys", func(t *testing.T) {
+		v := role{
+			Type:    "targets",
+			Version: 2,
+			Expires: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC),
+			Raw:     json.RawMessage(`{"z": "<>", "a": 1}`),
+		}
+		got, err := cjson.Marshal(v)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := `{"Raw":{"a":1,"z":"<>"},"_type":"targets","expires":"2030-01-01T00:00:00Z","version":2}`
+		if string(got) != want {
+			t.Errorf("expected %s, got %s", want, got)
+		}
+	})
+	t.Run("should fail on float values", func(t *testing.T) {
+		if _, err := cjson.Marshal(map[string]float64{"a": 0.5}); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+	t.Run("should fail on unsupported values", func(t *testing.T) {
+		if _, err := cjson.Marshal(make(chan int)); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+}
+
+func TestUnmarshal(t *testing.T) {
+	t.Run("should keep integer precision", func(t *testing.T) {
+		var v map[string]interface{}
+		if err := cjson.Unmarshal([]byte(`{"length":18446744073709551615}`), &v); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := v["length"].(json.Number).String(); got != "18446744073709551615" {
+			t.Errorf("expected 18446744073709551615, got %s", got)
+		}
+	})
+	t.Run("should round trip canonical form", func(t *testing.T) {
+		input := []byte(`{"a":[1,"b\"c"],"d":{"e":null}}`)
+		var v interface{}
+		if err := cjson.Unmarshal(input, &v); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		got, err := cjson.Marshal(v)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if string(got) != string(input) {
+			t.Errorf("expected %s, got %s", input, got)
+		}
+	})
+	t.Run("should reject duplicate keys", func(t *testing.T) {
+		var v struct {
+			Version int `json:"version"`
+		}
+		if err := cjson.Unmarshal([]byte(`{"version":1,"version":2}`), &v); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+	t.Run("should accept same keys in different objects", func(t *testing.T) {
+		var v interface{}
+		if err := cjson.Unmarshal([]byte(`{"a":{"a":1},"b":[{"a":1},{"a":2}]}`), &v); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+	t.Run("IsCanonical should detect non canonical form", func(t *testing.T) {
+		if cjson.IsCanonical([]byte(`{"b":1,"a":2}`)) {
+			t.Error("expected non canonical")
+		}
+		if cjson.IsCanonical([]byte(`{"a": 2}`)) {
+			t.Error("expected non canonical")
+		}
+	})
+}
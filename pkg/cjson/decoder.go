@@ -0,0 +1,88 @@
+package cjson
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+
+	"github.com/shuvava/go-ota-svc-common/apperrors"
+
+	"github.com/shuvava/ota-tuf-server/pkg/errcodes"
+)
+
+// Unmarshal parses JSON document and stores the result in the value pointed to by v.
+// Numbers decoded into interface{} values are kept as json.Number to not lose integer precision.
+// Documents with duplicate object keys are rejected, as the value of such key is ambiguous.
+func Unmarshal(data []byte, v interface{}) error {
+	if err := checkDuplicateKeys(data); err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(v); err != nil {
+		return apperrors.CreateError(errcodes.ErrorDataSerializationCanonicalJSON, "failed to unmarshal value", err)
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return apperrors.NewAppError(errcodes.ErrorDataSerializationCanonicalJSON, "unexpected data after top-level value")
+	}
+	return nil
+}
+
+// jsonObject tracks keys of the object being parsed
+type jsonObject struct {
+	keys      map[string]struct{}
+	expectKey bool
+}
+
+// checkDuplicateKeys walks tokens of JSON document and fails on the first object key repeated in its object,
+// malformed documents are skipped to be reported by the decoder
+func checkDuplicateKeys(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	// nil entries are arrays
+	var stack []*jsonObject
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil
+		}
+		var obj *jsonObject
+		if len(stack) > 0 {
+			obj = stack[len(stack)-1]
+		}
+		if obj != nil && obj.expectKey {
+			if key, ok := tok.(string); ok {
+				if _, dup := obj.keys[key]; dup {
+					return apperrors.NewAppError(errcodes.ErrorDataSerializationCanonicalJSON, "duplicate object key '"+key+"'")
+				}
+				obj.keys[key] = struct{}{}
+				obj.expectKey = false
+				continue
+			}
+		}
+		switch tok {
+		case json.Delim('{'):
+			if obj != nil {
+				obj.expectKey = true
+			}
+			stack = append(stack, &jsonObject{keys: make(map[string]struct{}), expectKey: true})
+		case json.Delim('['):
+			if obj != nil {
+				obj.expectKey = true
+			}
+			stack = append(stack, nil)
+		case json.Delim('}'), json.Delim(']'):
+			stack = stack[:len(stack)-1]
+		default:
+			if obj != nil {
+				obj.expectKey = true
+			}
+		}
+	}
+}
+
+// IsCanonical checks if JSON document is in the canonical form
+func IsCanonical(data []byte) bool {
+	canonical, err := Canonicalize(data)
+	return err == nil && bytes.Equal(canonical, data)
+}
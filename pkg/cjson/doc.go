@@ -0,0 +1,3 @@
+// Package cjson implements OLPC canonical JSON used to serialize signed portions of TUF metadata.
+// http://wiki.laptop.org/go/Canonical_JSON
+package cjson
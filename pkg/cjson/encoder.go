@@ -0,0 +1,117 @@
+package cjson
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/shuvava/go-ota-svc-common/apperrors"
+
+	"github.com/shuvava/ota-tuf-server/pkg/errcodes"
+)
+
+// Marshal returns the canonical JSON encoding of v.
+// v is serialized with encoding/json first, so json struct tags and json.Marshaler are respected.
+func Marshal(v interface{}) ([]byte, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, apperrors.CreateError(errcodes.ErrorDataSerializationCanonicalJSON, "failed to marshal value", err)
+	}
+	return Canonicalize(raw)
+}
+
+// Canonicalize returns the canonical JSON form of JSON document
+func Canonicalize(raw []byte) ([]byte, error) {
+	var val interface{}
+	if err := Unmarshal(raw, &val); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := encode(&buf, val); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// encode writes canonical JSON form of decoded JSON value into buf
+func encode(buf *bytes.Buffer, val interface{}) error {
+	switch v := val.(type) {
+	case nil:
+		buf.WriteString("null")
+	case bool:
+		buf.WriteString(strconv.FormatBool(v))
+	case json.Number:
+		return encodeNumber(buf, v)
+	case string:
+		encodeString(buf, v)
+	case []interface{}:
+		buf.WriteByte('[')
+		for i, item := range v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := encode(buf, item); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		// byte order of UTF-8 strings is the same as order of Unicode code points
+		sort.Strings(keys)
+		buf.WriteByte('{')
+		for i, key := range keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			encodeString(buf, key)
+			buf.WriteByte(':')
+			if err := encode(buf, v[key]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	default:
+		return apperrors.NewAppError(errcodes.ErrorDataSerializationCanonicalJSON,
+			fmt.Sprintf("unsupported type %T", val))
+	}
+	return nil
+}
+
+// encodeNumber writes integer number, canonical JSON does not allow floating point numbers
+func encodeNumber(buf *bytes.Buffer, num json.Number) error {
+	s := num.String()
+	if strings.ContainsAny(s, ".eE") {
+		return apperrors.NewAppError(errcodes.ErrorDataSerializationCanonicalJSON,
+			"floating point number "+s+" is not allowed")
+	}
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		buf.WriteString(strconv.FormatInt(i, 10))
+		return nil
+	}
+	if u, err := strconv.ParseUint(s, 10, 64); err == nil {
+		buf.WriteString(strconv.FormatUint(u, 10))
+		return nil
+	}
+	return apperrors.NewAppError(errcodes.ErrorDataSerializationCanonicalJSON,
+		"number "+s+" is out of range")
+}
+
+// encodeString writes string, only quotation mark and reverse solidus are escaped
+func encodeString(buf *bytes.Buffer, s string) {
+	buf.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c == '"' || c == '\\' {
+			buf.WriteByte('\\')
+		}
+		buf.WriteByte(c)
+	}
+	buf.WriteByte('"')
+}
@@ -0,0 +1,54 @@
+package data
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"fmt"
+
+	"github.com/shuvava/go-ota-svc-common/apperrors"
+
+	intData "github.com/shuvava/ota-tuf-server/internal/data"
+)
+
+// HashAlgorithm is a name of the hash function used in metadata
+type HashAlgorithm string
+
+const (
+	// HashAlgorithmSHA256 is SHA-256 hash function
+	HashAlgorithmSHA256 = HashAlgorithm("sha256")
+	// HashAlgorithmSHA512 is SHA-512 hash function
+	HashAlgorithmSHA512 = HashAlgorithm("sha512")
+)
+
+// hashSizes is the digest length of supported hash functions
+var hashSizes = map[HashAlgorithm]int{
+	HashAlgorithmSHA256: sha256.Size,
+	HashAlgorithmSHA512: sha512.Size,
+}
+
+// Hashes is a dictionary of hex-encoded digests of a file by hash function name
+type Hashes map[HashAlgorithm]intData.HexBytes
+
+// NewHashes returns SHA-256 and SHA-512 digests of content
+func NewHashes(content []byte) Hashes {
+	sum256 := sha256.Sum256(content)
+	sum512 := sha512.Sum512(content)
+	return Hashes{
+		HashAlgorithmSHA256: sum256[:],
+		HashAlgorithmSHA512: sum512[:],
+	}
+}
+
+// Validate checks that at least one digest is present and digests of known functions have correct length
+func (h Hashes) Validate() error {
+	if len(h) == 0 {
+		return apperrors.NewAppError(apperrors.ErrorDataValidation, "tuf: hashes are empty")
+	}
+	for alg, digest := range h {
+		if size, ok := hashSizes[alg]; ok && len(digest) != size {
+			return apperrors.NewAppError(apperrors.ErrorDataValidation,
+				fmt.Sprintf("tuf: %s digest length %d is invalid", alg, len(digest)))
+		}
+	}
+	return nil
+}
@@ -10,6 +10,8 @@ type Key struct {
 	Type KeyType `json:"keytype"`
 	// Value is key value
 	Value json.RawMessage `json:"keyval"`
+	// Scheme is the signature scheme of the key, it is used only by python-tuf encoding
+	Scheme SignatureMethod `json:"scheme,omitempty"`
 }
 
 // PrivateKey is a private key
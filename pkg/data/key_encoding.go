@@ -0,0 +1,27 @@
+package data
+
+// KeyEncoding is the wire format of public keys and signature methods in published TUF metadata
+type KeyEncoding string
+
+const (
+	// KeyEncodingTUF is the native encoding of the service, key values are hex encoded
+	KeyEncodingTUF = KeyEncoding("tuf")
+	// KeyEncodingAktualizr is the encoding of Aktualizr and ota-tuf, e.g. `RSA` keys with PEM public key
+	KeyEncodingAktualizr = KeyEncoding("aktualizr")
+	// KeyEncodingPythonTUF is the encoding of python-tuf (securesystemslib), keys have signature scheme
+	KeyEncodingPythonTUF = KeyEncoding("python-tuf")
+)
+
+// IsNative checks if keys are published in the native encoding of the service
+func (e KeyEncoding) IsNative() bool {
+	return e == "" || e == KeyEncodingTUF
+}
+
+// IsValid checks if the key encoding is supported
+func (e KeyEncoding) IsValid() bool {
+	switch e {
+	case "", KeyEncodingTUF, KeyEncodingAktualizr, KeyEncodingPythonTUF:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,42 @@
+package data
+
+import "time"
+
+// KeyGenStatus is the status of a key generation request
+type KeyGenStatus string
+
+const (
+	// KeyGenStatusRequested is the status of a request waiting for processing
+	KeyGenStatusRequested = KeyGenStatus("REQUESTED")
+	// KeyGenStatusGenerated is the status of a request which keys are generated and root is published
+	KeyGenStatusGenerated = KeyGenStatus("GENERATED")
+	// KeyGenStatusError is the status of a failed request, it can be retried
+	KeyGenStatusError = KeyGenStatus("ERROR")
+)
+
+// KeyGenRequest is a request to generate keys of a new repository
+type KeyGenRequest struct {
+	// Repo is the configuration of the repository keys
+	Repo Repo `json:"repo"`
+	// Status is the status of the request
+	Status KeyGenStatus `json:"status"`
+	// Attempts is the number of processing attempts
+	Attempts int `json:"attempts"`
+	// Error is the description of the last failure
+	Error string `json:"error,omitempty"`
+	// CreatedAt is the time the request was created
+	CreatedAt time.Time `json:"created_at"`
+	// UpdatedAt is the time the request was updated last time
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NewKeyGenRequest returns a new KeyGenRequest of the repository
+func NewKeyGenRequest(repo Repo) KeyGenRequest {
+	now := time.Now().UTC()
+	return KeyGenRequest{
+		Repo:      repo,
+		Status:    KeyGenStatusRequested,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
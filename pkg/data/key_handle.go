@@ -0,0 +1,22 @@
+package data
+
+// KeyBackend is the name of a backend holding private keys outside of the service database
+type KeyBackend string
+
+const (
+	// KeyBackendLocal is the backend of keys which private part is stored in the service database
+	KeyBackendLocal = KeyBackend("local")
+)
+
+// IsLocal checks if private keys of the backend are stored in the service database
+func (b KeyBackend) IsLocal() bool {
+	return b == "" || b == KeyBackendLocal
+}
+
+// KeyHandle is a reference to a private key held by a key backend
+type KeyHandle struct {
+	// Backend is the name of the backend holding the key
+	Backend KeyBackend `json:"backend"`
+	// Ref is the backend specific reference of the key
+	Ref string `json:"ref"`
+}
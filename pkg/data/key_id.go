@@ -1,28 +1,25 @@
 package data
 
 import (
+	"github.com/shuvava/go-ota-svc-common/apperrors"
 	"github.com/shuvava/go-ota-svc-common/data"
 )
 
-// KeyID is a type of TUF server key id
-type KeyID data.CorrelationID
+// keyIDLength is the length of hex encoded SHA-256 digest
+const keyIDLength = 64
 
-func (key KeyID) String() string {
-	return data.CorrelationID(key).String()
-}
+// KeyID is a TUF key id, the hex encoded SHA-256 digest of the canonical form of the public key
+// https://theupdateframework.github.io/specification/latest/#keyid
+type KeyID string
 
-// NewKeyID creates a new KeyID
-func NewKeyID(repoID RepoID, roleType RoleType) KeyID {
-	id := data.NewChildCorrelationID(data.CorrelationID(repoID), string(roleType))
-	key := KeyID(id)
-	return key
+func (key KeyID) String() string {
+	return string(key)
 }
 
-//	KeyIDFromString returns a new KeyID from a string
+// KeyIDFromString returns a new KeyID from a string
 func KeyIDFromString(s string) (KeyID, error) {
-	id, err := data.CorrelationIDFromString(s)
-	if err != nil {
-		return KeyID(id), err
+	if !data.ValidHex(keyIDLength, s) {
+		return "", apperrors.NewAppError(apperrors.ErrorDataValidation, "tuf: invalid key id '"+s+"'")
 	}
-	return KeyID(id), nil
+	return KeyID(s), nil
 }
@@ -8,6 +8,64 @@ const (
 	KeyTypeEd25519 = KeyType("ed25519")
 	// KeyTypeECDSA is the type of ECDSA keys with SHA2 and P256.
 	KeyTypeECDSA = KeyType("ecdsa")
-	// KeyTypeRSA is the type of RSA keys with RSASSA-PSS and SHA256.
+	// KeyTypeECDSAP384 is the type of ECDSA keys with SHA2 and P384.
+	KeyTypeECDSAP384 = KeyType("ecdsa-p384")
+	// KeyTypeECDSAP521 is the type of ECDSA keys with SHA2 and P521.
+	KeyTypeECDSAP521 = KeyType("ecdsa-p521")
+	// KeyTypeRSA is the type of 2048-bit RSA keys with RSASSA-PSS and SHA256.
 	KeyTypeRSA = KeyType("rsa")
+	// KeyTypeRSA3072 is the type of 3072-bit RSA keys with RSASSA-PSS and SHA256.
+	KeyTypeRSA3072 = KeyType("rsa-3072")
+	// KeyTypeRSA4096 is the type of 4096-bit RSA keys with RSASSA-PSS and SHA256.
+	KeyTypeRSA4096 = KeyType("rsa-4096")
 )
+
+// Family returns the base key type of keys with the same signature system, e.g. KeyTypeRSA for KeyTypeRSA4096
+func (t KeyType) Family() KeyType {
+	switch t {
+	case KeyTypeRSA, KeyTypeRSA3072, KeyTypeRSA4096:
+		return KeyTypeRSA
+	case KeyTypeECDSA, KeyTypeECDSAP384, KeyTypeECDSAP521:
+		return KeyTypeECDSA
+	}
+	return t
+}
+
+// RSABits returns size of RSA keys of the type, it returns 0 for non RSA keys
+func (t KeyType) RSABits() int {
+	switch t {
+	case KeyTypeRSA:
+		return 2048
+	case KeyTypeRSA3072:
+		return 3072
+	case KeyTypeRSA4096:
+		return 4096
+	}
+	return 0
+}
+
+// IsValid checks if the key type is supported
+func (t KeyType) IsValid() bool {
+	switch t.Family() {
+	case KeyTypeEd25519, KeyTypeECDSA, KeyTypeRSA:
+		return true
+	}
+	return false
+}
+
+// SignatureMethod returns the signature scheme used by keys of the type
+func (t KeyType) SignatureMethod() SignatureMethod {
+	switch t {
+	case KeyTypeEd25519:
+		return SignatureMethodEd25519
+	case KeyTypeECDSA:
+		return SignatureMethodECDSA
+	case KeyTypeECDSAP384:
+		return SignatureMethodECDSAP384
+	case KeyTypeECDSAP521:
+		return SignatureMethodECDSAP521
+	case KeyTypeRSA, KeyTypeRSA3072, KeyTypeRSA4096:
+		return SignatureMethodRSAPSS
+	}
+	return SignatureMethod(t)
+}
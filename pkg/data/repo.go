@@ -0,0 +1,100 @@
+package data
+
+import (
+	"fmt"
+
+	"github.com/shuvava/go-ota-svc-common/apperrors"
+)
+
+// RoleConfig is the keys configuration of a repository role
+type RoleConfig struct {
+	// Threshold is the number of signatures required for the role
+	Threshold int `json:"threshold"`
+	// KeyCount is the number of keys of the role
+	KeyCount int `json:"key_count"`
+	// KeyType is the type of keys generated for the role, overrides KeyType of the repository if not empty
+	KeyType KeyType `json:"key_type,omitempty"`
+	// Backend is the backend holding private keys of the role, keys are stored in the service database if empty
+	Backend KeyBackend `json:"backend,omitempty"`
+}
+
+// Repo is a TUF repository
+type Repo struct {
+	// RepoID is the id of the repo
+	RepoID RepoID `json:"repo_id"`
+	// KeyType is the type of keys generated for the repo
+	KeyType KeyType `json:"key_type"`
+	// Roles is the keys configuration of data.TopLevelRoles
+	Roles map[RoleType]RoleConfig `json:"roles"`
+	// KeyEncoding is the encoding of keys in published metadata, native encoding is used if empty
+	KeyEncoding KeyEncoding `json:"key_encoding,omitempty"`
+}
+
+// NewRepo returns a new Repo with default configuration of data.TopLevelRoles
+func NewRepo(repoID RepoID, keyType KeyType) Repo {
+	roles := make(map[RoleType]RoleConfig, len(TopLevelRoles))
+	for role := range TopLevelRoles {
+		roles[role] = RoleConfig{Threshold: 1, KeyCount: 1}
+	}
+	return Repo{
+		RepoID:  repoID,
+		KeyType: keyType,
+		Roles:   roles,
+	}
+}
+
+// Threshold returns the number of signatures required for the role
+func (r Repo) Threshold(role RoleType) int {
+	if cfg, ok := r.Roles[role]; ok && cfg.Threshold > 0 {
+		return cfg.Threshold
+	}
+	return 1
+}
+
+// RoleKeyType returns the type of keys generated for the role
+func (r Repo) RoleKeyType(role RoleType) KeyType {
+	if cfg, ok := r.Roles[role]; ok && cfg.KeyType != "" {
+		return cfg.KeyType
+	}
+	return r.KeyType
+}
+
+// Validate checks keys configuration of data.TopLevelRoles
+func (r Repo) Validate() error {
+	if !r.KeyType.IsValid() {
+		return apperrors.NewAppError(apperrors.ErrorDataValidation,
+			fmt.Sprintf("tuf: unsupported key type '%s'", r.KeyType))
+	}
+	if !r.KeyEncoding.IsValid() {
+		return apperrors.NewAppError(apperrors.ErrorDataValidation,
+			fmt.Sprintf("tuf: unsupported key encoding '%s'", r.KeyEncoding))
+	}
+	for role := range TopLevelRoles {
+		if err := r.Roles[role].Validate(); err != nil {
+			return apperrors.NewAppError(apperrors.ErrorDataValidation,
+				fmt.Sprintf("tuf: role '%s' is invalid: %v", role, err))
+		}
+	}
+	return nil
+}
+
+// Validate checks the key type and the threshold of the role configuration against the number of role keys
+func (c RoleConfig) Validate() error {
+	if c.KeyType != "" && !c.KeyType.IsValid() {
+		return apperrors.NewAppError(apperrors.ErrorDataValidation,
+			fmt.Sprintf("tuf: unsupported key type '%s'", c.KeyType))
+	}
+	if c.KeyCount < 1 {
+		return apperrors.NewAppError(apperrors.ErrorDataValidation,
+			fmt.Sprintf("tuf: number of keys %d must be positive", c.KeyCount))
+	}
+	if c.Threshold < 1 {
+		return apperrors.NewAppError(apperrors.ErrorDataValidation,
+			fmt.Sprintf("tuf: threshold %d must be positive", c.Threshold))
+	}
+	if c.Threshold > c.KeyCount {
+		return apperrors.NewAppError(apperrors.ErrorDataValidation,
+			fmt.Sprintf("tuf: threshold %d exceeds the number of keys %d", c.Threshold, c.KeyCount))
+	}
+	return nil
+}
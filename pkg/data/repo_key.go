@@ -8,6 +8,12 @@ type RepoKey struct {
 	Role RoleType `json:"role"`
 	// KeyID is the id of the key
 	KeyID KeyID `json:"key_id"`
+	// InternalID is the storage id of the key, it is assigned on creation and kept by updates
+	InternalID string `json:"-"`
 	// Key is the public/private key
 	Key Key `json:"key"`
+	// Handle is the reference to the private key held by a key backend, Key contains only public part in that case
+	Handle *KeyHandle `json:"handle,omitempty"`
+	// Exported indicates that private part of the key was exported for offline escrow
+	Exported bool `json:"exported,omitempty"`
 }
@@ -0,0 +1,99 @@
+package data_test
+
+import (
+	"testing"
+
+	"github.com/shuvava/ota-tuf-server/pkg/data"
+)
+
+func TestRoleConfigValidate(t *testing.T) {
+	cases := []struct {
+		Name      string
+		Threshold int
+		KeyCount  int
+		KeyType   data.KeyType
+		Valid     bool
+	}{
+		{Name: "threshold equal to key count is valid", Threshold: 2, KeyCount: 2, Valid: true},
+		{Name: "threshold less than key count is valid", Threshold: 1, KeyCount: 3, Valid: true},
+		{Name: "threshold exceeding key count is invalid", Threshold: 2, KeyCount: 1, Valid: false},
+		{Name: "zero threshold is invalid", Threshold: 0, KeyCount: 1, Valid: false},
+		{Name: "negative threshold is invalid", Threshold: -1, KeyCount: 1, Valid: false},
+		{Name: "zero key count is invalid", Threshold: 1, KeyCount: 0, Valid: false},
+		{Name: "parameterized key type is valid", Threshold: 1, KeyCount: 1, KeyType: data.KeyTypeRSA4096, Valid: true},
+		{Name: "unknown key type is invalid", Threshold: 1, KeyCount: 1, KeyType: "dsa", Valid: false},
+	}
+	for _, test := range cases {
+		t.Run(test.Name, func(t *testing.T) {
+			err := data.RoleConfig{Threshold: test.Threshold, KeyCount: test.KeyCount, KeyType: test.KeyType}.Validate()
+			if test.Valid && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if !test.Valid && err == nil {
+				t.Error("expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestRepoThreshold(t *testing.T) {
+	t.Run("new repo should have threshold 1 for all top-level roles", func(t *testing.T) {
+		repo := data.NewRepo(data.NewRepoID(), data.KeyTypeEd25519)
+		for role := range data.TopLevelRoles {
+			if got := repo.Threshold(role); got != 1 {
+				t.Errorf("expected threshold 1 for %s, got %d", role, got)
+			}
+		}
+	})
+	t.Run("should return configured threshold", func(t *testing.T) {
+		repo := data.NewRepo(data.NewRepoID(), data.KeyTypeEd25519)
+		repo.Roles[data.RoleTypeRoot] = data.RoleConfig{Threshold: 2, KeyCount: 2}
+		if got := repo.Threshold(data.RoleTypeRoot); got != 2 {
+			t.Errorf("expected threshold 2, got %d", got)
+		}
+	})
+}
+
+func TestRepoRoleKeyType(t *testing.T) {
+	t.Run("should return repository key type by default", func(t *testing.T) {
+		repo := data.NewRepo(data.NewRepoID(), data.KeyTypeEd25519)
+		if got := repo.RoleKeyType(data.RoleTypeRoot); got != data.KeyTypeEd25519 {
+			t.Errorf("expected key type %s, got %s", data.KeyTypeEd25519, got)
+		}
+	})
+	t.Run("should return key type of the role", func(t *testing.T) {
+		repo := data.NewRepo(data.NewRepoID(), data.KeyTypeEd25519)
+		repo.Roles[data.RoleTypeRoot] = data.RoleConfig{Threshold: 1, KeyCount: 1, KeyType: data.KeyTypeRSA4096}
+		if got := repo.RoleKeyType(data.RoleTypeRoot); got != data.KeyTypeRSA4096 {
+			t.Errorf("expected key type %s, got %s", data.KeyTypeRSA4096, got)
+		}
+		if err := repo.Validate(); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+	t.Run("repository with unknown key type should be invalid", func(t *testing.T) {
+		if err := data.NewRepo(data.NewRepoID(), "dsa").Validate(); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+}
+
+func TestRepoKeyEncoding(t *testing.T) {
+	encodings := []data.KeyEncoding{"", data.KeyEncodingTUF, data.KeyEncodingAktualizr, data.KeyEncodingPythonTUF}
+	for _, enc := range encodings {
+		t.Run("repository with key encoding '"+string(enc)+"' should be valid", func(t *testing.T) {
+			repo := data.NewRepo(data.NewRepoID(), data.KeyTypeRSA)
+			repo.KeyEncoding = enc
+			if err := repo.Validate(); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+	t.Run("repository with unknown key encoding should be invalid", func(t *testing.T) {
+		repo := data.NewRepo(data.NewRepoID(), data.KeyTypeRSA)
+		repo.KeyEncoding = "pem"
+		if err := repo.Validate(); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+}
@@ -0,0 +1,19 @@
+package data
+
+import "time"
+
+// ResignRecord is the outcome of background re-signing of expiring role metadata of a repository
+type ResignRecord struct {
+	// RepoID is the id of the repo
+	RepoID RepoID `json:"repo_id"`
+	// Role is the role which metadata was re-signed
+	Role RoleType `json:"role"`
+	// Version is the published version of the role metadata, it is 0 if re-signing failed
+	Version int `json:"version,omitempty"`
+	// Owner is the id of the server instance which re-signed the metadata
+	Owner string `json:"owner"`
+	// Error is the description of the failure
+	Error string `json:"error,omitempty"`
+	// CreatedAt is the time the metadata was re-signed
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,68 @@
+package data
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shuvava/go-ota-svc-common/apperrors"
+)
+
+const (
+	// SpecVersion is the version of TUF specification implemented by the server
+	SpecVersion = "1.0.0"
+)
+
+// RoleHeader contains fields common for signed portion of all metadata files
+// https://theupdateframework.github.io/specification/latest/#file-formats-general-principles
+type RoleHeader struct {
+	// Type is the type of metadata, equal to the role name
+	Type RoleType `json:"_type"`
+	// SpecVersion is the version of TUF specification
+	SpecVersion string `json:"spec_version"`
+	// Version is the version of the metadata
+	Version int `json:"version"`
+	// Expires is the time the metadata expires
+	Expires time.Time `json:"expires"`
+}
+
+// NewRoleHeader returns a new RoleHeader of the role with default expiration time
+func NewRoleHeader(role RoleType, version int) RoleHeader {
+	return RoleHeader{
+		Type:        role,
+		SpecVersion: SpecVersion,
+		Version:     version,
+		Expires:     DefaultExpires(role),
+	}
+}
+
+// IsExpired checks if metadata is expired at the provided time
+func (h RoleHeader) IsExpired(now time.Time) bool {
+	return !h.Expires.After(now)
+}
+
+// Validate checks common metadata fields, role is the expected type of the metadata
+func (h RoleHeader) Validate(role RoleType) error {
+	if h.Type != role {
+		return apperrors.NewAppError(apperrors.ErrorDataValidation,
+			fmt.Sprintf("tuf: expected metadata type '%s', got '%s'", role, h.Type))
+	}
+	if major(h.SpecVersion) != major(SpecVersion) {
+		return apperrors.NewAppError(apperrors.ErrorDataValidation,
+			fmt.Sprintf("tuf: unsupported spec version '%s'", h.SpecVersion))
+	}
+	if h.Version < 1 {
+		return apperrors.NewAppError(apperrors.ErrorDataValidation,
+			fmt.Sprintf("tuf: %s version %d must be positive", role, h.Version))
+	}
+	if h.Expires.IsZero() {
+		return apperrors.NewAppError(apperrors.ErrorDataValidation,
+			fmt.Sprintf("tuf: %s expiration time is not set", role))
+	}
+	return nil
+}
+
+// major returns major part of semantic version
+func major(version string) string {
+	return strings.SplitN(version, ".", 2)[0]
+}
@@ -1,6 +1,8 @@
 package data
 
 import (
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/shuvava/go-ota-svc-common/apperrors"
@@ -33,8 +35,32 @@ var TopLevelRoles = map[RoleType]struct{}{
 	RoleTypeTimestamp: {},
 }
 
+var (
+	expiresMu sync.RWMutex
+	// expiresPeriods is the configured expiration periods of roles
+	expiresPeriods = make(map[RoleType]time.Duration)
+)
+
+// SetExpiresPeriod sets the period DefaultExpires adds to the current time for the role,
+// not positive period restores the default one
+func SetExpiresPeriod(role RoleType, period time.Duration) {
+	expiresMu.Lock()
+	defer expiresMu.Unlock()
+	if period <= 0 {
+		delete(expiresPeriods, role)
+		return
+	}
+	expiresPeriods[role] = period
+}
+
 // DefaultExpires returns the default expiration time for a role
 func DefaultExpires(role RoleType) time.Time {
+	expiresMu.RLock()
+	period, ok := expiresPeriods[role]
+	expiresMu.RUnlock()
+	if ok {
+		return time.Now().Add(period).UTC().Round(time.Second)
+	}
 	var t time.Time
 	switch role {
 	case RoleTypeRoot:
@@ -49,6 +75,19 @@ func DefaultExpires(role RoleType) time.Time {
 	return t.UTC().Round(time.Second)
 }
 
+// MetaFileName returns the name of metadata file of the role
+func (r RoleType) MetaFileName() string {
+	return string(r) + ".json"
+}
+
+// RoleTypeFromMetaFileName returns the role of metadata file name, e.g. targets.json
+func RoleTypeFromMetaFileName(name string) (RoleType, error) {
+	if !strings.HasSuffix(name, ".json") {
+		return "", apperrors.NewAppError(apperrors.ErrorDataValidation, "tuf: invalid metadata file name '"+name+"'")
+	}
+	return NewRoleType(strings.TrimSuffix(name, ".json"))
+}
+
 // NewRoleType returns a new RoleType from a string
 func NewRoleType(name string) (RoleType, error) {
 	role := RoleType(name)
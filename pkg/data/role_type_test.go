@@ -0,0 +1,45 @@
+package data_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shuvava/ota-tuf-server/pkg/data"
+)
+
+func TestDefaultExpires(t *testing.T) {
+	t.Run("should use configured period of the role", func(t *testing.T) {
+		data.SetExpiresPeriod(data.RoleTypeTimestamp, time.Hour)
+		defer data.SetExpiresPeriod(data.RoleTypeTimestamp, 0)
+		got := data.DefaultExpires(data.RoleTypeTimestamp)
+		if d := time.Until(got); d < 59*time.Minute || d > 61*time.Minute {
+			t.Errorf("expected expiration in an hour, got %s", got)
+		}
+	})
+	t.Run("should restore default period", func(t *testing.T) {
+		data.SetExpiresPeriod(data.RoleTypeTimestamp, time.Hour)
+		data.SetExpiresPeriod(data.RoleTypeTimestamp, 0)
+		got := data.DefaultExpires(data.RoleTypeTimestamp)
+		if d := time.Until(got); d < 23*time.Hour {
+			t.Errorf("expected expiration in a day, got %s", got)
+		}
+	})
+}
+
+func TestRoleTypeFromMetaFileName(t *testing.T) {
+	t.Run("should return role of metadata file", func(t *testing.T) {
+		for role := range data.TopLevelRoles {
+			got, err := data.RoleTypeFromMetaFileName(role.MetaFileName())
+			if err != nil || got != role {
+				t.Errorf("expected %s, got %s (%v)", role, got, err)
+			}
+		}
+	})
+	t.Run("should reject unknown file", func(t *testing.T) {
+		for _, name := range []string{"targets", "mirrors.json", "1.root.json"} {
+			if _, err := data.RoleTypeFromMetaFileName(name); err == nil {
+				t.Errorf("expected error for %s, got nil", name)
+			}
+		}
+	})
+}
@@ -0,0 +1,67 @@
+package data
+
+import (
+	"fmt"
+
+	"github.com/shuvava/go-ota-svc-common/apperrors"
+)
+
+// RoleKeys is the list of keys trusted for the role and the number of signatures required
+type RoleKeys struct {
+	// KeyIDs is the list of keys trusted for the role
+	KeyIDs []KeyID `json:"keyids"`
+	// Threshold is the number of signatures required for the role
+	Threshold int `json:"threshold"`
+}
+
+// RootRole is the signed portion of root.json
+// https://theupdateframework.github.io/specification/latest/#file-formats-root
+type RootRole struct {
+	RoleHeader
+	// ConsistentSnapshot indicates if the repository supports consistent snapshots
+	ConsistentSnapshot bool `json:"consistent_snapshot"`
+	// Keys is the list of public keys referenced by Roles
+	Keys map[KeyID]Key `json:"keys"`
+	// Roles is the list of keys trusted for each top-level role
+	Roles map[RoleType]*RoleKeys `json:"roles"`
+}
+
+// Validate checks that root metadata is well-formed
+func (r *RootRole) Validate() error {
+	if err := r.RoleHeader.Validate(RoleTypeRoot); err != nil {
+		return err
+	}
+	for role := range TopLevelRoles {
+		roleKeys, ok := r.Roles[role]
+		if !ok || roleKeys == nil {
+			return apperrors.NewAppError(apperrors.ErrorDataValidation,
+				fmt.Sprintf("tuf: root does not define role '%s'", role))
+		}
+		if err := roleKeys.Validate(r.Keys); err != nil {
+			return apperrors.NewAppError(apperrors.ErrorDataValidation,
+				fmt.Sprintf("tuf: role '%s' is invalid: %v", role, err))
+		}
+	}
+	return nil
+}
+
+// Validate checks that threshold is reachable and all key ids are defined in keys
+func (r *RoleKeys) Validate(keys map[KeyID]Key) error {
+	if r.Threshold < 1 {
+		return apperrors.NewAppError(apperrors.ErrorDataValidation,
+			fmt.Sprintf("tuf: threshold %d must be positive", r.Threshold))
+	}
+	unique := make(map[KeyID]struct{}, len(r.KeyIDs))
+	for _, keyID := range r.KeyIDs {
+		if _, ok := keys[keyID]; !ok {
+			return apperrors.NewAppError(apperrors.ErrorDataValidation,
+				fmt.Sprintf("tuf: key '%s' is not defined", keyID))
+		}
+		unique[keyID] = struct{}{}
+	}
+	if r.Threshold > len(unique) {
+		return apperrors.NewAppError(apperrors.ErrorDataValidation,
+			fmt.Sprintf("tuf: threshold %d exceeds the number of keys %d", r.Threshold, len(unique)))
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package data_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/shuvava/ota-tuf-server/pkg/data"
+)
+
+const testKeyID = data.KeyID("1a2b3c4d5e6f1a2b3c4d5e6f1a2b3c4d5e6f1a2b3c4d5e6f1a2b3c4d5e6f1a2b")
+
+func newTestRoot() *data.RootRole {
+	root := &data.RootRole{
+		RoleHeader: data.NewRoleHeader(data.RoleTypeRoot, 1),
+		Keys: map[data.KeyID]data.Key{
+			testKeyID: {Type: data.KeyTypeEd25519, Value: json.RawMessage(`{"public":"abcd"}`)},
+		},
+		Roles: map[data.RoleType]*data.RoleKeys{},
+	}
+	for role := range data.TopLevelRoles {
+		root.Roles[role] = &data.RoleKeys{KeyIDs: []data.KeyID{testKeyID}, Threshold: 1}
+	}
+	return root
+}
+
+func TestRootRole(t *testing.T) {
+	t.Run("should round trip JSON", func(t *testing.T) {
+		root := newTestRoot()
+		raw, err := json.Marshal(root)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var fields map[string]interface{}
+		if err = json.Unmarshal(raw, &fields); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		for _, name := range []string{"_type", "spec_version", "version", "expires", "consistent_snapshot", "keys", "roles"} {
+			if _, ok := fields[name]; !ok {
+				t.Errorf("field %s is missing", name)
+			}
+		}
+		var got data.RootRole
+		if err = json.Unmarshal(raw, &got); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err = got.Validate(); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if !got.Expires.Equal(root.Expires) || got.Version != root.Version {
+			t.Errorf("expected %v, got %v", root.RoleHeader, got.RoleHeader)
+		}
+	})
+	t.Run("should fail validation on wrong type", func(t *testing.T) {
+		root := newTestRoot()
+		root.Type = data.RoleTypeTargets
+		if err := root.Validate(); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+	t.Run("should fail validation on unsupported spec version", func(t *testing.T) {
+		root := newTestRoot()
+		root.SpecVersion = "2.0.0"
+		if err := root.Validate(); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+	t.Run("should fail validation on missing role", func(t *testing.T) {
+		root := newTestRoot()
+		delete(root.Roles, data.RoleTypeTimestamp)
+		if err := root.Validate(); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+	t.Run("should fail validation on unknown key", func(t *testing.T) {
+		root := newTestRoot()
+		root.Roles[data.RoleTypeRoot].KeyIDs = []data.KeyID{"unknown"}
+		if err := root.Validate(); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+	t.Run("should fail validation on unreachable threshold", func(t *testing.T) {
+		root := newTestRoot()
+		root.Roles[data.RoleTypeRoot].KeyIDs = []data.KeyID{testKeyID, testKeyID}
+		root.Roles[data.RoleTypeRoot].Threshold = 2
+		if err := root.Validate(); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+	t.Run("should detect expiration", func(t *testing.T) {
+		root := newTestRoot()
+		if root.IsExpired(time.Now()) {
+			t.Error("new root should not be expired")
+		}
+		if !root.IsExpired(root.Expires) {
+			t.Error("root should be expired at expiration time")
+		}
+	})
+}
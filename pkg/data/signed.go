@@ -0,0 +1,61 @@
+package data
+
+import (
+	"encoding/json"
+
+	"github.com/shuvava/go-ota-svc-common/apperrors"
+
+	intData "github.com/shuvava/ota-tuf-server/internal/data"
+)
+
+// SignatureMethod is a signature scheme used to sign TUF metadata
+type SignatureMethod string
+
+const (
+	// SignatureMethodEd25519 is the signature scheme of Ed25519 keys
+	SignatureMethodEd25519 = SignatureMethod("ed25519")
+	// SignatureMethodECDSA is the signature scheme of ECDSA keys with SHA2 and P256
+	SignatureMethodECDSA = SignatureMethod("ecdsa-sha2-nistp256")
+	// SignatureMethodECDSAP384 is the signature scheme of ECDSA keys with SHA2 and P384
+	SignatureMethodECDSAP384 = SignatureMethod("ecdsa-sha2-nistp384")
+	// SignatureMethodECDSAP521 is the signature scheme of ECDSA keys with SHA2 and P521
+	SignatureMethodECDSAP521 = SignatureMethod("ecdsa-sha2-nistp521")
+	// SignatureMethodRSAPSS is the signature scheme of RSA keys with RSASSA-PSS and SHA256
+	SignatureMethodRSAPSS = SignatureMethod("rsassa-pss-sha256")
+)
+
+// Signature is a signature of TUF metadata made by one key
+// https://theupdateframework.github.io/specification/latest/#file-formats-general-principles
+type Signature struct {
+	// KeyID is the identifier of the key signing the metadata
+	KeyID KeyID `json:"keyid"`
+	// Method is the signature scheme used by the key
+	Method SignatureMethod `json:"method"`
+	// Signature is a hex-encoded signature of the canonical form of the metadata
+	Signature intData.HexBytes `json:"sig"`
+}
+
+// SignedPayload is the envelope of signed TUF metadata
+type SignedPayload struct {
+	// Signatures is the list of signatures of Signed
+	Signatures []Signature `json:"signatures"`
+	// Signed is the signed portion of the metadata
+	Signed json.RawMessage `json:"signed"`
+}
+
+// FileContent returns content of the metadata file, snapshot and timestamp metadata describe files by it
+func (p *SignedPayload) FileContent() ([]byte, error) {
+	content, err := json.Marshal(p)
+	if err != nil {
+		return nil, apperrors.CreateError(apperrors.ErrorDataSerialization, "tuf: failed to marshal metadata", err)
+	}
+	return content, nil
+}
+
+// DecodeSigned unmarshals signed portion of the metadata into v
+func (p *SignedPayload) DecodeSigned(v interface{}) error {
+	if err := json.Unmarshal(p.Signed, v); err != nil {
+		return apperrors.CreateError(apperrors.ErrorDataSerialization, "tuf: failed to unmarshal signed metadata", err)
+	}
+	return nil
+}
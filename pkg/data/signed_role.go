@@ -0,0 +1,17 @@
+package data
+
+import "time"
+
+// SignedRole is a published version of signed role metadata of a repository
+type SignedRole struct {
+	// RepoID is the id of the repo
+	RepoID RepoID `json:"repo_id"`
+	// Role is the role of the metadata
+	Role RoleType `json:"role"`
+	// Version is the version of the metadata
+	Version int `json:"version"`
+	// ExpiresAt is the time the metadata expires
+	ExpiresAt time.Time `json:"expires_at"`
+	// Content is the signed metadata
+	Content SignedPayload `json:"content"`
+}
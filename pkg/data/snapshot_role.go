@@ -0,0 +1,76 @@
+package data
+
+import (
+	"fmt"
+
+	"github.com/shuvava/go-ota-svc-common/apperrors"
+)
+
+// MetaFileMeta describes a metadata file listed in snapshot and timestamp metadata
+// https://theupdateframework.github.io/specification/latest/#metafiles
+type MetaFileMeta struct {
+	// Version is the version of the metadata file
+	Version int `json:"version"`
+	// Length is the size of the metadata file in bytes
+	Length int64 `json:"length,omitempty"`
+	// Hashes is the digests of the metadata file
+	Hashes Hashes `json:"hashes,omitempty"`
+}
+
+// NewMetaFileMeta returns description of the metadata file of provided version and content
+func NewMetaFileMeta(version int, content []byte) MetaFileMeta {
+	return MetaFileMeta{
+		Version: version,
+		Length:  int64(len(content)),
+		Hashes:  NewHashes(content),
+	}
+}
+
+// SnapshotRole is the signed portion of snapshot.json
+// https://theupdateframework.github.io/specification/latest/#file-formats-snapshot
+type SnapshotRole struct {
+	RoleHeader
+	// Meta is the list of targets metadata files by file name
+	Meta map[string]MetaFileMeta `json:"meta"`
+}
+
+// NewSnapshotRole returns a new SnapshotRole of provided version listing targets metadata file
+func NewSnapshotRole(version int, targets MetaFileMeta) *SnapshotRole {
+	return &SnapshotRole{
+		RoleHeader: NewRoleHeader(RoleTypeSnapshot, version),
+		Meta:       map[string]MetaFileMeta{RoleTypeTargets.MetaFileName(): targets},
+	}
+}
+
+// Validate checks that snapshot metadata is well-formed
+func (r *SnapshotRole) Validate() error {
+	if err := r.RoleHeader.Validate(RoleTypeSnapshot); err != nil {
+		return err
+	}
+	if _, ok := r.Meta[RoleTypeTargets.MetaFileName()]; !ok {
+		return apperrors.NewAppError(apperrors.ErrorDataValidation, "tuf: snapshot does not list targets metadata")
+	}
+	for name, meta := range r.Meta {
+		if err := meta.Validate(); err != nil {
+			return apperrors.NewAppError(apperrors.ErrorDataValidation,
+				fmt.Sprintf("tuf: meta '%s' is invalid: %v", name, err))
+		}
+	}
+	return nil
+}
+
+// Validate checks that metadata file description is well-formed
+func (m MetaFileMeta) Validate() error {
+	if m.Version < 1 {
+		return apperrors.NewAppError(apperrors.ErrorDataValidation,
+			fmt.Sprintf("tuf: version %d must be positive", m.Version))
+	}
+	if m.Length < 0 {
+		return apperrors.NewAppError(apperrors.ErrorDataValidation,
+			fmt.Sprintf("tuf: length %d must not be negative", m.Length))
+	}
+	if m.Hashes != nil {
+		return m.Hashes.Validate()
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package data_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/shuvava/ota-tuf-server/pkg/data"
+)
+
+func TestSnapshotRole(t *testing.T) {
+	t.Run("should round trip JSON", func(t *testing.T) {
+		raw := []byte(`{"_type":"snapshot","spec_version":"1.0.0","version":2,"expires":"2030-01-01T00:00:00Z","meta":{"targets.json":{"version":4}}}`)
+		var role data.SnapshotRole
+		if err := json.Unmarshal(raw, &role); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := role.Validate(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		out, err := json.Marshal(role)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if string(out) != string(raw) {
+			t.Errorf("expected %s, got %s", raw, out)
+		}
+	})
+	t.Run("should fail validation without targets meta", func(t *testing.T) {
+		role := data.SnapshotRole{
+			RoleHeader: data.NewRoleHeader(data.RoleTypeSnapshot, 1),
+			Meta:       map[string]data.MetaFileMeta{},
+		}
+		if err := role.Validate(); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+}
+
+func TestTimestampRole(t *testing.T) {
+	t.Run("should be valid", func(t *testing.T) {
+		role := data.TimestampRole{
+			RoleHeader: data.NewRoleHeader(data.RoleTypeTimestamp, 1),
+			Meta: map[string]data.MetaFileMeta{
+				"snapshot.json": {Version: 1, Length: 10, Hashes: data.NewHashes([]byte("snapshot"))},
+			},
+		}
+		if err := role.Validate(); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+	t.Run("should fail validation with extra meta", func(t *testing.T) {
+		role := data.TimestampRole{
+			RoleHeader: data.NewRoleHeader(data.RoleTypeTimestamp, 1),
+			Meta: map[string]data.MetaFileMeta{
+				"snapshot.json": {Version: 1},
+				"targets.json":  {Version: 1},
+			},
+		}
+		if err := role.Validate(); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+	t.Run("should fail validation with zero version", func(t *testing.T) {
+		role := data.TimestampRole{
+			RoleHeader: data.NewRoleHeader(data.RoleTypeTimestamp, 0),
+			Meta:       map[string]data.MetaFileMeta{"snapshot.json": {Version: 1}},
+		}
+		if err := role.Validate(); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+}
@@ -0,0 +1,133 @@
+package data
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/shuvava/go-ota-svc-common/apperrors"
+)
+
+// TargetFileMeta describes a target file
+// https://theupdateframework.github.io/specification/latest/#targets
+type TargetFileMeta struct {
+	// Length is the size of the target file in bytes
+	Length int64 `json:"length"`
+	// Hashes is the digests of the target file
+	Hashes Hashes `json:"hashes"`
+	// Custom is opaque application specific data
+	Custom json.RawMessage `json:"custom,omitempty"`
+}
+
+// DelegatedRole is a role the targets role delegates trust to
+// https://theupdateframework.github.io/specification/latest/#delegations
+type DelegatedRole struct {
+	// Name is the name of the delegated role
+	Name string `json:"name"`
+	RoleKeys
+	// Terminating indicates if subsequent delegations should be considered
+	Terminating bool `json:"terminating"`
+	// Paths is the list of target path patterns the role is trusted for
+	Paths []string `json:"paths,omitempty"`
+	// PathHashPrefixes is the list of target path digest prefixes the role is trusted for
+	PathHashPrefixes []string `json:"path_hash_prefixes,omitempty"`
+}
+
+// Delegations is the list of roles the targets role delegates trust to
+type Delegations struct {
+	// Keys is the list of public keys referenced by Roles
+	Keys map[KeyID]Key `json:"keys"`
+	// Roles is the ordered list of delegated roles
+	Roles []DelegatedRole `json:"roles"`
+}
+
+// TargetsRole is the signed portion of targets.json and delegated targets metadata
+// https://theupdateframework.github.io/specification/latest/#file-formats-targets
+type TargetsRole struct {
+	RoleHeader
+	// Targets is the list of target files by target path
+	Targets map[string]TargetFileMeta `json:"targets"`
+	// Delegations is the optional list of delegated roles
+	Delegations *Delegations `json:"delegations,omitempty"`
+}
+
+// UnsignedTargets is the signed portion of the latest version of targets.json taken for offline signing,
+// Checksum identifies the version, it is required to upload offline signed metadata replacing it
+type UnsignedTargets struct {
+	Signed   TargetsRole `json:"signed"`
+	Checksum string      `json:"checksum"`
+}
+
+// NewTargetsRole returns a new empty TargetsRole of provided version
+func NewTargetsRole(version int) *TargetsRole {
+	return &TargetsRole{
+		RoleHeader: NewRoleHeader(RoleTypeTargets, version),
+		Targets:    map[string]TargetFileMeta{},
+	}
+}
+
+// Validate checks that targets metadata is well-formed
+func (r *TargetsRole) Validate() error {
+	if err := r.RoleHeader.Validate(RoleTypeTargets); err != nil {
+		return err
+	}
+	for path, meta := range r.Targets {
+		if err := ValidateTargetPath(path); err != nil {
+			return err
+		}
+		if err := meta.Validate(); err != nil {
+			return apperrors.NewAppError(apperrors.ErrorDataValidation,
+				fmt.Sprintf("tuf: target '%s' is invalid: %v", path, err))
+		}
+	}
+	if r.Delegations != nil {
+		return r.Delegations.Validate()
+	}
+	return nil
+}
+
+// Validate checks that target file description is well-formed
+func (m TargetFileMeta) Validate() error {
+	if m.Length < 0 {
+		return apperrors.NewAppError(apperrors.ErrorDataValidation,
+			fmt.Sprintf("tuf: length %d must not be negative", m.Length))
+	}
+	return m.Hashes.Validate()
+}
+
+// Validate checks that delegated roles are well-formed and reference defined keys
+func (d *Delegations) Validate() error {
+	names := make(map[string]struct{}, len(d.Roles))
+	for _, role := range d.Roles {
+		if role.Name == "" {
+			return apperrors.NewAppError(apperrors.ErrorDataValidation, "tuf: delegated role name is empty")
+		}
+		if _, ok := TopLevelRoles[RoleType(role.Name)]; ok {
+			return apperrors.NewAppError(apperrors.ErrorDataValidation,
+				fmt.Sprintf("tuf: delegated role name '%s' is reserved", role.Name))
+		}
+		if _, ok := names[role.Name]; ok {
+			return apperrors.NewAppError(apperrors.ErrorDataValidation,
+				fmt.Sprintf("tuf: delegated role '%s' is duplicated", role.Name))
+		}
+		names[role.Name] = struct{}{}
+		if len(role.Paths) > 0 && len(role.PathHashPrefixes) > 0 {
+			return apperrors.NewAppError(apperrors.ErrorDataValidation,
+				fmt.Sprintf("tuf: delegated role '%s' defines both paths and path_hash_prefixes", role.Name))
+		}
+		if err := role.RoleKeys.Validate(d.Keys); err != nil {
+			return apperrors.NewAppError(apperrors.ErrorDataValidation,
+				fmt.Sprintf("tuf: delegated role '%s' is invalid: %v", role.Name, err))
+		}
+	}
+	return nil
+}
+
+// ValidateTargetPath checks that target path is a relative path
+func ValidateTargetPath(path string) error {
+	if path == "" || strings.HasPrefix(path, "/") {
+		return apperrors.NewAppError(apperrors.ErrorDataValidation,
+			fmt.Sprintf("tuf: target path '%s' is invalid", path))
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package data_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/shuvava/ota-tuf-server/pkg/data"
+)
+
+func TestTargetsRole(t *testing.T) {
+	t.Run("should round trip JSON", func(t *testing.T) {
+		raw := []byte(`{
+			"_type": "targets",
+			"spec_version": "1.0.0",
+			"version": 3,
+			"expires": "2030-01-01T00:00:00Z",
+			"targets": {
+				"firmware/app.bin": {
+					"length": 5,
+					"hashes": {"sha256": "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"},
+					"custom": {"hardwareIds": ["board"]}
+				}
+			},
+			"delegations": {
+				"keys": {"` + string(testKeyID) + `": {"keytype": "ed25519", "keyval": {"public": "abcd"}}},
+				"roles": [{"name": "partner", "keyids": ["` + string(testKeyID) + `"], "threshold": 1, "terminating": true, "paths": ["partner/*"]}]
+			}
+		}`)
+		var role data.TargetsRole
+		if err := json.Unmarshal(raw, &role); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := role.Validate(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		target := role.Targets["firmware/app.bin"]
+		if target.Length != 5 || target.Hashes[data.HashAlgorithmSHA256].String() != "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824" {
+			t.Errorf("unexpected target %v", target)
+		}
+		if role.Delegations.Roles[0].Threshold != 1 || role.Delegations.Roles[0].Name != "partner" {
+			t.Errorf("unexpected delegation %v", role.Delegations.Roles[0])
+		}
+		out, err := json.Marshal(role)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var got data.TargetsRole
+		if err = json.Unmarshal(out, &got); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if string(got.Targets["firmware/app.bin"].Custom) != `{"hardwareIds":["board"]}` {
+			t.Errorf("unexpected custom %s", got.Targets["firmware/app.bin"].Custom)
+		}
+	})
+	t.Run("new targets should be valid", func(t *testing.T) {
+		role := data.NewTargetsRole(1)
+		role.Targets["a.bin"] = data.TargetFileMeta{Length: 5, Hashes: data.NewHashes([]byte("hello"))}
+		if err := role.Validate(); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+	t.Run("should fail validation on target without hashes", func(t *testing.T) {
+		role := data.NewTargetsRole(1)
+		role.Targets["a.bin"] = data.TargetFileMeta{Length: 5}
+		if err := role.Validate(); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+	t.Run("should fail validation on absolute target path", func(t *testing.T) {
+		role := data.NewTargetsRole(1)
+		role.Targets["/a.bin"] = data.TargetFileMeta{Length: 5, Hashes: data.NewHashes([]byte("hello"))}
+		if err := role.Validate(); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+	t.Run("should fail validation on invalid digest length", func(t *testing.T) {
+		role := data.NewTargetsRole(1)
+		role.Targets["a.bin"] = data.TargetFileMeta{Length: 5, Hashes: data.Hashes{data.HashAlgorithmSHA256: []byte("short")}}
+		if err := role.Validate(); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+	t.Run("should fail validation on reserved delegated role name", func(t *testing.T) {
+		role := data.NewTargetsRole(1)
+		role.Delegations = &data.Delegations{
+			Keys: map[data.KeyID]data.Key{testKeyID: {Type: data.KeyTypeEd25519}},
+			Roles: []data.DelegatedRole{{
+				Name:     "snapshot",
+				RoleKeys: data.RoleKeys{KeyIDs: []data.KeyID{testKeyID}, Threshold: 1},
+			}},
+		}
+		if err := role.Validate(); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+	t.Run("should fail validation on both paths and path hash prefixes", func(t *testing.T) {
+		role := data.NewTargetsRole(1)
+		role.Delegations = &data.Delegations{
+			Keys: map[data.KeyID]data.Key{testKeyID: {Type: data.KeyTypeEd25519}},
+			Roles: []data.DelegatedRole{{
+				Name:             "partner",
+				RoleKeys:         data.RoleKeys{KeyIDs: []data.KeyID{testKeyID}, Threshold: 1},
+				Paths:            []string{"*"},
+				PathHashPrefixes: []string{"ab"},
+			}},
+		}
+		if err := role.Validate(); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+}
@@ -0,0 +1,36 @@
+package data
+
+import (
+	"github.com/shuvava/go-ota-svc-common/apperrors"
+)
+
+// TimestampRole is the signed portion of timestamp.json
+// https://theupdateframework.github.io/specification/latest/#file-formats-timestamp
+type TimestampRole struct {
+	RoleHeader
+	// Meta is the description of snapshot metadata file
+	Meta map[string]MetaFileMeta `json:"meta"`
+}
+
+// NewTimestampRole returns a new TimestampRole of provided version pointing to snapshot metadata file
+func NewTimestampRole(version int, snapshot MetaFileMeta) *TimestampRole {
+	return &TimestampRole{
+		RoleHeader: NewRoleHeader(RoleTypeTimestamp, version),
+		Meta:       map[string]MetaFileMeta{RoleTypeSnapshot.MetaFileName(): snapshot},
+	}
+}
+
+// Validate checks that timestamp metadata is well-formed
+func (r *TimestampRole) Validate() error {
+	if err := r.RoleHeader.Validate(RoleTypeTimestamp); err != nil {
+		return err
+	}
+	meta, ok := r.Meta[RoleTypeSnapshot.MetaFileName()]
+	if !ok || len(r.Meta) != 1 {
+		return apperrors.NewAppError(apperrors.ErrorDataValidation, "tuf: timestamp must list only snapshot metadata")
+	}
+	if err := meta.Validate(); err != nil {
+		return apperrors.NewAppError(apperrors.ErrorDataValidation, "tuf: snapshot meta is invalid: "+err.Error())
+	}
+	return nil
+}
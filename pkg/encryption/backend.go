@@ -0,0 +1,90 @@
+package encryption
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"sync"
+
+	"github.com/shuvava/go-ota-svc-common/apperrors"
+
+	"github.com/shuvava/ota-tuf-server/pkg/data"
+	"github.com/shuvava/ota-tuf-server/pkg/errcodes"
+)
+
+// Backend holds private keys outside of the process memory and signs with them
+type Backend interface {
+	// GenerateKey creates a new key of the type in the backend,
+	// it returns public part of the key and the backend reference of the key
+	GenerateKey(keyType data.KeyType, label string) (*data.Key, string, error)
+	// Signer returns signer of the key held by the backend
+	Signer(ref string, pub *data.Key) (Signer, error)
+}
+
+var (
+	backendsMu sync.RWMutex
+	backends   = make(map[data.KeyBackend]Backend)
+)
+
+// RegisterBackend makes key backend available by the name, registered backend with the same name is replaced
+func RegisterBackend(name data.KeyBackend, backend Backend) {
+	backendsMu.Lock()
+	defer backendsMu.Unlock()
+	backends[name] = backend
+}
+
+// GetBackend returns registered key backend by the name
+func GetBackend(name data.KeyBackend) (Backend, error) {
+	backendsMu.RLock()
+	defer backendsMu.RUnlock()
+	backend, ok := backends[name]
+	if !ok {
+		return nil, apperrors.NewAppError(errcodes.ErrorDataSigningBackend,
+			"key backend '"+string(name)+"' is not registered")
+	}
+	return backend, nil
+}
+
+// RepoKeySigner returns signer of the repository key,
+// private part of the key is either stored in the key data or held by a key backend
+func RepoKeySigner(key *data.RepoKey) (Signer, error) {
+	if key.Handle == nil {
+		return UnmarshalSigner(&key.Key)
+	}
+	backend, err := GetBackend(key.Handle.Backend)
+	if err != nil {
+		return nil, err
+	}
+	return backend.Signer(key.Handle.Ref, &key.Key)
+}
+
+// CanSign checks if the repository key can be used for signing
+func CanSign(key *data.RepoKey) bool {
+	return key.Handle != nil || HasPrivateKey(&key.Key)
+}
+
+// NewPublicKey returns data.Key containing public part of RSA, ECDSA or Ed25519 key,
+// type of the key is selected by RSA key size or ECDSA curve
+func NewPublicKey(pub crypto.PublicKey) (*data.Key, error) {
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		keyType := data.KeyTypeRSA
+		for _, t := range []data.KeyType{data.KeyTypeRSA3072, data.KeyTypeRSA4096} {
+			if k.N.BitLen() == t.RSABits() {
+				keyType = t
+			}
+		}
+		return (&RSAKey{PublicKey: k, keyType: keyType}).MarshalPublicData()
+	case *ecdsa.PublicKey:
+		for _, t := range []data.KeyType{data.KeyTypeECDSA, data.KeyTypeECDSAP384, data.KeyTypeECDSAP521} {
+			if curve, _, _ := ECDSACurve(t); curve == k.Curve {
+				return (&ECDSAKey{PublicKey: k, keyType: t}).MarshalPublicData()
+			}
+		}
+		return nil, apperrors.NewAppError(apperrors.ErrorDataValidation, "unsupported ecdsa curve")
+	case ed25519.PublicKey:
+		return (&Ed25519Key{PublicKey: k, keyType: data.KeyTypeEd25519}).MarshalPublicData()
+	}
+	return nil, apperrors.NewAppError(apperrors.ErrorDataValidation, "unsupported public key type")
+}
@@ -1,9 +1,16 @@
 package encryption
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
 	"github.com/shuvava/go-ota-svc-common/apperrors"
+
 	intData "github.com/shuvava/ota-tuf-server/internal/data"
+	"github.com/shuvava/ota-tuf-server/pkg/cjson"
 	"github.com/shuvava/ota-tuf-server/pkg/data"
+	"github.com/shuvava/ota-tuf-server/pkg/errcodes"
 )
 
 // rawKey is a raw key representation used for marshaling/unmarshaling
@@ -14,9 +21,13 @@ type rawKey struct {
 
 // UnmarshalKey takes key data to a working verifier implementation for the key type.
 // This performs any validation over the data.PublicKey to ensure that the verifier is usable
-// to verify signatures.
+// to verify signatures. Keys of any supported encoding are accepted.
 func UnmarshalKey(key *data.Key) (Verifier, error) {
-	switch key.Type {
+	key, err := DecodeKey(key)
+	if err != nil {
+		return nil, err
+	}
+	switch key.Type.Family() {
 	case data.KeyTypeEd25519:
 		return UnmarshalEd25519Key(key)
 	case data.KeyTypeRSA:
@@ -27,15 +38,87 @@ func UnmarshalKey(key *data.Key) (Verifier, error) {
 	return nil, apperrors.NewAppError(apperrors.ErrorDataValidation, "unsupported key type: "+string(key.Type))
 }
 
+// PublicKey returns public part of the key
+func PublicKey(key *data.Key) (*data.Key, error) {
+	verifier, err := UnmarshalKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return verifier.MarshalPublicData()
+}
+
+// UnmarshalSigner takes key data to a working signer implementation for the key type.
+// The key data must contain a private key.
+func UnmarshalSigner(key *data.Key) (Signer, error) {
+	var (
+		signer     Signer
+		hasPrivate bool
+	)
+	switch key.Type.Family() {
+	case data.KeyTypeEd25519:
+		k, err := UnmarshalEd25519Key(key)
+		if err != nil {
+			return nil, err
+		}
+		signer, hasPrivate = k, len(k.PrivateKey) > 0
+	case data.KeyTypeRSA:
+		k, err := UnmarshalRSAKey(key)
+		if err != nil {
+			return nil, err
+		}
+		signer, hasPrivate = k, k.PrivateKey != nil
+	case data.KeyTypeECDSA:
+		k, err := UnmarshalECDSAKey(key)
+		if err != nil {
+			return nil, err
+		}
+		signer, hasPrivate = k, k.PrivateKey != nil
+	default:
+		return nil, apperrors.NewAppError(apperrors.ErrorDataValidation, "unsupported key type: "+string(key.Type))
+	}
+	if !hasPrivate {
+		return nil, apperrors.NewAppError(errcodes.ErrorDataSigningNoPrivateKey, "key does not contain private key")
+	}
+	return signer, nil
+}
+
 // NewKey creates a new encryption key of the given type.
 func NewKey(keyType data.KeyType) (Key, error) {
-	switch keyType {
+	switch keyType.Family() {
 	case data.KeyTypeEd25519:
 		return GenerateEd25519Key()
 	case data.KeyTypeRSA:
-		return GenerateRSAKey()
+		return GenerateRSAKeyOfType(keyType)
 	case data.KeyTypeECDSA:
-		return GenerateECDSAKey()
+		return GenerateECDSAKeyOfType(keyType)
 	}
 	return nil, apperrors.NewAppError(apperrors.ErrorDataValidation, "unsupported key type: "+string(keyType))
 }
+
+// ComputeKeyID returns TUF key id of the key,
+// the SHA-256 digest of the canonical form of the public part of the key.
+func ComputeKeyID(key *data.Key) (data.KeyID, error) {
+	verifier, err := UnmarshalKey(key)
+	if err != nil {
+		return "", err
+	}
+	pub, err := verifier.MarshalPublicData()
+	if err != nil {
+		return "", err
+	}
+	msg, err := cjson.Marshal(pub)
+	if err != nil {
+		return "", apperrors.CreateError(apperrors.ErrorDataSerialization, "failed to marshal public key: ", err)
+	}
+	digest := sha256.Sum256(msg)
+	return data.KeyID(hex.EncodeToString(digest[:])), nil
+}
+
+// HasPrivateKey checks if key data contains a private key
+func HasPrivateKey(key *data.Key) bool {
+	var kv rawKey
+	if err := json.Unmarshal(key.Value, &kv); err != nil {
+		return false
+	}
+	return len(kv.Private) > 0
+}
@@ -0,0 +1,125 @@
+package encryption_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/shuvava/ota-tuf-server/pkg/data"
+	"github.com/shuvava/ota-tuf-server/pkg/encryption"
+)
+
+func TestComputeKeyID(t *testing.T) {
+	t.Run("should be sha256 of canonical public key", func(t *testing.T) {
+		pub := `{"keytype":"ed25519","keyval":{"public":"d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a"}}`
+		key := data.Key{
+			Type:  data.KeyTypeEd25519,
+			Value: []byte(`{"public":"d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a"}`),
+		}
+		digest := sha256.Sum256([]byte(pub))
+		want := data.KeyID(hex.EncodeToString(digest[:]))
+		got, err := encryption.ComputeKeyID(&key)
+		if err != nil {
+			t.Fatalf("unable to compute key id: %v", err)
+		}
+		if got != want {
+			t.Errorf("expected %s, got %s", want, got)
+		}
+	})
+	for _, keyType := range []data.KeyType{data.KeyTypeEd25519, data.KeyTypeECDSA, data.KeyTypeRSA} {
+		t.Run("private data should not change "+string(keyType)+" key id", func(t *testing.T) {
+			key, _ := encryption.NewKey(keyType)
+			allData, err := key.MarshalAllData()
+			if err != nil {
+				t.Fatalf("unable to marshal key: %v", err)
+			}
+			pubData, err := key.(encryption.Verifier).MarshalPublicData()
+			if err != nil {
+				t.Fatalf("unable to marshal key: %v", err)
+			}
+			idAll, err := encryption.ComputeKeyID(allData)
+			if err != nil {
+				t.Fatalf("unable to compute key id: %v", err)
+			}
+			idPub, err := encryption.ComputeKeyID(pubData)
+			if err != nil {
+				t.Fatalf("unable to compute key id: %v", err)
+			}
+			if idAll != idPub {
+				t.Errorf("expected %s, got %s", idPub, idAll)
+			}
+			if _, err = data.KeyIDFromString(idAll.String()); err != nil {
+				t.Errorf("key id is invalid: %v", err)
+			}
+		})
+	}
+}
+
+func TestNewKey(t *testing.T) {
+	keyTypes := []data.KeyType{data.KeyTypeECDSAP384, data.KeyTypeECDSAP521, data.KeyTypeRSA3072, data.KeyTypeRSA4096}
+	for _, keyType := range keyTypes {
+		t.Run("should sign and verify with "+string(keyType)+" key", func(t *testing.T) {
+			key, err := encryption.NewKey(keyType)
+			if err != nil {
+				t.Fatalf("unable to generate key: %v", err)
+			}
+			if key.Type() != keyType {
+				t.Errorf("expected key type %s, got %s", keyType, key.Type())
+			}
+			allData, err := key.MarshalAllData()
+			if err != nil {
+				t.Fatalf("unable to marshal key: %v", err)
+			}
+			signer, err := encryption.UnmarshalSigner(allData)
+			if err != nil {
+				t.Fatalf("unable to unmarshal signer: %v", err)
+			}
+			msg := []byte("message")
+			sig, err := signer.SignMessage(msg)
+			if err != nil {
+				t.Fatalf("unable to sign message: %v", err)
+			}
+			pubData, err := encryption.PublicKey(allData)
+			if err != nil {
+				t.Fatalf("unable to get public key: %v", err)
+			}
+			if pubData.Type != keyType {
+				t.Errorf("expected public key type %s, got %s", keyType, pubData.Type)
+			}
+			verifier, err := encryption.UnmarshalKey(pubData)
+			if err != nil {
+				t.Fatalf("unable to unmarshal verifier: %v", err)
+			}
+			if err = verifier.Verify(msg, sig); err != nil {
+				t.Errorf("signature verification failed: %v", err)
+			}
+		})
+	}
+	t.Run("should fail for unsupported key type", func(t *testing.T) {
+		if _, err := encryption.NewKey("rsa-1024"); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+	t.Run("should reject rsa key of wrong size", func(t *testing.T) {
+		key, _ := encryption.NewKey(data.KeyTypeRSA)
+		pubData, err := key.(encryption.Verifier).MarshalPublicData()
+		if err != nil {
+			t.Fatalf("unable to marshal key: %v", err)
+		}
+		pubData.Type = data.KeyTypeRSA4096
+		if _, err = encryption.UnmarshalKey(pubData); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+	t.Run("should reject ecdsa key of another curve", func(t *testing.T) {
+		key, _ := encryption.NewKey(data.KeyTypeECDSAP384)
+		pubData, err := key.(encryption.Verifier).MarshalPublicData()
+		if err != nil {
+			t.Fatalf("unable to marshal key: %v", err)
+		}
+		pubData.Type = data.KeyTypeECDSA
+		if _, err = encryption.UnmarshalKey(pubData); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+}
@@ -1,10 +1,11 @@
 package encryption
 
 import (
+	"crypto"
 	"crypto/ecdsa"
 	"crypto/elliptic"
 	"crypto/rand"
-	"crypto/sha256"
+	_ "crypto/sha512" // registers SHA-384 and SHA-512 hash functions
 	"encoding/asn1"
 	"encoding/json"
 	"math/big"
@@ -29,16 +30,38 @@ type ECDSAKey struct {
 	keyType    data.KeyType
 }
 
-// GenerateECDSAKey generates a new ecdsa private key and returns it
+// ECDSACurve returns the curve and the hash function used by ecdsa keys of the type
+func ECDSACurve(keyType data.KeyType) (elliptic.Curve, crypto.Hash, error) {
+	switch keyType {
+	case data.KeyTypeECDSA:
+		return elliptic.P256(), crypto.SHA256, nil
+	case data.KeyTypeECDSAP384:
+		return elliptic.P384(), crypto.SHA384, nil
+	case data.KeyTypeECDSAP521:
+		return elliptic.P521(), crypto.SHA512, nil
+	}
+	return nil, 0, apperrors.NewAppError(apperrors.ErrorDataValidation, "unsupported ecdsa key type: "+string(keyType))
+}
+
+// GenerateECDSAKey generates a new P256 ecdsa private key and returns it
 func GenerateECDSAKey() (*ECDSAKey, error) {
-	private, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	return GenerateECDSAKeyOfType(data.KeyTypeECDSA)
+}
+
+// GenerateECDSAKeyOfType generates a new ecdsa private key on the curve defined by the key type and returns it
+func GenerateECDSAKeyOfType(keyType data.KeyType) (*ECDSAKey, error) {
+	curve, _, err := ECDSACurve(keyType)
+	if err != nil {
+		return nil, err
+	}
+	private, err := ecdsa.GenerateKey(curve, rand.Reader)
 	if err != nil {
 		return nil, apperrors.CreateError(errcodes.ErrorDataSerializationECDSAKey, "failed to generate key: ", err)
 	}
 	signer := ECDSAKey{
 		PrivateKey: private,
 		PublicKey:  &private.PublicKey,
-		keyType:    data.KeyTypeECDSA,
+		keyType:    keyType,
 	}
 	return &signer, nil
 }
@@ -69,8 +92,11 @@ func (k *ECDSAKey) Public() string {
 
 // SignMessage signs a message with the private key.
 func (k *ECDSAKey) SignMessage(message []byte) ([]byte, error) {
-	hash := sha256.Sum256(message)
-	r, s, err := ecdsa.Sign(rand.Reader, k.PrivateKey, hash[:])
+	hash, err := k.digest(message)
+	if err != nil {
+		return nil, err
+	}
+	r, s, err := ecdsa.Sign(rand.Reader, k.PrivateKey, hash)
 	if err != nil {
 		return nil, apperrors.CreateError(errcodes.ErrorDataSigningECDSAKey, "failed to sign message: ", err)
 	}
@@ -91,8 +117,11 @@ func (k *ECDSAKey) Verify(msg, sig []byte) error {
 		return err
 	}
 
-	hash := sha256.Sum256(msg)
-	if !ecdsa.Verify(k.PublicKey, hash[:], signature.R, signature.S) {
+	hash, err := k.digest(msg)
+	if err != nil {
+		return err
+	}
+	if !ecdsa.Verify(k.PublicKey, hash, signature.R, signature.S) {
 		return apperrors.NewAppError(errcodes.ErrorDataValidationECDSAKey, "tuf: ecdsa signature verification failed")
 	}
 	return nil
@@ -100,7 +129,7 @@ func (k *ECDSAKey) Verify(msg, sig []byte) error {
 
 // VerifyECDSAKey is a helper function to verify an ecdsa key.
 func VerifyECDSAKey(v *ECDSAKey) error {
-	if !v.PublicKey.IsOnCurve(v.PublicKey.X, v.PublicKey.Y) {
+	if v.PublicKey.X == nil || !v.PublicKey.IsOnCurve(v.PublicKey.X, v.PublicKey.Y) {
 		return apperrors.NewAppError(errcodes.ErrorDataValidationECDSAKey, "tuf: ecdsa key is invalid")
 	}
 	return nil
@@ -112,15 +141,19 @@ func UnmarshalECDSAKey(key *data.Key) (*ECDSAKey, error) {
 	if err := json.Unmarshal(key.Value, &kv); err != nil {
 		return nil, err
 	}
-	x, y := elliptic.Unmarshal(elliptic.P256(), kv.Public)
+	curve, _, err := ECDSACurve(key.Type)
+	if err != nil {
+		return nil, err
+	}
+	x, y := elliptic.Unmarshal(curve, kv.Public)
 	publicKey := ecdsa.PublicKey{
-		Curve: elliptic.P256(),
+		Curve: curve,
 		X:     x,
 		Y:     y,
 	}
 	ecdsaKey := ECDSAKey{
 		PublicKey: &publicKey,
-		keyType:   data.KeyTypeECDSA,
+		keyType:   key.Type,
 	}
 	if len(kv.Private) > 0 {
 		privateKey := ecdsa.PrivateKey{
@@ -148,3 +181,14 @@ func (k *ECDSAKey) marshalKey(kv rawKey) (*data.Key, error) {
 		Value: valueBytes,
 	}, nil
 }
+
+// digest returns hash of the message by the hash function of the key type
+func (k *ECDSAKey) digest(message []byte) ([]byte, error) {
+	_, hashFunc, err := ECDSACurve(k.keyType)
+	if err != nil {
+		return nil, err
+	}
+	h := hashFunc.New()
+	h.Write(message)
+	return h.Sum(nil), nil
+}
@@ -0,0 +1,254 @@
+package encryption
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"strings"
+
+	"github.com/shuvava/go-ota-svc-common/apperrors"
+
+	"github.com/shuvava/ota-tuf-server/pkg/cjson"
+	"github.com/shuvava/ota-tuf-server/pkg/data"
+)
+
+// Key types and signature methods of Aktualizr and ota-tuf encoding
+const (
+	aktualizrKeyTypeRSA          = data.KeyType("RSA")
+	aktualizrKeyTypeEd25519      = data.KeyType("ED25519")
+	aktualizrKeyTypeECPrime256V1 = data.KeyType("ECPRIME256V1")
+	// aktualizrMethodRSAPSS is the legacy name of rsassa-pss-sha256 still accepted by Aktualizr
+	aktualizrMethodRSAPSS       = data.SignatureMethod("rsassa-pss")
+	aktualizrMethodECPrime256V1 = data.SignatureMethod("ecPrime256v1")
+)
+
+// Key types of python-tuf encoding of ECDSA keys, the key type is the same as the signature scheme
+const (
+	pythonTUFKeyTypeECDSAP256 = data.KeyType(data.SignatureMethodECDSA)
+	pythonTUFKeyTypeECDSAP384 = data.KeyType(data.SignatureMethodECDSAP384)
+)
+
+const (
+	pemPrefix        = "-----BEGIN"
+	pemTypePublicKey = "PUBLIC KEY"
+)
+
+// keyEncodings is the list of supported encodings
+var keyEncodings = []data.KeyEncoding{data.KeyEncodingTUF, data.KeyEncodingAktualizr, data.KeyEncodingPythonTUF}
+
+// encodedKeyFamilies maps key types of foreign encodings to key type families
+var encodedKeyFamilies = map[data.KeyType]data.KeyType{
+	aktualizrKeyTypeRSA:          data.KeyTypeRSA,
+	aktualizrKeyTypeEd25519:      data.KeyTypeEd25519,
+	aktualizrKeyTypeECPrime256V1: data.KeyTypeECDSA,
+	pythonTUFKeyTypeECDSAP256:    data.KeyTypeECDSA,
+	pythonTUFKeyTypeECDSAP384:    data.KeyTypeECDSA,
+}
+
+// encodedKey is the key value of foreign encodings, public key is either PEM or hex string
+type encodedKey struct {
+	Public string `json:"public"`
+}
+
+// EncodePublicKey returns public part of the key in the encoding.
+// Clients compute key ids from the encoded form, so the key is published under EncodedKeyID.
+func EncodePublicKey(key *data.Key, enc data.KeyEncoding) (*data.Key, error) {
+	native, err := PublicKey(key)
+	if err != nil || enc.IsNative() {
+		return native, err
+	}
+	method, err := SignatureMethod(native.Type, enc)
+	if err != nil {
+		return nil, err
+	}
+	verifier, err := UnmarshalKey(native)
+	if err != nil {
+		return nil, err
+	}
+	var pub string
+	switch k := verifier.(type) {
+	case *Ed25519Key:
+		pub = hex.EncodeToString(k.PublicKey)
+	case *RSAKey:
+		pub, err = encodePEM(k.PublicKey)
+	case *ECDSAKey:
+		pub, err = encodePEM(k.PublicKey)
+	}
+	if err != nil {
+		return nil, err
+	}
+	res := data.Key{Type: native.Type.Family()}
+	switch enc {
+	case data.KeyEncodingAktualizr:
+		switch res.Type {
+		case data.KeyTypeRSA:
+			res.Type = aktualizrKeyTypeRSA
+		case data.KeyTypeEd25519:
+			res.Type = aktualizrKeyTypeEd25519
+		case data.KeyTypeECDSA:
+			res.Type = aktualizrKeyTypeECPrime256V1
+		}
+	case data.KeyEncodingPythonTUF:
+		if res.Type == data.KeyTypeECDSA {
+			res.Type = data.KeyType(method)
+		}
+		res.Scheme = method
+	}
+	res.Value, err = json.Marshal(encodedKey{Public: pub})
+	if err != nil {
+		return nil, apperrors.CreateError(apperrors.ErrorDataSerialization, "failed to marshal key: ", err)
+	}
+	return &res, nil
+}
+
+// EncodedKeyID returns id of the key published in the encoding,
+// it equals to the id computed from the native form for the native encoding only
+func EncodedKeyID(key *data.Key, enc data.KeyEncoding) (data.KeyID, error) {
+	pub, err := EncodePublicKey(key, enc)
+	if err != nil {
+		return "", err
+	}
+	return PublishedKeyID(pub)
+}
+
+// PublishedKeyID returns id of the public key in the form it is published in metadata,
+// it is sha256 digest of canonical JSON form of the key in any supported encoding
+func PublishedKeyID(key *data.Key) (data.KeyID, error) {
+	msg, err := cjson.Marshal(key)
+	if err != nil {
+		return "", apperrors.CreateError(apperrors.ErrorDataSerialization, "failed to marshal public key: ", err)
+	}
+	if isAktualizrKeyType(key.Type) {
+		// Aktualizr and ota-tuf escape control characters in canonical JSON, e.g. new lines of PEM public keys
+		msg = escapeControlChars(msg)
+	}
+	digest := sha256.Sum256(msg)
+	return data.KeyID(hex.EncodeToString(digest[:])), nil
+}
+
+// DecodeKey returns the native form of the key in any supported encoding,
+// keys in the native encoding are returned as is.
+func DecodeKey(key *data.Key) (*data.Key, error) {
+	var kv encodedKey
+	if err := json.Unmarshal(key.Value, &kv); err != nil {
+		// not a key of foreign encoding, it is validated by the key type unmarshaler
+		return key, nil
+	}
+	family, ok := encodedKeyFamilies[key.Type]
+	if !ok {
+		family = key.Type.Family()
+	}
+	if strings.HasPrefix(kv.Public, pemPrefix) {
+		block, _ := pem.Decode([]byte(kv.Public))
+		if block == nil {
+			return nil, apperrors.NewAppError(apperrors.ErrorDataValidation, "unable to decode PEM block in public key")
+		}
+		pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, apperrors.CreateError(apperrors.ErrorDataValidation, "failed to unmarshal public key: ", err)
+		}
+		res, err := NewPublicKey(pub)
+		if err != nil {
+			return nil, err
+		}
+		if res.Type.Family() != family {
+			return nil, apperrors.NewAppError(apperrors.ErrorDataValidation,
+				"public key does not match key type: "+string(key.Type))
+		}
+		return res, nil
+	}
+	if key.Type == aktualizrKeyTypeEd25519 || key.Scheme != "" {
+		return &data.Key{Type: family, Value: key.Value}, nil
+	}
+	return key, nil
+}
+
+// SignatureMethod returns signature method of the key type in the encoding
+func SignatureMethod(keyType data.KeyType, enc data.KeyEncoding) (data.SignatureMethod, error) {
+	method := keyType.SignatureMethod()
+	switch enc {
+	case "", data.KeyEncodingTUF:
+	case data.KeyEncodingAktualizr:
+		// ota-tuf supports ECDSA keys on P256 curve only, Aktualizr does not support them at all
+		switch keyType {
+		case data.KeyTypeECDSA:
+			method = aktualizrMethodECPrime256V1
+		case data.KeyTypeECDSAP384, data.KeyTypeECDSAP521:
+			method = ""
+		}
+	case data.KeyEncodingPythonTUF:
+		if keyType == data.KeyTypeECDSAP521 {
+			method = ""
+		}
+	default:
+		return "", apperrors.NewAppError(apperrors.ErrorDataValidation, "unsupported key encoding: "+string(enc))
+	}
+	if method == "" {
+		return "", apperrors.NewAppError(apperrors.ErrorDataValidation,
+			"key type "+string(keyType)+" is not supported by "+string(enc)+" key encoding")
+	}
+	return method, nil
+}
+
+// isSignatureMethodOf checks if the signature method is valid for the key type in any supported encoding
+func isSignatureMethodOf(method data.SignatureMethod, keyType data.KeyType) bool {
+	if method == aktualizrMethodRSAPSS && keyType.Family() == data.KeyTypeRSA {
+		return true
+	}
+	for _, enc := range keyEncodings {
+		if m, err := SignatureMethod(keyType, enc); err == nil && m == method {
+			return true
+		}
+	}
+	return false
+}
+
+// isAktualizrKeyType checks if the key type is the key type of Aktualizr encoding
+func isAktualizrKeyType(keyType data.KeyType) bool {
+	switch keyType {
+	case aktualizrKeyTypeRSA, aktualizrKeyTypeEd25519, aktualizrKeyTypeECPrime256V1:
+		return true
+	}
+	return false
+}
+
+// escapeControlChars escapes control characters of canonical JSON document the way JSON encoders do,
+// canonical form does not have whitespaces, so every control character is a part of a string
+func escapeControlChars(doc []byte) []byte {
+	const hexDigits = "0123456789abcdef"
+	var buf bytes.Buffer
+	for _, b := range doc {
+		switch {
+		case b == '\b':
+			buf.WriteString(`\b`)
+		case b == '\f':
+			buf.WriteString(`\f`)
+		case b == '\n':
+			buf.WriteString(`\n`)
+		case b == '\r':
+			buf.WriteString(`\r`)
+		case b == '\t':
+			buf.WriteString(`\t`)
+		case b < 0x20:
+			buf.WriteString(`\u00`)
+			buf.WriteByte(hexDigits[b>>4])
+			buf.WriteByte(hexDigits[b&0xf])
+		default:
+			buf.WriteByte(b)
+		}
+	}
+	return buf.Bytes()
+}
+
+// encodePEM returns PEM encoded PKIX form of the public key
+func encodePEM(pub crypto.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return "", apperrors.CreateError(apperrors.ErrorDataSerialization, "failed to marshal public key: ", err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: pemTypePublicKey, Bytes: der})), nil
+}
@@ -0,0 +1,291 @@
+package encryption_test
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shuvava/ota-tuf-server/pkg/cjson"
+	"github.com/shuvava/ota-tuf-server/pkg/data"
+	"github.com/shuvava/ota-tuf-server/pkg/encryption"
+)
+
+// encodingKeys are names of fixed keys in testdata/keys
+var encodingKeys = []string{"ed25519", "rsa", "ecdsa", "ecdsa-p384"}
+
+func readKey(t *testing.T, name string) data.Key {
+	t.Helper()
+	var key data.Key
+	readJSON(t, filepath.Join("testdata", "keys", name+".json"), &key)
+	return key
+}
+
+func readJSON(t *testing.T, path string, v interface{}) {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("unable to read %s: %v", path, err)
+	}
+	if err = json.Unmarshal(content, v); err != nil {
+		t.Fatalf("unable to unmarshal %s: %v", path, err)
+	}
+}
+
+// readKeyIDs returns ids of fixed keys published in the encoding, they are computed outside of the package
+// by the canonical JSON form of clients of the encoding
+func readKeyIDs(t *testing.T, enc data.KeyEncoding) map[string]data.KeyID {
+	t.Helper()
+	var ids map[string]data.KeyID
+	readJSON(t, filepath.Join("testdata", "encoding", string(enc), "keyids.json"), &ids)
+	return ids
+}
+
+// assertGolden compares JSON form of v with the golden file
+func assertGolden(t *testing.T, path string, v interface{}) {
+	t.Helper()
+	got, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		t.Fatalf("unable to marshal: %v", err)
+	}
+	got = append(got, '\n')
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("unable to read golden file: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s mismatch\nexpected:\n%s\ngot:\n%s", path, want, got)
+	}
+}
+
+func TestEncodePublicKey(t *testing.T) {
+	encodings := []data.KeyEncoding{data.KeyEncodingTUF, data.KeyEncodingAktualizr, data.KeyEncodingPythonTUF}
+	for _, enc := range encodings {
+		for _, name := range encodingKeys {
+			golden := filepath.Join("testdata", "encoding", string(enc), name+".json")
+			t.Run("should encode "+name+" key in "+string(enc)+" encoding", func(t *testing.T) {
+				key := readKey(t, name)
+				got, err := encryption.EncodePublicKey(&key, enc)
+				if enc == data.KeyEncodingAktualizr && name == "ecdsa-p384" {
+					if err == nil {
+						t.Error("expected error, got nil")
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("unable to encode key: %v", err)
+				}
+				assertGolden(t, golden, got)
+				keyID, err := encryption.EncodedKeyID(&key, enc)
+				if err != nil {
+					t.Fatalf("unable to compute key id: %v", err)
+				}
+				if want := readKeyIDs(t, enc)[name]; keyID != want {
+					t.Errorf("expected key id %s, got %s", want, keyID)
+				}
+			})
+		}
+	}
+	t.Run("should not encode ecdsa-p521 key in python-tuf encoding", func(t *testing.T) {
+		key, _ := encryption.NewKey(data.KeyTypeECDSAP521)
+		pub, _ := key.(encryption.Verifier).MarshalPublicData()
+		if _, err := encryption.EncodePublicKey(pub, data.KeyEncodingPythonTUF); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+}
+
+func TestPublishedKeyID(t *testing.T) {
+	encodings := []data.KeyEncoding{data.KeyEncodingTUF, data.KeyEncodingAktualizr, data.KeyEncodingPythonTUF}
+	for _, enc := range encodings {
+		for name, want := range readKeyIDs(t, enc) {
+			t.Run("should compute id of "+name+" key published in "+string(enc)+" encoding", func(t *testing.T) {
+				var key data.Key
+				readJSON(t, filepath.Join("testdata", "encoding", string(enc), name+".json"), &key)
+				got, err := encryption.PublishedKeyID(&key)
+				if err != nil {
+					t.Fatalf("unable to compute key id: %v", err)
+				}
+				if got != want {
+					t.Errorf("expected %s, got %s", want, got)
+				}
+			})
+		}
+	}
+	t.Run("should keep native key id in native encoding", func(t *testing.T) {
+		key := readKey(t, "rsa")
+		want, _ := encryption.ComputeKeyID(&key)
+		if got, _ := encryption.EncodedKeyID(&key, data.KeyEncodingTUF); got != want {
+			t.Errorf("expected %s, got %s", want, got)
+		}
+	})
+}
+
+func TestDecodeKey(t *testing.T) {
+	encodings := []data.KeyEncoding{data.KeyEncodingTUF, data.KeyEncodingAktualizr, data.KeyEncodingPythonTUF}
+	for _, enc := range encodings {
+		for _, name := range encodingKeys {
+			golden := filepath.Join("testdata", "encoding", string(enc), name+".json")
+			if _, err := os.Stat(golden); err != nil {
+				continue
+			}
+			t.Run("should decode "+name+" key of "+string(enc)+" encoding to the native key", func(t *testing.T) {
+				key := readKey(t, name)
+				var encoded data.Key
+				readJSON(t, golden, &encoded)
+				got, err := encryption.DecodeKey(&encoded)
+				if err != nil {
+					t.Fatalf("unable to decode key: %v", err)
+				}
+				if got.Type != key.Type {
+					t.Errorf("expected %s, got %s", key.Type, got.Type)
+				}
+				want, _ := encryption.ComputeKeyID(&key)
+				keyID, err := encryption.ComputeKeyID(got)
+				if err != nil {
+					t.Fatalf("unable to compute key id: %v", err)
+				}
+				if keyID != want {
+					t.Errorf("expected %s, got %s", want, keyID)
+				}
+			})
+		}
+	}
+	t.Run("should reject PEM key of another key type", func(t *testing.T) {
+		var encoded data.Key
+		readJSON(t, filepath.Join("testdata", "encoding", "aktualizr", "rsa.json"), &encoded)
+		encoded.Type = "ECPRIME256V1"
+		if _, err := encryption.DecodeKey(&encoded); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+}
+
+func TestSignPayloadWithEncoding(t *testing.T) {
+	newRoot := func(t *testing.T, enc data.KeyEncoding, keys ...data.RepoKey) data.RootRole {
+		root := data.RootRole{
+			RoleHeader: data.RoleHeader{
+				Type:        data.RoleTypeRoot,
+				SpecVersion: "1.0.0",
+				Version:     1,
+				Expires:     time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC),
+			},
+			Keys:  map[data.KeyID]data.Key{},
+			Roles: map[data.RoleType]*data.RoleKeys{},
+		}
+		for role := range data.TopLevelRoles {
+			root.Roles[role] = &data.RoleKeys{Threshold: 1}
+		}
+		for _, key := range keys {
+			pub, err := encryption.EncodePublicKey(&key.Key, enc)
+			if err != nil {
+				t.Fatalf("unable to encode key: %v", err)
+			}
+			keyID, err := encryption.EncodedKeyID(&key.Key, enc)
+			if err != nil {
+				t.Fatalf("unable to compute key id: %v", err)
+			}
+			root.Keys[keyID] = *pub
+			for _, roleKeys := range root.Roles {
+				roleKeys.KeyIDs = append(roleKeys.KeyIDs, keyID)
+			}
+		}
+		return root
+	}
+	newRepoKey := func(t *testing.T, key data.Key) data.RepoKey {
+		keyID, err := encryption.ComputeKeyID(&key)
+		if err != nil {
+			t.Fatalf("unable to compute key id: %v", err)
+		}
+		return data.RepoKey{KeyID: keyID, Key: key}
+	}
+
+	for _, enc := range []data.KeyEncoding{data.KeyEncodingAktualizr, data.KeyEncodingPythonTUF} {
+		t.Run("should produce "+string(enc)+" root of the fixture signed by ed25519 key", func(t *testing.T) {
+			var want data.SignedPayload
+			readJSON(t, filepath.Join("testdata", "encoding", string(enc), "root.json"), &want)
+			key := newRepoKey(t, readKey(t, "ed25519"))
+			root := newRoot(t, enc, key)
+			signed, err := encryption.SignPayloadWithEncoding(root, []data.RepoKey{key}, enc)
+			if err != nil {
+				t.Fatalf("unable to sign payload: %v", err)
+			}
+			got, _ := cjson.Canonicalize(signed.Signed)
+			wantSigned, _ := cjson.Canonicalize(want.Signed)
+			if !bytes.Equal(got, wantSigned) {
+				t.Errorf("signed portion mismatch\nexpected:\n%s\ngot:\n%s", wantSigned, got)
+			}
+			if len(signed.Signatures) != 1 || signed.Signatures[0].KeyID != want.Signatures[0].KeyID ||
+				!bytes.Equal(signed.Signatures[0].Signature, want.Signatures[0].Signature) {
+				t.Errorf("expected signature %v, got %v", want.Signatures, signed.Signatures)
+			}
+		})
+		t.Run("should verify golden "+string(enc)+" root", func(t *testing.T) {
+			var signed data.SignedPayload
+			readJSON(t, filepath.Join("testdata", "encoding", string(enc), "root.json"), &signed)
+			var root data.RootRole
+			if err := signed.DecodeSigned(&root); err != nil {
+				t.Fatalf("unable to decode root: %v", err)
+			}
+			if err := encryption.VerifyPayload(&signed, root.Keys, root.Roles[data.RoleTypeRoot]); err != nil {
+				t.Errorf("expected valid payload, got %v", err)
+			}
+		})
+	}
+	t.Run("should name signature methods by the encoding", func(t *testing.T) {
+		key, _ := encryption.NewKey(data.KeyTypeECDSA)
+		dtKey, _ := key.MarshalAllData()
+		repoKey := newRepoKey(t, *dtKey)
+		cases := map[data.KeyEncoding]data.SignatureMethod{
+			data.KeyEncodingTUF:       data.SignatureMethodECDSA,
+			data.KeyEncodingAktualizr: "ecPrime256v1",
+			data.KeyEncodingPythonTUF: data.SignatureMethodECDSA,
+		}
+		for enc, want := range cases {
+			root := newRoot(t, enc, repoKey)
+			signed, err := encryption.SignPayloadWithEncoding(root, []data.RepoKey{repoKey}, enc)
+			if err != nil {
+				t.Fatalf("unable to sign payload: %v", err)
+			}
+			if got := signed.Signatures[0].Method; got != want {
+				t.Errorf("expected %s, got %s", want, got)
+			}
+			if err = encryption.VerifyPayload(signed, root.Keys, root.Roles[data.RoleTypeRoot]); err != nil {
+				t.Errorf("expected valid payload, got %v", err)
+			}
+		}
+	})
+	t.Run("should sign with rsa pss salt of the hash length", func(t *testing.T) {
+		key, _ := encryption.NewKey(data.KeyTypeRSA)
+		dtKey, _ := key.MarshalAllData()
+		repoKey := newRepoKey(t, *dtKey)
+		root := newRoot(t, data.KeyEncodingAktualizr, repoKey)
+		signed, err := encryption.SignPayloadWithEncoding(root, []data.RepoKey{repoKey}, data.KeyEncodingAktualizr)
+		if err != nil {
+			t.Fatalf("unable to sign payload: %v", err)
+		}
+		msg, _ := cjson.Canonicalize(signed.Signed)
+		digest := sha256.Sum256(msg)
+		opts := rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash}
+		pub := key.(*encryption.RSAKey).PublicKey
+		if err = rsa.VerifyPSS(pub, crypto.SHA256, digest[:], signed.Signatures[0].Signature, &opts); err != nil {
+			t.Errorf("expected valid signature, got %v", err)
+		}
+	})
+	t.Run("should accept legacy rsassa-pss method", func(t *testing.T) {
+		key, _ := encryption.NewKey(data.KeyTypeRSA)
+		dtKey, _ := key.MarshalAllData()
+		repoKey := newRepoKey(t, *dtKey)
+		root := newRoot(t, data.KeyEncodingAktualizr, repoKey)
+		signed, _ := encryption.SignPayloadWithEncoding(root, []data.RepoKey{repoKey}, data.KeyEncodingAktualizr)
+		signed.Signatures[0].Method = "rsassa-pss"
+		if err := encryption.VerifyPayload(signed, root.Keys, root.Roles[data.RoleTypeRoot]); err != nil {
+			t.Errorf("expected valid payload, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,72 @@
+package pkcs11
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"encoding/asn1"
+	"math/big"
+
+	"github.com/shuvava/go-ota-svc-common/apperrors"
+
+	"github.com/shuvava/ota-tuf-server/pkg/data"
+	"github.com/shuvava/ota-tuf-server/pkg/encryption"
+	"github.com/shuvava/ota-tuf-server/pkg/errcodes"
+)
+
+// ecdsaSignature is ASN.1 form of ECDSA signature used by encryption.ECDSAKey
+type ecdsaSignature struct {
+	R, S *big.Int
+}
+
+// ECDSASignatureASN1 converts ECDSA signature returned by PKCS#11 module as r||s
+// to ASN.1 form used by encryption.ECDSAKey
+func ECDSASignatureASN1(sig []byte) ([]byte, error) {
+	if len(sig) == 0 || len(sig)%2 != 0 {
+		return nil, apperrors.NewAppError(errcodes.ErrorDataSigningECDSAKey, "tuf: ecdsa signature of PKCS#11 module is invalid")
+	}
+	half := len(sig) / 2
+	der, err := asn1.Marshal(ecdsaSignature{
+		R: new(big.Int).SetBytes(sig[:half]),
+		S: new(big.Int).SetBytes(sig[half:]),
+	})
+	if err != nil {
+		return nil, apperrors.CreateError(errcodes.ErrorDataSigningECDSAKey, "failed to sign message: ", err)
+	}
+	return der, nil
+}
+
+// PublicKeyFromECPoint returns data.Key of ECDSA or Ed25519 public key of the type by CKA_EC_POINT value
+func PublicKeyFromECPoint(keyType data.KeyType, value []byte) (*data.Key, error) {
+	if keyType == data.KeyTypeEd25519 {
+		point := ecPoint(value, ed25519.PublicKeySize)
+		if len(point) != ed25519.PublicKeySize {
+			return nil, apperrors.NewAppError(errcodes.ErrorDataValidationEd25519Key, "tuf: ed25519 public key is invalid")
+		}
+		return encryption.NewPublicKey(ed25519.PublicKey(point))
+	}
+	curve, _, err := encryption.ECDSACurve(keyType)
+	if err != nil {
+		return nil, err
+	}
+	// uncompressed point is 0x04 || X || Y
+	pointSize := 1 + 2*((curve.Params().BitSize+7)/8)
+	x, y := elliptic.Unmarshal(curve, ecPoint(value, pointSize))
+	if x == nil {
+		return nil, apperrors.NewAppError(errcodes.ErrorDataValidationECDSAKey, "tuf: ecdsa key is invalid")
+	}
+	return encryption.NewPublicKey(&ecdsa.PublicKey{Curve: curve, X: x, Y: y})
+}
+
+// ecPoint returns raw EC point of CKA_EC_POINT value,
+// the value is DER encoded OCTET STRING by the standard but some modules return raw point
+func ecPoint(value []byte, size int) []byte {
+	if len(value) == size {
+		return value
+	}
+	var raw []byte
+	if rest, err := asn1.Unmarshal(value, &raw); err == nil && len(rest) == 0 {
+		return raw
+	}
+	return value
+}
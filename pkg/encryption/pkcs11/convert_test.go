@@ -0,0 +1,119 @@
+package pkcs11_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/asn1"
+	"math/big"
+	"testing"
+
+	"github.com/shuvava/ota-tuf-server/pkg/data"
+	"github.com/shuvava/ota-tuf-server/pkg/encryption"
+	"github.com/shuvava/ota-tuf-server/pkg/encryption/pkcs11"
+)
+
+func TestECDSASignatureASN1(t *testing.T) {
+	message := []byte("signed message")
+	tests := []struct {
+		name    string
+		keyType data.KeyType
+	}{
+		{"should convert P-256 signature", data.KeyTypeECDSA},
+		{"should convert P-384 signature", data.KeyTypeECDSAP384},
+		{"should convert P-521 signature", data.KeyTypeECDSAP521},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			curve, hashFunc, _ := encryption.ECDSACurve(tt.keyType)
+			priv, err := ecdsa.GenerateKey(curve, rand.Reader)
+			if err != nil {
+				t.Fatalf("unable to generate key: %v", err)
+			}
+			h := hashFunc.New()
+			h.Write(message)
+			r, s, err := ecdsa.Sign(rand.Reader, priv, h.Sum(nil))
+			if err != nil {
+				t.Fatalf("unable to sign message: %v", err)
+			}
+			// PKCS#11 modules return r and s padded to the curve size
+			size := (curve.Params().BitSize + 7) / 8
+			raw := make([]byte, 2*size)
+			r.FillBytes(raw[:size])
+			s.FillBytes(raw[size:])
+			sig, err := pkcs11.ECDSASignatureASN1(raw)
+			if err != nil {
+				t.Fatalf("unable to convert signature: %v", err)
+			}
+			pub, _ := encryption.NewPublicKey(&priv.PublicKey)
+			verifier, _ := encryption.UnmarshalKey(pub)
+			if err = verifier.Verify(message, sig); err != nil {
+				t.Errorf("signature verification failed: %v", err)
+			}
+		})
+	}
+	t.Run("should strip padding of r and s", func(t *testing.T) {
+		raw := make([]byte, 64)
+		raw[31], raw[63] = 0x01, 0x02
+		sig, err := pkcs11.ECDSASignatureASN1(raw)
+		if err != nil {
+			t.Fatalf("unable to convert signature: %v", err)
+		}
+		var parsed struct{ R, S *big.Int }
+		if _, err = asn1.Unmarshal(sig, &parsed); err != nil || parsed.R.Int64() != 1 || parsed.S.Int64() != 2 {
+			t.Errorf("expected r 1 and s 2, got %v %v (%v)", parsed.R, parsed.S, err)
+		}
+	})
+	for _, raw := range [][]byte{nil, {0x01, 0x02, 0x03}} {
+		if _, err := pkcs11.ECDSASignatureASN1(raw); err == nil {
+			t.Errorf("expected error on signature %x, got nil", raw)
+		}
+	}
+}
+
+func TestPublicKeyFromECPoint(t *testing.T) {
+	edPub, _, _ := ed25519.GenerateKey(rand.Reader)
+	edKey, _ := encryption.NewPublicKey(edPub)
+	ecPriv, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	ecKey, _ := encryption.NewPublicKey(&ecPriv.PublicKey)
+	ecPoint := elliptic.Marshal(elliptic.P384(), ecPriv.X, ecPriv.Y)
+	octetString := func(b []byte) []byte {
+		der, err := asn1.Marshal(b)
+		if err != nil {
+			t.Fatalf("unable to encode point: %v", err)
+		}
+		return der
+	}
+	tests := []struct {
+		name    string
+		keyType data.KeyType
+		value   []byte
+		want    *data.Key
+	}{
+		{"should parse DER encoded ed25519 point", data.KeyTypeEd25519, octetString(edPub), edKey},
+		{"should parse raw ed25519 point", data.KeyTypeEd25519, edPub, edKey},
+		{"should parse DER encoded ecdsa point", data.KeyTypeECDSAP384, octetString(ecPoint), ecKey},
+		{"should parse raw ecdsa point", data.KeyTypeECDSAP384, ecPoint, ecKey},
+		{"should reject ed25519 point of invalid size", data.KeyTypeEd25519, edPub[1:], nil},
+		{"should reject point not on the curve", data.KeyTypeECDSA, ecPoint, nil},
+		{"should reject point of rsa key", data.KeyTypeRSA, ecPoint, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := pkcs11.PublicKeyFromECPoint(tt.keyType, tt.value)
+			if tt.want == nil {
+				if err == nil {
+					t.Errorf("expected error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unable to parse point: %v", err)
+			}
+			if got.Type != tt.want.Type || string(got.Value) != string(tt.want.Value) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,10 @@
+// Package pkcs11 implements encryption.Backend keeping private keys in HSM token accessed through PKCS#11 module.
+// Supported key types are RSA 2048/3072/4096 (RSASSA-PSS with SHA-256), ECDSA P-256/P-384/P-521 and Ed25519.
+//
+// Backend requires cgo and is compiled only with `pkcs11` build tag,
+// conversions of signatures and public keys returned by PKCS#11 modules are compiled without it:
+//
+//	go build -tags pkcs11 ./...
+//
+// SoftHSM can be used as PKCS#11 module for local development and tests.
+package pkcs11
@@ -0,0 +1,297 @@
+//go:build pkcs11
+
+package pkcs11
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/hex"
+	"math/big"
+	"strings"
+	"sync"
+
+	p11 "github.com/miekg/pkcs11"
+	"github.com/shuvava/go-ota-svc-common/apperrors"
+
+	"github.com/shuvava/ota-tuf-server/pkg/data"
+	"github.com/shuvava/ota-tuf-server/pkg/encryption"
+	"github.com/shuvava/ota-tuf-server/pkg/errcodes"
+)
+
+// BackendName is the name of PKCS#11 key backend
+const BackendName = data.KeyBackend("pkcs11")
+
+const (
+	// PKCS#11 v3.0 Edwards curve mechanisms missing in github.com/miekg/pkcs11
+	ckmECEdwardsKeyPairGen = 0x1055
+	ckmEdDSA               = 0x1057
+
+	keyIDSize = 16
+)
+
+var (
+	// DER encoded OIDs of NIST curves by ecdsa key type
+	ecdsaCurveOIDs = map[data.KeyType][]byte{
+		data.KeyTypeECDSA:     {0x06, 0x08, 0x2a, 0x86, 0x48, 0xce, 0x3d, 0x03, 0x01, 0x07},
+		data.KeyTypeECDSAP384: {0x06, 0x05, 0x2b, 0x81, 0x04, 0x00, 0x22},
+		data.KeyTypeECDSAP521: {0x06, 0x05, 0x2b, 0x81, 0x04, 0x00, 0x23},
+	}
+	// DER encoded OID of Ed25519 curve
+	oidEd25519 = []byte{0x06, 0x03, 0x2b, 0x65, 0x70}
+)
+
+// Config is the PKCS#11 token configuration
+type Config struct {
+	// Module is the path of PKCS#11 module shared library
+	Module string
+	// TokenLabel is the label of the token keeping keys
+	TokenLabel string
+	// Pin is the user PIN of the token
+	Pin string
+}
+
+// Backend is encryption.Backend keeping keys in PKCS#11 token.
+// PKCS#11 sessions are not safe for concurrent use, all token operations are serialized.
+type Backend struct {
+	mu      sync.Mutex
+	ctx     *p11.Ctx
+	session p11.SessionHandle
+}
+
+// New loads PKCS#11 module, opens session to the token and logs in
+func New(cfg Config) (*Backend, error) {
+	ctx := p11.New(cfg.Module)
+	if ctx == nil {
+		return nil, apperrors.NewAppError(errcodes.ErrorDataSigningBackend,
+			"failed to load PKCS#11 module '"+cfg.Module+"'")
+	}
+	if err := ctx.Initialize(); err != nil {
+		ctx.Destroy()
+		return nil, backendError("failed to initialize PKCS#11 module", err)
+	}
+	b := &Backend{ctx: ctx}
+	slot, err := b.findSlot(cfg.TokenLabel)
+	if err != nil {
+		b.destroy()
+		return nil, err
+	}
+	if b.session, err = ctx.OpenSession(slot, p11.CKF_SERIAL_SESSION|p11.CKF_RW_SESSION); err != nil {
+		b.destroy()
+		return nil, backendError("failed to open PKCS#11 session", err)
+	}
+	if err = ctx.Login(b.session, p11.CKU_USER, cfg.Pin); err != nil {
+		_ = ctx.CloseSession(b.session)
+		b.destroy()
+		return nil, backendError("failed to login to PKCS#11 token", err)
+	}
+	return b, nil
+}
+
+// Close logs out and releases PKCS#11 module
+func (b *Backend) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	_ = b.ctx.Logout(b.session)
+	err := b.ctx.CloseSession(b.session)
+	b.destroy()
+	if err != nil {
+		return backendError("failed to close PKCS#11 session", err)
+	}
+	return nil
+}
+
+// GenerateKey creates a new non-extractable key pair in the token,
+// the reference of the key is hex encoded CKA_ID of the key pair
+func (b *Backend) GenerateKey(keyType data.KeyType, label string) (*data.Key, string, error) {
+	id := make([]byte, keyIDSize)
+	if _, err := rand.Read(id); err != nil {
+		return nil, "", backendError("failed to generate key id", err)
+	}
+	pubTemplate := []*p11.Attribute{
+		p11.NewAttribute(p11.CKA_TOKEN, true),
+		p11.NewAttribute(p11.CKA_VERIFY, true),
+		p11.NewAttribute(p11.CKA_ID, id),
+		p11.NewAttribute(p11.CKA_LABEL, label),
+	}
+	privTemplate := []*p11.Attribute{
+		p11.NewAttribute(p11.CKA_TOKEN, true),
+		p11.NewAttribute(p11.CKA_PRIVATE, true),
+		p11.NewAttribute(p11.CKA_SIGN, true),
+		p11.NewAttribute(p11.CKA_SENSITIVE, true),
+		p11.NewAttribute(p11.CKA_EXTRACTABLE, false),
+		p11.NewAttribute(p11.CKA_ID, id),
+		p11.NewAttribute(p11.CKA_LABEL, label),
+	}
+	var mech uint
+	switch keyType.Family() {
+	case data.KeyTypeRSA:
+		mech = p11.CKM_RSA_PKCS_KEY_PAIR_GEN
+		pubTemplate = append(pubTemplate,
+			p11.NewAttribute(p11.CKA_MODULUS_BITS, keyType.RSABits()),
+			p11.NewAttribute(p11.CKA_PUBLIC_EXPONENT, []byte{0x01, 0x00, 0x01}))
+	case data.KeyTypeECDSA:
+		mech = p11.CKM_EC_KEY_PAIR_GEN
+		pubTemplate = append(pubTemplate, p11.NewAttribute(p11.CKA_EC_PARAMS, ecdsaCurveOIDs[keyType]))
+	case data.KeyTypeEd25519:
+		mech = ckmECEdwardsKeyPairGen
+		pubTemplate = append(pubTemplate, p11.NewAttribute(p11.CKA_EC_PARAMS, oidEd25519))
+	default:
+		return nil, "", apperrors.NewAppError(apperrors.ErrorDataValidation,
+			"key type '"+string(keyType)+"' is not supported by PKCS#11 backend")
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	pubHandle, _, err := b.ctx.GenerateKeyPair(b.session,
+		[]*p11.Mechanism{p11.NewMechanism(mech, nil)}, pubTemplate, privTemplate)
+	if err != nil {
+		return nil, "", backendError("failed to generate key pair", err)
+	}
+	pub, err := b.publicKey(pubHandle, keyType)
+	if err != nil {
+		return nil, "", err
+	}
+	return pub, hex.EncodeToString(id), nil
+}
+
+// Signer returns signer of the token key referenced by hex encoded CKA_ID
+func (b *Backend) Signer(ref string, pub *data.Key) (encryption.Signer, error) {
+	id, err := hex.DecodeString(ref)
+	if err != nil {
+		return nil, apperrors.CreateError(apperrors.ErrorDataValidation, "invalid PKCS#11 key reference", err)
+	}
+	switch pub.Type.Family() {
+	case data.KeyTypeRSA, data.KeyTypeECDSA, data.KeyTypeEd25519:
+	default:
+		return nil, apperrors.NewAppError(apperrors.ErrorDataValidation,
+			"key type '"+string(pub.Type)+"' is not supported by PKCS#11 backend")
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	handle, err := b.findPrivateKey(id)
+	if err != nil {
+		return nil, err
+	}
+	return &signer{backend: b, handle: handle, keyType: pub.Type}, nil
+}
+
+// signer signs messages with private key kept in the token
+type signer struct {
+	backend *Backend
+	handle  p11.ObjectHandle
+	keyType data.KeyType
+}
+
+// SignMessage signs a message with the private key.
+func (s *signer) SignMessage(message []byte) ([]byte, error) {
+	switch s.keyType.Family() {
+	case data.KeyTypeRSA:
+		params := p11.NewPSSParams(p11.CKM_SHA256, p11.CKG_MGF1_SHA256, sha256.Size)
+		return s.backend.sign(s.handle, p11.NewMechanism(p11.CKM_SHA256_RSA_PKCS_PSS, params), message)
+	case data.KeyTypeECDSA:
+		_, hashFunc, err := encryption.ECDSACurve(s.keyType)
+		if err != nil {
+			return nil, err
+		}
+		h := hashFunc.New()
+		h.Write(message)
+		sig, err := s.backend.sign(s.handle, p11.NewMechanism(p11.CKM_ECDSA, nil), h.Sum(nil))
+		if err != nil {
+			return nil, err
+		}
+		// PKCS#11 returns r||s, signatures of encryption.ECDSAKey are ASN.1 encoded
+		return ECDSASignatureASN1(sig)
+	default:
+		return s.backend.sign(s.handle, p11.NewMechanism(ckmEdDSA, nil), message)
+	}
+}
+
+func (b *Backend) sign(handle p11.ObjectHandle, mech *p11.Mechanism, message []byte) ([]byte, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err := b.ctx.SignInit(b.session, []*p11.Mechanism{mech}, handle); err != nil {
+		return nil, backendError("failed to sign message", err)
+	}
+	sig, err := b.ctx.Sign(b.session, message)
+	if err != nil {
+		return nil, backendError("failed to sign message", err)
+	}
+	return sig, nil
+}
+
+// findSlot returns slot of the token with the label
+func (b *Backend) findSlot(label string) (uint, error) {
+	slots, err := b.ctx.GetSlotList(true)
+	if err != nil {
+		return 0, backendError("failed to list PKCS#11 slots", err)
+	}
+	for _, slot := range slots {
+		info, err := b.ctx.GetTokenInfo(slot)
+		if err != nil {
+			return 0, backendError("failed to get PKCS#11 token info", err)
+		}
+		if strings.TrimSpace(info.Label) == label {
+			return slot, nil
+		}
+	}
+	return 0, apperrors.NewAppError(errcodes.ErrorDataSigningBackend,
+		"PKCS#11 token '"+label+"' is not found")
+}
+
+// findPrivateKey returns handle of the private key with the CKA_ID
+func (b *Backend) findPrivateKey(id []byte) (p11.ObjectHandle, error) {
+	template := []*p11.Attribute{
+		p11.NewAttribute(p11.CKA_CLASS, p11.CKO_PRIVATE_KEY),
+		p11.NewAttribute(p11.CKA_ID, id),
+	}
+	if err := b.ctx.FindObjectsInit(b.session, template); err != nil {
+		return 0, backendError("failed to find key", err)
+	}
+	handles, _, err := b.ctx.FindObjects(b.session, 1)
+	if finErr := b.ctx.FindObjectsFinal(b.session); err == nil {
+		err = finErr
+	}
+	if err != nil {
+		return 0, backendError("failed to find key", err)
+	}
+	if len(handles) == 0 {
+		return 0, apperrors.NewAppError(errcodes.ErrorDataSigningNoPrivateKey,
+			"key '"+hex.EncodeToString(id)+"' is not found in PKCS#11 token")
+	}
+	return handles[0], nil
+}
+
+// publicKey reads public key object of the key pair
+func (b *Backend) publicKey(handle p11.ObjectHandle, keyType data.KeyType) (*data.Key, error) {
+	if keyType.Family() == data.KeyTypeRSA {
+		attrs, err := b.ctx.GetAttributeValue(b.session, handle, []*p11.Attribute{
+			p11.NewAttribute(p11.CKA_MODULUS, nil),
+			p11.NewAttribute(p11.CKA_PUBLIC_EXPONENT, nil),
+		})
+		if err != nil {
+			return nil, backendError("failed to read public key", err)
+		}
+		return encryption.NewPublicKey(&rsa.PublicKey{
+			N: new(big.Int).SetBytes(attrs[0].Value),
+			E: int(new(big.Int).SetBytes(attrs[1].Value).Int64()),
+		})
+	}
+	attrs, err := b.ctx.GetAttributeValue(b.session, handle, []*p11.Attribute{
+		p11.NewAttribute(p11.CKA_EC_POINT, nil),
+	})
+	if err != nil {
+		return nil, backendError("failed to read public key", err)
+	}
+	return PublicKeyFromECPoint(keyType, attrs[0].Value)
+}
+
+func (b *Backend) destroy() {
+	_ = b.ctx.Finalize()
+	b.ctx.Destroy()
+}
+
+func backendError(msg string, err error) error {
+	return apperrors.CreateError(errcodes.ErrorDataSigningBackend, msg, err)
+}
@@ -0,0 +1,87 @@
+//go:build pkcs11
+
+package pkcs11_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/shuvava/ota-tuf-server/pkg/data"
+	"github.com/shuvava/ota-tuf-server/pkg/encryption"
+	"github.com/shuvava/ota-tuf-server/pkg/encryption/pkcs11"
+)
+
+// newTestBackend connects to token configured by environment variables, e.g. SoftHSM:
+//
+//	softhsm2-util --init-token --free --label tuf --pin 1234 --so-pin 1234
+//	PKCS11_MODULE=/usr/lib/softhsm/libsofthsm2.so PKCS11_TOKEN_LABEL=tuf PKCS11_PIN=1234 go test -tags pkcs11 ./pkg/encryption/pkcs11
+func newTestBackend(t *testing.T) *pkcs11.Backend {
+	module := os.Getenv("PKCS11_MODULE")
+	if module == "" {
+		t.Skip("PKCS11_MODULE is not set")
+	}
+	backend, err := pkcs11.New(pkcs11.Config{
+		Module:     module,
+		TokenLabel: os.Getenv("PKCS11_TOKEN_LABEL"),
+		Pin:        os.Getenv("PKCS11_PIN"),
+	})
+	if err != nil {
+		t.Fatalf("unable to connect to token: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := backend.Close(); err != nil {
+			t.Errorf("unable to close backend: %v", err)
+		}
+	})
+	return backend
+}
+
+func TestBackend(t *testing.T) {
+	backend := newTestBackend(t)
+	encryption.RegisterBackend(pkcs11.BackendName, backend)
+	repoID := data.NewRepoID()
+	keyTypes := []data.KeyType{
+		data.KeyTypeEd25519,
+		data.KeyTypeECDSA, data.KeyTypeECDSAP384, data.KeyTypeECDSAP521,
+		data.KeyTypeRSA, data.KeyTypeRSA3072, data.KeyTypeRSA4096,
+	}
+	for _, keyType := range keyTypes {
+		t.Run("should sign payload with "+string(keyType)+" token key", func(t *testing.T) {
+			pub, ref, err := backend.GenerateKey(keyType, repoID.String()+"/root")
+			if err != nil {
+				t.Fatalf("unable to generate key: %v", err)
+			}
+			if encryption.HasPrivateKey(pub) {
+				t.Fatalf("generated key contains private part")
+			}
+			keyID, err := encryption.ComputeKeyID(pub)
+			if err != nil {
+				t.Fatalf("unable to compute key id: %v", err)
+			}
+			key := data.RepoKey{
+				RepoID: repoID,
+				Role:   data.RoleTypeRoot,
+				KeyID:  keyID,
+				Key:    *pub,
+				Handle: &data.KeyHandle{Backend: pkcs11.BackendName, Ref: ref},
+			}
+			signed, err := encryption.SignPayload(map[string]string{"foo": "bar"}, []data.RepoKey{key})
+			if err != nil {
+				t.Fatalf("unable to sign payload: %v", err)
+			}
+			role := &data.RoleKeys{KeyIDs: []data.KeyID{keyID}, Threshold: 1}
+			if err = encryption.VerifyPayload(signed, map[data.KeyID]data.Key{keyID: *pub}, role); err != nil {
+				t.Errorf("signature verification failed: %v", err)
+			}
+		})
+	}
+	t.Run("should fail on unknown key reference", func(t *testing.T) {
+		pub, _, err := backend.GenerateKey(data.KeyTypeEd25519, repoID.String()+"/root")
+		if err != nil {
+			t.Fatalf("unable to generate key: %v", err)
+		}
+		if _, err = backend.Signer("00112233445566778899aabbccddeeff", pub); err == nil {
+			t.Errorf("expected error")
+		}
+	})
+}
@@ -0,0 +1,154 @@
+package remote
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/shuvava/go-ota-svc-common/apperrors"
+
+	"github.com/shuvava/ota-tuf-server/pkg/data"
+	"github.com/shuvava/ota-tuf-server/pkg/encryption"
+	"github.com/shuvava/ota-tuf-server/pkg/errcodes"
+)
+
+const (
+	// clientTimeout is the timeout of a single signer request, RSA key generation is the slowest operation
+	clientTimeout = 30 * time.Second
+	// socketHost is the fake host of requests sent over Unix socket
+	socketHost = "http://signer"
+)
+
+// Client is encryption.Backend delegating key generation and signing to remote signer
+type Client struct {
+	http    *http.Client
+	baseURL string
+}
+
+// NewClient creates Client connecting to signer listening on the Unix socket
+func NewClient(socketPath string) *Client {
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", socketPath)
+		},
+	}
+	return NewHTTPClient(&http.Client{Transport: transport, Timeout: clientTimeout}, socketHost)
+}
+
+// NewHTTPClient creates Client sending requests to signer base URL by the HTTP client
+func NewHTTPClient(client *http.Client, baseURL string) *Client {
+	return &Client{
+		http:    client,
+		baseURL: baseURL,
+	}
+}
+
+// Close releases idle connections to signer
+func (c *Client) Close() error {
+	c.http.CloseIdleConnections()
+	return nil
+}
+
+// GenerateKey creates a new key of the type in signer
+func (c *Client) GenerateKey(keyType data.KeyType, label string) (*data.Key, string, error) {
+	var resp keyResponse
+	if err := c.do(http.MethodPost, pathKeys, generateKeyRequest{KeyType: keyType, Label: label}, &resp); err != nil {
+		return nil, "", err
+	}
+	return &resp.Key, resp.Ref, nil
+}
+
+// Signer returns remote key referenced by the signer key reference
+func (c *Client) Signer(ref string, pub *data.Key) (encryption.Signer, error) {
+	return c.Key(ref, pub)
+}
+
+// Key returns remote key referenced by the signer key reference,
+// public part of the key is requested from signer if pub is nil
+func (c *Client) Key(ref string, pub *data.Key) (*Key, error) {
+	if pub == nil {
+		var resp keyResponse
+		if err := c.do(http.MethodGet, pathKeys+"/"+ref, nil, &resp); err != nil {
+			return nil, err
+		}
+		pub = &resp.Key
+	}
+	verifier, err := encryption.UnmarshalKey(pub)
+	if err != nil {
+		return nil, err
+	}
+	return &Key{client: c, ref: ref, pub: verifier}, nil
+}
+
+// do sends request to signer and decodes response
+func (c *Client) do(method, path string, req, resp interface{}) error {
+	var body bytes.Buffer
+	if req != nil {
+		if err := json.NewEncoder(&body).Encode(req); err != nil {
+			return apperrors.CreateError(apperrors.ErrorDataSerialization, "failed to encode signer request", err)
+		}
+	}
+	httpReq, err := http.NewRequest(method, c.baseURL+path, &body)
+	if err != nil {
+		return apperrors.CreateError(errcodes.ErrorDataSigningBackend, "failed to create signer request", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpResp, err := c.http.Do(httpReq)
+	if err != nil {
+		return apperrors.CreateError(errcodes.ErrorDataSigningBackend, "signer request failed", err)
+	}
+	defer httpResp.Body.Close()
+	if httpResp.StatusCode != http.StatusOK {
+		var errResp errorResponse
+		if err = json.NewDecoder(httpResp.Body).Decode(&errResp); err != nil || errResp.Code == "" {
+			return apperrors.NewAppError(errcodes.ErrorDataSigningBackend,
+				"signer request failed with status "+httpResp.Status)
+		}
+		return apperrors.NewAppError(apperrors.AppErrorCode(errResp.Code), errResp.Message)
+	}
+	if err = json.NewDecoder(httpResp.Body).Decode(resp); err != nil {
+		return apperrors.CreateError(apperrors.ErrorDataSerialization, "failed to decode signer response", err)
+	}
+	return nil
+}
+
+// Key is the key held by remote signer, it implements encryption.Signer and encryption.Verifier
+type Key struct {
+	client *Client
+	ref    string
+	pub    encryption.Verifier
+}
+
+// Ref returns signer reference of the key
+func (k *Key) Ref() string {
+	return k.ref
+}
+
+// SignMessage signs a message with the private key held by signer.
+func (k *Key) SignMessage(message []byte) ([]byte, error) {
+	var resp signResponse
+	if err := k.client.do(http.MethodPost, pathKeys+"/"+k.ref+"/"+pathSign, signRequest{Message: message}, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Signature, nil
+}
+
+// MarshalPublicData returns the data.Key object associated with the verifier contains only public key.
+func (k *Key) MarshalPublicData() (*data.Key, error) {
+	return k.pub.MarshalPublicData()
+}
+
+// Public this is the public string used as a unique identifier for the verifier instance.
+func (k *Key) Public() string {
+	return k.pub.Public()
+}
+
+// Verify takes a message and signature and verifies them by the public key,
+// verification does not depend on availability of signer.
+func (k *Key) Verify(msg, sig []byte) error {
+	return k.pub.Verify(msg, sig)
+}
@@ -0,0 +1,11 @@
+// Package remote implements remote signer protocol allowing to keep private keys in a separate signer process.
+//
+// The protocol is JSON over HTTP, the signer daemon listens on a Unix socket:
+//
+//	POST /v1/keys            generates a new key, returns key reference and public part of the key
+//	GET  /v1/keys/{ref}      returns public part of the key
+//	POST /v1/keys/{ref}/sign signs base64 encoded message
+//
+// Client implements encryption.Backend, Handler serves the protocol on top of KeyStore.
+// Signatures are verified by the public part of the key without requests to signer.
+package remote
@@ -0,0 +1,153 @@
+package remote
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/shuvava/go-logging/logger"
+	"github.com/shuvava/go-ota-svc-common/apperrors"
+
+	"github.com/shuvava/ota-tuf-server/pkg/encryption"
+	"github.com/shuvava/ota-tuf-server/pkg/errcodes"
+)
+
+// maxRequestSize limits size of request body, signed metadata is much smaller
+const maxRequestSize = 16 << 20
+
+// Handler serves remote signer protocol, private keys never leave the handler
+type Handler struct {
+	log   logger.Logger
+	store KeyStore
+}
+
+// NewHandler creates Handler on top of the key store
+func NewHandler(l logger.Logger, store KeyStore) *Handler {
+	return &Handler{
+		log:   l.SetOperation("RemoteSigner"),
+		store: store,
+	}
+}
+
+// ServeHTTP dispatches remote signer protocol requests
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxRequestSize)
+	path := strings.TrimPrefix(r.URL.Path, pathKeys)
+	if path == r.URL.Path {
+		h.writeError(w, apperrors.NewAppError(apperrors.ErrorDbNoDocumentFound, "unknown path '"+r.URL.Path+"'"))
+		return
+	}
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	switch {
+	case len(parts) == 1 && parts[0] == "" && r.Method == http.MethodPost:
+		h.generateKey(w, r)
+	case len(parts) == 1 && parts[0] != "" && r.Method == http.MethodGet:
+		h.getKey(w, parts[0])
+	case len(parts) == 2 && parts[1] == pathSign && r.Method == http.MethodPost:
+		h.sign(w, r, parts[0])
+	default:
+		h.writeError(w, apperrors.NewAppError(apperrors.ErrorDbNoDocumentFound,
+			"unknown operation "+r.Method+" '"+r.URL.Path+"'"))
+	}
+}
+
+func (h *Handler) generateKey(w http.ResponseWriter, r *http.Request) {
+	var req generateKeyRequest
+	if err := h.readRequest(r, &req); err != nil {
+		h.writeError(w, err)
+		return
+	}
+	ref, err := h.store.Create(req.KeyType)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	h.log.WithField("Ref", ref).
+		WithField("KeyType", req.KeyType).
+		WithField("Label", req.Label).
+		Info("Key generated")
+	h.getKey(w, ref)
+}
+
+func (h *Handler) getKey(w http.ResponseWriter, ref string) {
+	key, err := h.store.Get(ref)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	pub, err := encryption.PublicKey(key)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	h.writeResponse(w, keyResponse{Ref: ref, Key: *pub})
+}
+
+func (h *Handler) sign(w http.ResponseWriter, r *http.Request, ref string) {
+	var req signRequest
+	if err := h.readRequest(r, &req); err != nil {
+		h.writeError(w, err)
+		return
+	}
+	key, err := h.store.Get(ref)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	signer, err := encryption.UnmarshalSigner(key)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	sig, err := signer.SignMessage(req.Message)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	h.log.WithField("Ref", ref).
+		Debug("Message signed")
+	h.writeResponse(w, signResponse{Signature: sig})
+}
+
+func (h *Handler) readRequest(r *http.Request, req interface{}) error {
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		return apperrors.CreateError(apperrors.ErrorDataSerialization, "failed to decode request", err)
+	}
+	return nil
+}
+
+func (h *Handler) writeResponse(w http.ResponseWriter, resp interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.log.WithError(err).
+			Warn("Failed to write response")
+	}
+}
+
+// writeError sends error response with HTTP status code matching to the error code
+func (h *Handler) writeError(w http.ResponseWriter, err error) {
+	resp := errorResponse{
+		Code:    string(errcodes.ErrorDataSigningBackend),
+		Message: err.Error(),
+	}
+	var typedErr apperrors.AppError
+	if errors.As(err, &typedErr) {
+		resp.Code = string(typedErr.ErrorCode)
+		resp.Message = typedErr.Description
+	}
+	status := http.StatusInternalServerError
+	switch {
+	case strings.HasPrefix(resp.Code, apperrors.ErrorDbNoDocumentFound):
+		status = http.StatusNotFound
+	case strings.HasPrefix(resp.Code, apperrors.ErrorDataValidation),
+		strings.HasPrefix(resp.Code, apperrors.ErrorDataSerialization):
+		status = http.StatusBadRequest
+	default:
+		h.log.WithError(err).
+			Error("Request failed")
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,39 @@
+package remote
+
+import (
+	"github.com/shuvava/ota-tuf-server/pkg/data"
+)
+
+// BackendName is the name of remote signer key backend
+const BackendName = data.KeyBackend("remote")
+
+const (
+	pathKeys = "/v1/keys"
+	pathSign = "sign"
+)
+
+type (
+	// generateKeyRequest is the body of key generation request
+	generateKeyRequest struct {
+		KeyType data.KeyType `json:"keyType"`
+		Label   string       `json:"label,omitempty"`
+	}
+	// keyResponse is the public part of the key held by signer
+	keyResponse struct {
+		Ref string   `json:"ref"`
+		Key data.Key `json:"key"`
+	}
+	// signRequest is the body of signing request
+	signRequest struct {
+		Message []byte `json:"message"`
+	}
+	// signResponse is the response of signing request
+	signResponse struct {
+		Signature []byte `json:"signature"`
+	}
+	// errorResponse is the response of failed request
+	errorResponse struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	}
+)
@@ -0,0 +1,133 @@
+package remote_test
+
+import (
+	"net"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/shuvava/go-logging/logger"
+
+	"github.com/shuvava/ota-tuf-server/pkg/data"
+	"github.com/shuvava/ota-tuf-server/pkg/encryption"
+	"github.com/shuvava/ota-tuf-server/pkg/encryption/remote"
+)
+
+// newTestSigner starts signer on Unix socket in temporary directory and returns its client
+// and the function stopping signer
+func newTestSigner(t *testing.T) (*remote.Client, string, func()) {
+	t.Helper()
+	dir := t.TempDir()
+	keysDir := filepath.Join(dir, "keys")
+	store, err := remote.NewFileKeyStore(keysDir)
+	if err != nil {
+		t.Fatalf("unable to create key store: %v", err)
+	}
+	socket := filepath.Join(dir, "signer.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatalf("unable to listen on socket: %v", err)
+	}
+	server := &http.Server{Handler: remote.NewHandler(logger.NewNopLogger(), store)}
+	go func() { _ = server.Serve(listener) }()
+	client := remote.NewClient(socket)
+	stop := func() {
+		_ = client.Close()
+		_ = server.Close()
+	}
+	t.Cleanup(stop)
+	return client, keysDir, stop
+}
+
+func TestRemoteSigner(t *testing.T) {
+	client, keysDir, _ := newTestSigner(t)
+	repoID := data.NewRepoID()
+	for _, keyType := range []data.KeyType{data.KeyTypeEd25519, data.KeyTypeECDSA, data.KeyTypeRSA} {
+		t.Run("should sign payload with remote "+string(keyType)+" key", func(t *testing.T) {
+			pub, ref, err := client.GenerateKey(keyType, repoID.String()+"/root")
+			if err != nil {
+				t.Fatalf("unable to generate key: %v", err)
+			}
+			if pub.Type != keyType || encryption.HasPrivateKey(pub) {
+				t.Fatalf("expected public %s key, got %s", keyType, pub.Type)
+			}
+			keyID, err := encryption.ComputeKeyID(pub)
+			if err != nil {
+				t.Fatalf("unable to compute key id: %v", err)
+			}
+			encryption.RegisterBackend(remote.BackendName, client)
+			key := data.RepoKey{
+				RepoID: repoID,
+				Role:   data.RoleTypeRoot,
+				KeyID:  keyID,
+				Key:    *pub,
+				Handle: &data.KeyHandle{Backend: remote.BackendName, Ref: ref},
+			}
+			signed, err := encryption.SignPayload(map[string]string{"foo": "bar"}, []data.RepoKey{key})
+			if err != nil {
+				t.Fatalf("unable to sign payload: %v", err)
+			}
+			role := &data.RoleKeys{KeyIDs: []data.KeyID{keyID}, Threshold: 1}
+			if err = encryption.VerifyPayload(signed, map[data.KeyID]data.Key{keyID: *pub}, role); err != nil {
+				t.Errorf("signature verification failed: %v", err)
+			}
+		})
+	}
+	t.Run("remote key should verify signatures without signer", func(t *testing.T) {
+		client, _, stop := newTestSigner(t)
+		_, ref, err := client.GenerateKey(data.KeyTypeEd25519, "")
+		if err != nil {
+			t.Fatalf("unable to generate key: %v", err)
+		}
+		key, err := client.Key(ref, nil)
+		if err != nil {
+			t.Fatalf("unable to get key: %v", err)
+		}
+		var verifier encryption.Verifier = key
+		msg := []byte("message")
+		sig, err := key.SignMessage(msg)
+		if err != nil {
+			t.Fatalf("unable to sign message: %v", err)
+		}
+		stop()
+		if _, err = key.SignMessage(msg); err == nil {
+			t.Fatal("expected signer unavailable, got nil")
+		}
+		if err = verifier.Verify(msg, sig); err != nil {
+			t.Errorf("expected valid signature, got %v", err)
+		}
+		if err = verifier.Verify([]byte("other"), sig); err == nil {
+			t.Error("expected verification error, got nil")
+		}
+	})
+	t.Run("keys should survive signer restart", func(t *testing.T) {
+		_, ref, err := client.GenerateKey(data.KeyTypeECDSA, "")
+		if err != nil {
+			t.Fatalf("unable to generate key: %v", err)
+		}
+		store, err := remote.NewFileKeyStore(keysDir)
+		if err != nil {
+			t.Fatalf("unable to open key store: %v", err)
+		}
+		key, err := store.Get(ref)
+		if err != nil {
+			t.Fatalf("unable to get key: %v", err)
+		}
+		if !encryption.HasPrivateKey(key) {
+			t.Error("stored key should contain private part")
+		}
+	})
+	t.Run("should fail for unknown key", func(t *testing.T) {
+		if _, err := client.Key("00112233445566778899aabbccddeeff", nil); err == nil {
+			t.Error("expected error, got nil")
+		}
+		if _, err := client.Key("../keys", nil); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+	t.Run("should fail for unsupported key type", func(t *testing.T) {
+		if _, _, err := client.GenerateKey("dsa", ""); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+}
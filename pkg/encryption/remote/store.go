@@ -0,0 +1,96 @@
+package remote
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+
+	"github.com/shuvava/go-ota-svc-common/apperrors"
+
+	"github.com/shuvava/ota-tuf-server/pkg/data"
+	"github.com/shuvava/ota-tuf-server/pkg/encryption"
+	"github.com/shuvava/ota-tuf-server/pkg/errcodes"
+)
+
+const refSize = 16
+
+var refRegexp = regexp.MustCompile(`^[0-9a-f]{32}$`)
+
+// KeyStore keeps private keys of the signer
+type KeyStore interface {
+	// Create generates a new key of the type and returns its reference
+	Create(keyType data.KeyType) (string, error)
+	// Get returns key including private part by the reference
+	Get(ref string) (*data.Key, error)
+}
+
+// FileKeyStore is KeyStore keeping every key in a separate file of the directory
+type FileKeyStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewFileKeyStore creates FileKeyStore in the directory, the directory is created if it does not exist
+func NewFileKeyStore(dir string) (*FileKeyStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, apperrors.CreateError(apperrors.ErrorFsIOOpen, "failed to create key store directory", err)
+	}
+	return &FileKeyStore{dir: dir}, nil
+}
+
+// Create generates a new key of the type and returns its reference
+func (s *FileKeyStore) Create(keyType data.KeyType) (string, error) {
+	key, err := encryption.NewKey(keyType)
+	if err != nil {
+		return "", err
+	}
+	dtKey, err := key.MarshalAllData()
+	if err != nil {
+		return "", err
+	}
+	b, err := json.Marshal(dtKey)
+	if err != nil {
+		return "", apperrors.CreateError(apperrors.ErrorDataSerialization, "failed to marshal key", err)
+	}
+	id := make([]byte, refSize)
+	if _, err = rand.Read(id); err != nil {
+		return "", apperrors.CreateError(errcodes.ErrorDataSigningBackend, "failed to generate key reference", err)
+	}
+	ref := hex.EncodeToString(id)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err = ioutil.WriteFile(s.path(ref), b, 0600); err != nil {
+		return "", apperrors.CreateError(errcodes.ErrorDataSigningBackend, "failed to save key", err)
+	}
+	return ref, nil
+}
+
+// Get returns key including private part by the reference
+func (s *FileKeyStore) Get(ref string) (*data.Key, error) {
+	if !refRegexp.MatchString(ref) {
+		return nil, apperrors.NewAppError(apperrors.ErrorDataValidation, "invalid key reference")
+	}
+	s.mu.Lock()
+	b, err := ioutil.ReadFile(s.path(ref))
+	s.mu.Unlock()
+	if os.IsNotExist(err) {
+		return nil, apperrors.NewAppError(apperrors.ErrorDbNoDocumentFound, "key '"+ref+"' is not found")
+	}
+	if err != nil {
+		return nil, apperrors.CreateError(errcodes.ErrorDataSigningBackend, "failed to read key", err)
+	}
+	var key data.Key
+	if err = json.Unmarshal(b, &key); err != nil {
+		return nil, apperrors.CreateError(apperrors.ErrorDataSerialization, "failed to unmarshal key", err)
+	}
+	return &key, nil
+}
+
+func (s *FileKeyStore) path(ref string) string {
+	return filepath.Join(s.dir, ref+".json")
+}
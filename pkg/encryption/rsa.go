@@ -8,6 +8,7 @@ import (
 	"crypto/x509"
 	"encoding/json"
 	"encoding/pem"
+	"fmt"
 
 	"github.com/shuvava/go-ota-svc-common/apperrors"
 
@@ -25,22 +26,32 @@ type RSAKey struct {
 	keyType    data.KeyType
 }
 
-// GenerateRSAKey generates a new rsa private key and returns it
+// GenerateRSAKey generates a new 2048-bit rsa private key and returns it
 func GenerateRSAKey() (*RSAKey, error) {
-	private, err := rsa.GenerateKey(rand.Reader, 2048)
+	return GenerateRSAKeyOfType(data.KeyTypeRSA)
+}
+
+// GenerateRSAKeyOfType generates a new rsa private key of the size defined by the key type and returns it
+func GenerateRSAKeyOfType(keyType data.KeyType) (*RSAKey, error) {
+	bits := keyType.RSABits()
+	if bits == 0 {
+		return nil, apperrors.NewAppError(apperrors.ErrorDataValidation, "unsupported rsa key type: "+string(keyType))
+	}
+	private, err := rsa.GenerateKey(rand.Reader, bits)
 	if err != nil {
 		return nil, apperrors.CreateError(errcodes.ErrorDataSerializationRSAKey, "failed to generate key: ", err)
 	}
 	key := RSAKey{
 		PrivateKey: private,
 		PublicKey:  private.Public().(*rsa.PublicKey),
+		keyType:    keyType,
 	}
 	return &key, nil
 }
 
 // Type returns the type of key.
 func (k *RSAKey) Type() data.KeyType {
-	return data.KeyTypeRSA
+	return k.keyType
 }
 
 // MarshalPublicData returns the data.Key object associated with the verifier contains only public key.
@@ -74,7 +85,9 @@ func (k *RSAKey) Public() string {
 // SignMessage signs a message with the private key.
 func (k *RSAKey) SignMessage(message []byte) ([]byte, error) {
 	hash := sha256.Sum256(message)
-	keySig, err := rsa.SignPSS(rand.Reader, k.PrivateKey, crypto.SHA256, hash[:], &rsa.PSSOptions{})
+	// salt length equal to the hash length is expected by Aktualizr and python-tuf
+	opts := rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash}
+	keySig, err := rsa.SignPSS(rand.Reader, k.PrivateKey, crypto.SHA256, hash[:], &opts)
 	if err != nil {
 		return nil, apperrors.CreateError(errcodes.ErrorDataSerializationRSAKey, "failed to sign message: ", err)
 	}
@@ -107,9 +120,13 @@ func UnmarshalRSAKey(key *data.Key) (*RSAKey, error) {
 	if err != nil {
 		return nil, apperrors.CreateError(errcodes.ErrorDataValidationRSAKey, "failed to unmarshal public key: ", err)
 	}
+	rsaPublicKey, ok := publicKey.(*rsa.PublicKey)
+	if !ok {
+		return nil, apperrors.NewAppError(errcodes.ErrorDataValidationRSAKey, "public key is not rsa key")
+	}
 	rsaKey := RSAKey{
-		PublicKey: publicKey.(*rsa.PublicKey),
-		keyType:   data.KeyTypeRSA,
+		PublicKey: rsaPublicKey,
+		keyType:   key.Type,
 	}
 
 	block, _ = pem.Decode(kv.Private)
@@ -132,6 +149,11 @@ func VerifyRSAKey(v *RSAKey) error {
 	if v.PublicKey == nil {
 		return apperrors.NewAppError(errcodes.ErrorDataValidationRSAKey, "public key is nil")
 	}
+	// size of keys of legacy `rsa` type is not restricted
+	if v.keyType != data.KeyTypeRSA && v.PublicKey.N.BitLen() != v.keyType.RSABits() {
+		return apperrors.NewAppError(errcodes.ErrorDataValidationRSAKey,
+			fmt.Sprintf("key size %d does not match key type %s", v.PublicKey.N.BitLen(), v.keyType))
+	}
 	return nil
 }
 
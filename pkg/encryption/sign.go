@@ -0,0 +1,75 @@
+package encryption
+
+import (
+	"encoding/json"
+
+	"github.com/shuvava/go-ota-svc-common/apperrors"
+
+	"github.com/shuvava/ota-tuf-server/pkg/cjson"
+	"github.com/shuvava/ota-tuf-server/pkg/data"
+	"github.com/shuvava/ota-tuf-server/pkg/errcodes"
+)
+
+// SignPayload signs canonical JSON form of payload with every provided repository key.
+// It is the only way signed portion of TUF metadata should be produced.
+func SignPayload(payload interface{}, keys []data.RepoKey) (*data.SignedPayload, error) {
+	return SignPayloadWithEncoding(payload, keys, data.KeyEncodingTUF)
+}
+
+// SignPayloadWithEncoding signs canonical JSON form of payload with every provided repository key,
+// signature methods are named by the key encoding
+func SignPayloadWithEncoding(payload interface{}, keys []data.RepoKey, enc data.KeyEncoding) (*data.SignedPayload, error) {
+	signed, err := json.Marshal(payload)
+	if err != nil {
+		return nil, apperrors.CreateError(errcodes.ErrorDataSigning, "failed to marshal payload: ", err)
+	}
+	sigs, err := SignJSONWithEncoding(signed, keys, enc)
+	if err != nil {
+		return nil, err
+	}
+	return &data.SignedPayload{
+		Signatures: sigs,
+		Signed:     signed,
+	}, nil
+}
+
+// SignJSON signs canonical form of JSON document with every provided repository key
+func SignJSON(doc []byte, keys []data.RepoKey) ([]data.Signature, error) {
+	return SignJSONWithEncoding(doc, keys, data.KeyEncodingTUF)
+}
+
+// SignJSONWithEncoding signs canonical form of JSON document with every provided repository key,
+// signature methods are named and keys are identified by the key encoding
+func SignJSONWithEncoding(doc []byte, keys []data.RepoKey, enc data.KeyEncoding) ([]data.Signature, error) {
+	msg, err := cjson.Canonicalize(doc)
+	if err != nil {
+		return nil, err
+	}
+	sigs := make([]data.Signature, 0, len(keys))
+	for _, key := range keys {
+		method, err := SignatureMethod(key.Key.Type, enc)
+		if err != nil {
+			return nil, err
+		}
+		keyID := key.KeyID
+		if !enc.IsNative() {
+			if keyID, err = EncodedKeyID(&key.Key, enc); err != nil {
+				return nil, err
+			}
+		}
+		signer, err := RepoKeySigner(&key)
+		if err != nil {
+			return nil, err
+		}
+		sig, err := signer.SignMessage(msg)
+		if err != nil {
+			return nil, err
+		}
+		sigs = append(sigs, data.Signature{
+			KeyID:     keyID,
+			Method:    method,
+			Signature: sig,
+		})
+	}
+	return sigs, nil
+}
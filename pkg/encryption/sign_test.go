@@ -0,0 +1,93 @@
+package encryption_test
+
+import (
+	"testing"
+
+	"github.com/shuvava/ota-tuf-server/pkg/cjson"
+	"github.com/shuvava/ota-tuf-server/pkg/data"
+	"github.com/shuvava/ota-tuf-server/pkg/encryption"
+)
+
+func TestSignPayload(t *testing.T) {
+	repoID := data.NewRepoID()
+	newRepoKey := func(t *testing.T, keyType data.KeyType, private bool) data.RepoKey {
+		key, err := encryption.NewKey(keyType)
+		if err != nil {
+			t.Fatalf("unable to generate key: %v", err)
+		}
+		dtKey, err := key.MarshalAllData()
+		if !private {
+			dtKey, err = key.(encryption.Verifier).MarshalPublicData()
+		}
+		if err != nil {
+			t.Fatalf("unable to marshal key: %v", err)
+		}
+		keyID, err := encryption.ComputeKeyID(dtKey)
+		if err != nil {
+			t.Fatalf("unable to compute key id: %v", err)
+		}
+		return data.RepoKey{
+			RepoID: repoID,
+			Role:   data.RoleTypeRoot,
+			KeyID:  keyID,
+			Key:    *dtKey,
+		}
+	}
+	for _, keyType := range []data.KeyType{data.KeyTypeEd25519, data.KeyTypeECDSA, data.KeyTypeRSA} {
+		t.Run("should sign payload with "+string(keyType)+" key", func(t *testing.T) {
+			key := newRepoKey(t, keyType, true)
+			signed, err := encryption.SignPayload(map[string]string{"foo": "bar"}, []data.RepoKey{key})
+			if err != nil {
+				t.Fatalf("unable to sign payload: %v", err)
+			}
+			if len(signed.Signatures) != 1 {
+				t.Fatalf("expected 1 signature, got %d", len(signed.Signatures))
+			}
+			sig := signed.Signatures[0]
+			if sig.Method != keyType.SignatureMethod() {
+				t.Errorf("expected method %s, got %s", keyType.SignatureMethod(), sig.Method)
+			}
+			verifier, err := encryption.UnmarshalKey(&key.Key)
+			if err != nil {
+				t.Fatalf("unable to unmarshal key: %v", err)
+			}
+			msg, err := cjson.Canonicalize(signed.Signed)
+			if err != nil {
+				t.Fatalf("unable to canonicalize payload: %v", err)
+			}
+			if err = verifier.Verify(msg, sig.Signature); err != nil {
+				t.Errorf("signature is invalid: %v", err)
+			}
+		})
+	}
+	t.Run("should sign JSON document canonical form", func(t *testing.T) {
+		key := newRepoKey(t, data.KeyTypeEd25519, true)
+		sigs, err := encryption.SignJSON([]byte(`{"b": 1, "a": [true]}`), []data.RepoKey{key})
+		if err != nil {
+			t.Fatalf("unable to sign document: %v", err)
+		}
+		verifier, _ := encryption.UnmarshalKey(&key.Key)
+		if err = verifier.Verify([]byte(`{"a":[true],"b":1}`), sigs[0].Signature); err != nil {
+			t.Errorf("signature is invalid: %v", err)
+		}
+		if sigs[0].KeyID != key.KeyID {
+			t.Errorf("expected key id %s, got %s", key.KeyID, sigs[0].KeyID)
+		}
+	})
+	t.Run("should detect private part of the key", func(t *testing.T) {
+		private := newRepoKey(t, data.KeyTypeECDSA, true)
+		public := newRepoKey(t, data.KeyTypeECDSA, false)
+		if !encryption.HasPrivateKey(&private.Key) {
+			t.Error("expected private key")
+		}
+		if encryption.HasPrivateKey(&public.Key) {
+			t.Error("expected no private key")
+		}
+	})
+	t.Run("should fail if key has no private part", func(t *testing.T) {
+		key := newRepoKey(t, data.KeyTypeEd25519, false)
+		if _, err := encryption.SignPayload(map[string]string{"foo": "bar"}, []data.RepoKey{key}); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+}
@@ -0,0 +1,3 @@
+// Package vault implements encryption.Backend keeping private keys in HashiCorp Vault transit secrets engine.
+// Keys are created as not exportable, signing and verification are done by transit HTTP API.
+package vault
@@ -0,0 +1,351 @@
+package vault
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shuvava/go-ota-svc-common/apperrors"
+
+	"github.com/shuvava/ota-tuf-server/pkg/data"
+	"github.com/shuvava/ota-tuf-server/pkg/encryption"
+	"github.com/shuvava/ota-tuf-server/pkg/errcodes"
+)
+
+// BackendName is the name of Vault transit key backend
+const BackendName = data.KeyBackend("vault")
+
+const (
+	defaultMount      = "transit"
+	defaultRSAKeySize = 2048
+	clientTimeout     = 30 * time.Second
+	keyNamePrefix     = "tuf-"
+	keyNameSize       = 16
+	signaturePrefix   = "vault:v"
+	hashAlgorithm     = "sha2-256"
+	rsaSignatureAlg   = "pss"
+	rsaSaltLength     = "hash"
+	ecdsaMarshalAlg   = "asn1"
+)
+
+// Config is Vault transit engine configuration
+type Config struct {
+	// Address is the Vault server address, e.g. https://vault:8200
+	Address string
+	// Token is the Vault token allowed to use transit keys
+	Token string
+	// Namespace is the Vault Enterprise namespace, optional
+	Namespace string
+	// Mount is the mount path of transit engine, default is `transit`
+	Mount string
+	// RSAKeySize is the size of generated keys of `rsa` type, 2048 (default) or 4096
+	RSAKeySize int
+}
+
+// Backend is encryption.Backend keeping keys in Vault transit engine
+type Backend struct {
+	cfg  Config
+	http *http.Client
+}
+
+type (
+	// createKeyRequest is the body of transit key creation request
+	createKeyRequest struct {
+		Type       string `json:"type"`
+		Exportable bool   `json:"exportable"`
+	}
+	// keyVersion is the version of transit key
+	keyVersion struct {
+		PublicKey string `json:"public_key"`
+	}
+	// readKeyResponse is the response of transit key read request
+	readKeyResponse struct {
+		Data struct {
+			Type          string                `json:"type"`
+			LatestVersion int                   `json:"latest_version"`
+			Keys          map[string]keyVersion `json:"keys"`
+		} `json:"data"`
+	}
+	// signRequest is the body of transit sign request
+	signRequest struct {
+		Input               []byte `json:"input"`
+		KeyVersion          int    `json:"key_version"`
+		HashAlgorithm       string `json:"hash_algorithm,omitempty"`
+		SignatureAlgorithm  string `json:"signature_algorithm,omitempty"`
+		MarshalingAlgorithm string `json:"marshaling_algorithm,omitempty"`
+		SaltLength          string `json:"salt_length,omitempty"`
+	}
+	// signResponse is the response of transit sign request
+	signResponse struct {
+		Data struct {
+			Signature string `json:"signature"`
+		} `json:"data"`
+	}
+	// verifyRequest is the body of transit verify request
+	verifyRequest struct {
+		Input               []byte `json:"input"`
+		Signature           string `json:"signature"`
+		HashAlgorithm       string `json:"hash_algorithm,omitempty"`
+		SignatureAlgorithm  string `json:"signature_algorithm,omitempty"`
+		MarshalingAlgorithm string `json:"marshaling_algorithm,omitempty"`
+	}
+	// verifyResponse is the response of transit verify request
+	verifyResponse struct {
+		Data struct {
+			Valid bool `json:"valid"`
+		} `json:"data"`
+	}
+	// errorResponse is the response of failed Vault request
+	errorResponse struct {
+		Errors []string `json:"errors"`
+	}
+)
+
+// New creates Backend using Vault transit engine
+func New(cfg Config) (*Backend, error) {
+	if cfg.Address == "" {
+		return nil, apperrors.NewAppError(apperrors.ErrorDataValidation, "Vault address is not set")
+	}
+	if cfg.Mount == "" {
+		cfg.Mount = defaultMount
+	}
+	if cfg.RSAKeySize == 0 {
+		cfg.RSAKeySize = defaultRSAKeySize
+	}
+	if cfg.RSAKeySize != 2048 && cfg.RSAKeySize != 4096 {
+		return nil, apperrors.NewAppError(apperrors.ErrorDataValidation,
+			"unsupported RSA key size "+strconv.Itoa(cfg.RSAKeySize))
+	}
+	cfg.Address = strings.TrimRight(cfg.Address, "/")
+	cfg.Mount = strings.Trim(cfg.Mount, "/")
+	return &Backend{
+		cfg:  cfg,
+		http: &http.Client{Timeout: clientTimeout},
+	}, nil
+}
+
+// Close releases idle connections to Vault
+func (b *Backend) Close() error {
+	b.http.CloseIdleConnections()
+	return nil
+}
+
+// GenerateKey creates a new not exportable transit key,
+// the reference of the key is `<name>:<version>` of transit key
+func (b *Backend) GenerateKey(keyType data.KeyType, _ string) (*data.Key, string, error) {
+	vaultType, err := b.vaultKeyType(keyType)
+	if err != nil {
+		return nil, "", err
+	}
+	id := make([]byte, keyNameSize)
+	if _, err = rand.Read(id); err != nil {
+		return nil, "", apperrors.CreateError(errcodes.ErrorDataSigningBackend, "failed to generate key name", err)
+	}
+	name := keyNamePrefix + hex.EncodeToString(id)
+	if err = b.do(http.MethodPost, "/keys/"+name, createKeyRequest{Type: vaultType}, nil); err != nil {
+		return nil, "", err
+	}
+	var resp readKeyResponse
+	if err = b.do(http.MethodGet, "/keys/"+name, nil, &resp); err != nil {
+		return nil, "", err
+	}
+	version := resp.Data.LatestVersion
+	pub, err := parsePublicKey(keyType, resp.Data.Keys[strconv.Itoa(version)].PublicKey)
+	if err != nil {
+		return nil, "", err
+	}
+	return pub, name + ":" + strconv.Itoa(version), nil
+}
+
+// Signer returns transit key referenced by `<name>:<version>`
+func (b *Backend) Signer(ref string, pub *data.Key) (encryption.Signer, error) {
+	return b.Key(ref, pub)
+}
+
+// Key returns transit key referenced by `<name>:<version>`
+func (b *Backend) Key(ref string, pub *data.Key) (*Key, error) {
+	idx := strings.LastIndex(ref, ":")
+	if idx < 0 {
+		return nil, apperrors.NewAppError(apperrors.ErrorDataValidation, "invalid Vault key reference '"+ref+"'")
+	}
+	version, err := strconv.Atoi(ref[idx+1:])
+	if err != nil || version < 1 {
+		return nil, apperrors.NewAppError(apperrors.ErrorDataValidation, "invalid Vault key reference '"+ref+"'")
+	}
+	verifier, err := encryption.UnmarshalKey(pub)
+	if err != nil {
+		return nil, err
+	}
+	return &Key{
+		backend: b,
+		name:    ref[:idx],
+		version: version,
+		keyType: pub.Type,
+		pub:     verifier,
+	}, nil
+}
+
+// do sends request to transit engine and decodes response
+func (b *Backend) do(method, path string, req, resp interface{}) error {
+	var body bytes.Buffer
+	if req != nil {
+		if err := json.NewEncoder(&body).Encode(req); err != nil {
+			return apperrors.CreateError(apperrors.ErrorDataSerialization, "failed to encode Vault request", err)
+		}
+	}
+	httpReq, err := http.NewRequest(method, b.cfg.Address+"/v1/"+b.cfg.Mount+path, &body)
+	if err != nil {
+		return apperrors.CreateError(errcodes.ErrorDataSigningBackend, "failed to create Vault request", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("X-Vault-Token", b.cfg.Token)
+	if b.cfg.Namespace != "" {
+		httpReq.Header.Set("X-Vault-Namespace", b.cfg.Namespace)
+	}
+	httpResp, err := b.http.Do(httpReq)
+	if err != nil {
+		return apperrors.CreateError(errcodes.ErrorDataSigningBackend, "Vault request failed", err)
+	}
+	defer httpResp.Body.Close()
+	if httpResp.StatusCode < 200 || httpResp.StatusCode > 299 {
+		var errResp errorResponse
+		msg := "Vault request failed with status " + httpResp.Status
+		if json.NewDecoder(httpResp.Body).Decode(&errResp) == nil && len(errResp.Errors) > 0 {
+			msg += ": " + strings.Join(errResp.Errors, "; ")
+		}
+		return apperrors.NewAppError(errcodes.ErrorDataSigningBackend, msg)
+	}
+	if resp == nil || httpResp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	if err = json.NewDecoder(httpResp.Body).Decode(resp); err != nil {
+		return apperrors.CreateError(apperrors.ErrorDataSerialization, "failed to decode Vault response", err)
+	}
+	return nil
+}
+
+// vaultKeyType returns transit key type matching to the key type
+func (b *Backend) vaultKeyType(keyType data.KeyType) (string, error) {
+	switch keyType {
+	case data.KeyTypeEd25519:
+		return "ed25519", nil
+	case data.KeyTypeECDSA:
+		return "ecdsa-p256", nil
+	case data.KeyTypeECDSAP384:
+		return "ecdsa-p384", nil
+	case data.KeyTypeECDSAP521:
+		return "ecdsa-p521", nil
+	case data.KeyTypeRSA:
+		return "rsa-" + strconv.Itoa(b.cfg.RSAKeySize), nil
+	case data.KeyTypeRSA3072, data.KeyTypeRSA4096:
+		return "rsa-" + strconv.Itoa(keyType.RSABits()), nil
+	}
+	return "", apperrors.NewAppError(apperrors.ErrorDataValidation,
+		"key type '"+string(keyType)+"' is not supported by Vault backend")
+}
+
+// parsePublicKey converts transit public key to data.Key,
+// ed25519 keys are base64 encoded, other keys are PEM encoded PKIX public keys
+func parsePublicKey(keyType data.KeyType, encoded string) (*data.Key, error) {
+	if keyType == data.KeyTypeEd25519 {
+		raw, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(raw) != ed25519.PublicKeySize {
+			return nil, apperrors.NewAppError(errcodes.ErrorDataValidationEd25519Key, "tuf: ed25519 public key is invalid")
+		}
+		return encryption.NewPublicKey(ed25519.PublicKey(raw))
+	}
+	block, _ := pem.Decode([]byte(encoded))
+	if block == nil {
+		return nil, apperrors.NewAppError(apperrors.ErrorDataSerialization, "Vault public key is not PEM encoded")
+	}
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, apperrors.CreateError(apperrors.ErrorDataSerialization, "failed to parse Vault public key", err)
+	}
+	return encryption.NewPublicKey(pub)
+}
+
+// Key is the transit key, it implements encryption.Signer and encryption.Verifier
+type Key struct {
+	backend *Backend
+	name    string
+	version int
+	keyType data.KeyType
+	pub     encryption.Verifier
+}
+
+// SignMessage signs a message with the transit key.
+func (k *Key) SignMessage(message []byte) ([]byte, error) {
+	req := signRequest{Input: message, KeyVersion: k.version}
+	req.HashAlgorithm, req.SignatureAlgorithm, req.MarshalingAlgorithm = k.algorithms()
+	if req.SignatureAlgorithm == rsaSignatureAlg {
+		// salt length equal to the hash length is expected by Aktualizr and python-tuf
+		req.SaltLength = rsaSaltLength
+	}
+	var resp signResponse
+	if err := k.backend.do(http.MethodPost, "/sign/"+k.name, req, &resp); err != nil {
+		return nil, err
+	}
+	// signature is formatted as vault:v<version>:<base64>
+	parts := strings.SplitN(resp.Data.Signature, ":", 3)
+	if len(parts) != 3 || !strings.HasPrefix(resp.Data.Signature, signaturePrefix) {
+		return nil, apperrors.NewAppError(apperrors.ErrorDataSerialization, "invalid Vault signature format")
+	}
+	sig, err := base64.StdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, apperrors.CreateError(apperrors.ErrorDataSerialization, "invalid Vault signature format", err)
+	}
+	return sig, nil
+}
+
+// MarshalPublicData returns the data.Key object associated with the verifier contains only public key.
+func (k *Key) MarshalPublicData() (*data.Key, error) {
+	return k.pub.MarshalPublicData()
+}
+
+// Public this is the public string used as a unique identifier for the verifier instance.
+func (k *Key) Public() string {
+	return k.pub.Public()
+}
+
+// Verify takes a message and signature and verifies them by the transit engine.
+func (k *Key) Verify(msg, sig []byte) error {
+	req := verifyRequest{
+		Input:     msg,
+		Signature: signaturePrefix + strconv.Itoa(k.version) + ":" + base64.StdEncoding.EncodeToString(sig),
+	}
+	req.HashAlgorithm, req.SignatureAlgorithm, req.MarshalingAlgorithm = k.algorithms()
+	var resp verifyResponse
+	if err := k.backend.do(http.MethodPost, "/verify/"+k.name, req, &resp); err != nil {
+		return err
+	}
+	if !resp.Data.Valid {
+		return apperrors.NewAppError(errcodes.ErrorDataValidationSignature, "tuf: signature verification failed")
+	}
+	return nil
+}
+
+// algorithms returns hash, signature and marshaling algorithms producing signatures compatible with local keys
+func (k *Key) algorithms() (string, string, string) {
+	switch k.keyType {
+	case data.KeyTypeRSA, data.KeyTypeRSA3072, data.KeyTypeRSA4096:
+		return hashAlgorithm, rsaSignatureAlg, ""
+	case data.KeyTypeECDSA:
+		return hashAlgorithm, "", ecdsaMarshalAlg
+	case data.KeyTypeECDSAP384:
+		return "sha2-384", "", ecdsaMarshalAlg
+	case data.KeyTypeECDSAP521:
+		return "sha2-512", "", ecdsaMarshalAlg
+	}
+	// ed25519 signs the message itself
+	return "", "", ""
+}
@@ -0,0 +1,318 @@
+package vault_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/shuvava/ota-tuf-server/pkg/data"
+	"github.com/shuvava/ota-tuf-server/pkg/encryption"
+	"github.com/shuvava/ota-tuf-server/pkg/encryption/vault"
+)
+
+const testToken = "s.test"
+
+// fakeTransit implements subset of Vault transit engine API used by the backend
+type fakeTransit struct {
+	mu   sync.Mutex
+	keys map[string]crypto.Signer
+}
+
+func newFakeTransit(t *testing.T) *httptest.Server {
+	f := &fakeTransit{keys: map[string]crypto.Signer{}}
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func (f *fakeTransit) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("X-Vault-Token") != testToken {
+		writeError(w, http.StatusForbidden, "permission denied")
+		return
+	}
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/v1/transit/"), "/")
+	if len(parts) != 2 {
+		writeError(w, http.StatusNotFound, "unsupported path")
+		return
+	}
+	var req map[string]interface{}
+	if r.Method == http.MethodPost {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	name := parts[1]
+	switch {
+	case parts[0] == "keys" && r.Method == http.MethodPost:
+		f.createKey(w, name, req)
+	case parts[0] == "keys" && r.Method == http.MethodGet:
+		f.readKey(w, name)
+	case parts[0] == "sign":
+		f.sign(w, name, req)
+	case parts[0] == "verify":
+		f.verify(w, name, req)
+	default:
+		writeError(w, http.StatusNotFound, "unsupported path")
+	}
+}
+
+func (f *fakeTransit) createKey(w http.ResponseWriter, name string, req map[string]interface{}) {
+	var key crypto.Signer
+	var err error
+	switch req["type"] {
+	case "ed25519":
+		_, key, err = ed25519.GenerateKey(rand.Reader)
+	case "ecdsa-p256":
+		key, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case "ecdsa-p384":
+		key, err = ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	case "ecdsa-p521":
+		key, err = ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+	case "rsa-3072":
+		key, err = rsa.GenerateKey(rand.Reader, 3072)
+	case "rsa-2048":
+		key, err = rsa.GenerateKey(rand.Reader, 2048)
+	case "rsa-4096":
+		key, err = rsa.GenerateKey(rand.Reader, 4096)
+	default:
+		writeError(w, http.StatusBadRequest, "unsupported key type")
+		return
+	}
+	if err != nil || req["exportable"] != false {
+		writeError(w, http.StatusBadRequest, "invalid key request")
+		return
+	}
+	f.keys[name] = key
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (f *fakeTransit) readKey(w http.ResponseWriter, name string) {
+	key, ok := f.keys[name]
+	if !ok {
+		writeError(w, http.StatusNotFound, "key not found")
+		return
+	}
+	var pub string
+	if edKey, ok := key.Public().(ed25519.PublicKey); ok {
+		pub = base64.StdEncoding.EncodeToString(edKey)
+	} else {
+		der, _ := x509.MarshalPKIXPublicKey(key.Public())
+		pub = string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+	}
+	writeData(w, map[string]interface{}{
+		"latest_version": 1,
+		"keys":           map[string]interface{}{"1": map[string]string{"public_key": pub}},
+	})
+}
+
+func (f *fakeTransit) sign(w http.ResponseWriter, name string, req map[string]interface{}) {
+	key, input, hash, ok := f.keyInput(w, name, req)
+	if !ok {
+		return
+	}
+	var sig []byte
+	var err error
+	switch k := key.(type) {
+	case ed25519.PrivateKey:
+		sig = ed25519.Sign(k, input)
+	case *ecdsa.PrivateKey:
+		sig, err = ecdsa.SignASN1(rand.Reader, k, hash)
+	case *rsa.PrivateKey:
+		if req["signature_algorithm"] == "pss" && req["salt_length"] == "hash" {
+			opts := rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash}
+			sig, err = rsa.SignPSS(rand.Reader, k, crypto.SHA256, hash, &opts)
+		} else if req["signature_algorithm"] == "pss" {
+			sig, err = rsa.SignPSS(rand.Reader, k, crypto.SHA256, hash, nil)
+		} else {
+			sig, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, hash)
+		}
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeData(w, map[string]string{"signature": "vault:v1:" + base64.StdEncoding.EncodeToString(sig)})
+}
+
+func (f *fakeTransit) verify(w http.ResponseWriter, name string, req map[string]interface{}) {
+	key, input, hash, ok := f.keyInput(w, name, req)
+	if !ok {
+		return
+	}
+	encoded, _ := req["signature"].(string)
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(encoded, "vault:v1:"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid signature")
+		return
+	}
+	var valid bool
+	switch k := key.Public().(type) {
+	case ed25519.PublicKey:
+		valid = ed25519.Verify(k, input, sig)
+	case *ecdsa.PublicKey:
+		valid = ecdsa.VerifyASN1(k, hash, sig)
+	case *rsa.PublicKey:
+		valid = rsa.VerifyPSS(k, crypto.SHA256, hash, sig, nil) == nil
+	}
+	writeData(w, map[string]bool{"valid": valid})
+}
+
+// keyInput returns the key, decoded input of sign/verify request and its digest
+func (f *fakeTransit) keyInput(w http.ResponseWriter, name string, req map[string]interface{}) (crypto.Signer, []byte, []byte, bool) {
+	key, ok := f.keys[name]
+	if !ok {
+		writeError(w, http.StatusNotFound, "key not found")
+		return nil, nil, nil, false
+	}
+	encoded, _ := req["input"].(string)
+	input, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid input")
+		return nil, nil, nil, false
+	}
+	hashAlg, hashFunc := "sha2-256", crypto.SHA256
+	switch k := key.(type) {
+	case ed25519.PrivateKey:
+		return key, input, nil, true
+	case *ecdsa.PrivateKey:
+		switch k.Curve {
+		case elliptic.P384():
+			hashAlg, hashFunc = "sha2-384", crypto.SHA384
+		case elliptic.P521():
+			hashAlg, hashFunc = "sha2-512", crypto.SHA512
+		}
+	}
+	if req["hash_algorithm"] != hashAlg {
+		writeError(w, http.StatusBadRequest, "unexpected hash algorithm")
+		return nil, nil, nil, false
+	}
+	h := hashFunc.New()
+	h.Write(input)
+	return key, input, h.Sum(nil), true
+}
+
+func writeData(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": v})
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string][]string{"errors": {msg}})
+}
+
+func TestBackend(t *testing.T) {
+	srv := newFakeTransit(t)
+	repoID := data.NewRepoID()
+	newBackend := func(t *testing.T, rsaKeySize int) *vault.Backend {
+		backend, err := vault.New(vault.Config{Address: srv.URL, Token: testToken, RSAKeySize: rsaKeySize})
+		if err != nil {
+			t.Fatalf("unable to create backend: %v", err)
+		}
+		return backend
+	}
+	cases := []struct {
+		keyType    data.KeyType
+		rsaKeySize int
+	}{
+		{data.KeyTypeEd25519, 0},
+		{data.KeyTypeECDSA, 0},
+		{data.KeyTypeECDSAP384, 0},
+		{data.KeyTypeECDSAP521, 0},
+		{data.KeyTypeRSA, 2048},
+		{data.KeyTypeRSA, 4096},
+		{data.KeyTypeRSA3072, 0},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run("should sign payload with Vault "+string(c.keyType)+" key", func(t *testing.T) {
+			backend := newBackend(t, c.rsaKeySize)
+			encryption.RegisterBackend(vault.BackendName, backend)
+			pub, ref, err := backend.GenerateKey(c.keyType, repoID.String()+"/root")
+			if err != nil {
+				t.Fatalf("unable to generate key: %v", err)
+			}
+			keyID, err := encryption.ComputeKeyID(pub)
+			if err != nil {
+				t.Fatalf("unable to compute key id: %v", err)
+			}
+			key := data.RepoKey{
+				RepoID: repoID,
+				Role:   data.RoleTypeRoot,
+				KeyID:  keyID,
+				Key:    *pub,
+				Handle: &data.KeyHandle{Backend: vault.BackendName, Ref: ref},
+			}
+			signed, err := encryption.SignPayload(map[string]string{"foo": "bar"}, []data.RepoKey{key})
+			if err != nil {
+				t.Fatalf("unable to sign payload: %v", err)
+			}
+			role := &data.RoleKeys{KeyIDs: []data.KeyID{keyID}, Threshold: 1}
+			if err = encryption.VerifyPayload(signed, map[data.KeyID]data.Key{keyID: *pub}, role); err != nil {
+				t.Errorf("signature verification failed: %v", err)
+			}
+		})
+	}
+	t.Run("Vault key should verify signatures", func(t *testing.T) {
+		backend := newBackend(t, 0)
+		pub, ref, err := backend.GenerateKey(data.KeyTypeECDSA, "")
+		if err != nil {
+			t.Fatalf("unable to generate key: %v", err)
+		}
+		key, err := backend.Key(ref, pub)
+		if err != nil {
+			t.Fatalf("unable to get key: %v", err)
+		}
+		var verifier encryption.Verifier = key
+		msg := []byte("message")
+		sig, err := key.SignMessage(msg)
+		if err != nil {
+			t.Fatalf("unable to sign message: %v", err)
+		}
+		if err = verifier.Verify(msg, sig); err != nil {
+			t.Errorf("expected valid signature, got %v", err)
+		}
+		if err = verifier.Verify([]byte("other"), sig); err == nil {
+			t.Error("expected verification error, got nil")
+		}
+	})
+	t.Run("should fail with invalid token", func(t *testing.T) {
+		backend, _ := vault.New(vault.Config{Address: srv.URL, Token: "invalid"})
+		if _, _, err := backend.GenerateKey(data.KeyTypeEd25519, ""); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+	t.Run("should fail with unsupported RSA key size", func(t *testing.T) {
+		if _, err := vault.New(vault.Config{Address: srv.URL, RSAKeySize: 1024}); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+	t.Run("should fail with invalid key reference", func(t *testing.T) {
+		backend := newBackend(t, 0)
+		pub, _, err := backend.GenerateKey(data.KeyTypeEd25519, "")
+		if err != nil {
+			t.Fatalf("unable to generate key: %v", err)
+		}
+		if _, err = backend.Signer("tuf-key", pub); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+}
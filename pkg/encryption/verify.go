@@ -0,0 +1,58 @@
+package encryption
+
+import (
+	"fmt"
+
+	"github.com/shuvava/go-ota-svc-common/apperrors"
+
+	"github.com/shuvava/ota-tuf-server/pkg/cjson"
+	"github.com/shuvava/ota-tuf-server/pkg/data"
+	"github.com/shuvava/ota-tuf-server/pkg/errcodes"
+)
+
+// VerifyPayload checks that canonical form of signed portion of the payload
+// is signed by at least threshold of keys trusted for the role.
+// Signatures of unknown keys and invalid signatures are ignored,
+// every trusted key is counted once. Keys and signature methods of any supported encoding are accepted.
+func VerifyPayload(payload *data.SignedPayload, keys map[data.KeyID]data.Key, role *data.RoleKeys) error {
+	msg, err := cjson.Canonicalize(payload.Signed)
+	if err != nil {
+		return err
+	}
+	trusted := make(map[data.KeyID]struct{}, len(role.KeyIDs))
+	for _, keyID := range role.KeyIDs {
+		trusted[keyID] = struct{}{}
+	}
+	valid := make(map[data.KeyID]struct{}, len(payload.Signatures))
+	for _, sig := range payload.Signatures {
+		if _, ok := trusted[sig.KeyID]; !ok {
+			continue
+		}
+		if _, ok := valid[sig.KeyID]; ok {
+			continue
+		}
+		key, ok := keys[sig.KeyID]
+		if !ok {
+			continue
+		}
+		native, err := DecodeKey(&key)
+		if err != nil {
+			return err
+		}
+		if sig.Method != "" && !isSignatureMethodOf(sig.Method, native.Type) {
+			continue
+		}
+		verifier, err := UnmarshalKey(native)
+		if err != nil {
+			return err
+		}
+		if verifier.Verify(msg, sig.Signature) == nil {
+			valid[sig.KeyID] = struct{}{}
+		}
+	}
+	if len(valid) < role.Threshold {
+		return apperrors.NewAppError(errcodes.ErrorDataValidationSignature,
+			fmt.Sprintf("tuf: %d valid signatures, threshold is %d", len(valid), role.Threshold))
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package encryption_test
+
+import (
+	"testing"
+
+	"github.com/shuvava/ota-tuf-server/pkg/data"
+	"github.com/shuvava/ota-tuf-server/pkg/encryption"
+)
+
+func TestVerifyPayload(t *testing.T) {
+	newKey := func(t *testing.T) data.RepoKey {
+		key, err := encryption.NewKey(data.KeyTypeEd25519)
+		if err != nil {
+			t.Fatalf("unable to generate key: %v", err)
+		}
+		dtKey, err := key.MarshalAllData()
+		if err != nil {
+			t.Fatalf("unable to marshal key: %v", err)
+		}
+		keyID, err := encryption.ComputeKeyID(dtKey)
+		if err != nil {
+			t.Fatalf("unable to compute key id: %v", err)
+		}
+		return data.RepoKey{KeyID: keyID, Key: *dtKey}
+	}
+	key1, key2, key3 := newKey(t), newKey(t), newKey(t)
+	keys := map[data.KeyID]data.Key{
+		key1.KeyID: key1.Key,
+		key2.KeyID: key2.Key,
+		key3.KeyID: key3.Key,
+	}
+	role := &data.RoleKeys{
+		KeyIDs:    []data.KeyID{key1.KeyID, key2.KeyID},
+		Threshold: 2,
+	}
+	payload := map[string]interface{}{"foo": "bar", "num": 1}
+
+	t.Run("should accept payload signed by threshold of keys", func(t *testing.T) {
+		signed, err := encryption.SignPayload(payload, []data.RepoKey{key1, key2})
+		if err != nil {
+			t.Fatalf("unable to sign payload: %v", err)
+		}
+		if err = encryption.VerifyPayload(signed, keys, role); err != nil {
+			t.Errorf("expected valid payload, got %v", err)
+		}
+	})
+	t.Run("should ignore signatures of untrusted keys", func(t *testing.T) {
+		signed, _ := encryption.SignPayload(payload, []data.RepoKey{key1, key3})
+		if err := encryption.VerifyPayload(signed, keys, role); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+	t.Run("should count duplicate signatures once", func(t *testing.T) {
+		signed, _ := encryption.SignPayload(payload, []data.RepoKey{key1, key1})
+		if err := encryption.VerifyPayload(signed, keys, role); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+	t.Run("should reject modified payload", func(t *testing.T) {
+		signed, _ := encryption.SignPayload(payload, []data.RepoKey{key1, key2})
+		signed.Signed = []byte(`{"foo":"baz","num":1}`)
+		if err := encryption.VerifyPayload(signed, keys, role); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+	t.Run("should verify canonical form of non-canonical payload", func(t *testing.T) {
+		signed, _ := encryption.SignPayload(payload, []data.RepoKey{key1, key2})
+		signed.Signed = []byte(`{ "num": 1, "foo": "bar" }`)
+		if err := encryption.VerifyPayload(signed, keys, role); err != nil {
+			t.Errorf("expected valid payload, got %v", err)
+		}
+	})
+}
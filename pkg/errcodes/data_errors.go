@@ -0,0 +1,8 @@
+package errcodes
+
+import "github.com/shuvava/go-ota-svc-common/apperrors"
+
+const (
+	// ErrorDataSerializationCanonicalJSON is the error code for canonical JSON serialization failure
+	ErrorDataSerializationCanonicalJSON = apperrors.ErrorDataSerialization + ":CanonicalJSON"
+)
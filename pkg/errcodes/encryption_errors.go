@@ -15,6 +15,8 @@ const (
 	ErrorDataValidationRSAKey = apperrors.ErrorDataValidation + ":RSAKey"
 	// ErrorDataSerializationRSAKey is the error code for RSA key serialization/creation failure
 	ErrorDataSerializationRSAKey = apperrors.ErrorDataSerialization + ":RSAKey"
+	// ErrorDataValidationSignature is the error code for signatures not meeting the role threshold
+	ErrorDataValidationSignature = apperrors.ErrorDataValidation + ":Signature"
 	// ErrorDataSigning is the error code for signing failure
 	ErrorDataSigning = apperrors.ErrorNamespaceData + ":Signing"
 	// ErrorDataSigningECDSAKey is the error code for ECDSA key signing failure
@@ -23,4 +25,12 @@ const (
 	ErrorDataSigningEd25519Key = ErrorDataSigning + ":Ed25519Key"
 	// ErrorDataSigningRSAKey is the error code for RSA key signing failure
 	ErrorDataSigningRSAKey = ErrorDataSigning + ":RSAKey"
+	// ErrorDataSigningNoPrivateKey is the error code for signing with a key without private part
+	ErrorDataSigningNoPrivateKey = ErrorDataSigning + ":NoPrivateKey"
+	// ErrorDataSigningBackend is the error code for failure of a key backend
+	ErrorDataSigningBackend = ErrorDataSigning + ":Backend"
+	// ErrorDataEncryption is the error code for encryption/decryption failure of stored secrets
+	ErrorDataEncryption = apperrors.ErrorNamespaceData + ":Encryption"
+	// ErrorDataEncryptionMasterKey is the error code for missing or invalid master key
+	ErrorDataEncryptionMasterKey = ErrorDataEncryption + ":MasterKey"
 )
@@ -0,0 +1,16 @@
+package errcodes
+
+import "github.com/shuvava/go-ota-svc-common/apperrors"
+
+const (
+	// ErrorSvcRepoNotFound is the error code for operations on not existing repository
+	ErrorSvcRepoNotFound = apperrors.ErrorNamespaceSvc + ":RepoNotFound"
+	// ErrorSvcKeyExported is the error code for repeated export of a private key
+	ErrorSvcKeyExported = apperrors.ErrorNamespaceSvc + ":KeyExported"
+	// ErrorSvcKeyGenStatus is the error code for operations not allowed in the current key generation request status
+	ErrorSvcKeyGenStatus = apperrors.ErrorNamespaceSvc + ":KeyGenStatus"
+	// ErrorSvcTargetNotFound is the error code for operations on target file missing in targets metadata
+	ErrorSvcTargetNotFound = apperrors.ErrorNamespaceSvc + ":TargetNotFound"
+	// ErrorSvcChecksumMismatch is the error code for changes of metadata which was changed since the client took it
+	ErrorSvcChecksumMismatch = apperrors.ErrorNamespaceSvc + ":ChecksumMismatch"
+)
@@ -0,0 +1,10 @@
+package errcodes
+
+import "github.com/shuvava/go-ota-svc-common/apperrors"
+
+const (
+	// ErrorFsBlobNotFound is the error code for operations on not existing blob of target file content
+	ErrorFsBlobNotFound = apperrors.ErrorFsPath + ":Blob"
+	// ErrorFsBlobStorage is the error code for failure of blob storage
+	ErrorFsBlobStorage = apperrors.ErrorFsIOOperation + ":Blob"
+)
@@ -0,0 +1,40 @@
+package services
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/shuvava/go-ota-svc-common/apperrors"
+
+	"github.com/shuvava/ota-tuf-server/pkg/errcodes"
+)
+
+// isNotFound checks if error is apperrors.ErrorDbNoDocumentFound
+func isNotFound(err error) bool {
+	var typedErr apperrors.AppError
+	return errors.As(err, &typedErr) && typedErr.ErrorCode == apperrors.ErrorDbNoDocumentFound
+}
+
+// isAlreadyExist checks if error is one of apperrors.ErrorDbAlreadyExist errors
+func isAlreadyExist(err error) bool {
+	var typedErr apperrors.AppError
+	return errors.As(err, &typedErr) && strings.HasPrefix(string(typedErr.ErrorCode), apperrors.ErrorDbAlreadyExist)
+}
+
+// isNoPrivateKey checks if error is errcodes.ErrorDataSigningNoPrivateKey
+func isNoPrivateKey(err error) bool {
+	var typedErr apperrors.AppError
+	return errors.As(err, &typedErr) && typedErr.ErrorCode == errcodes.ErrorDataSigningNoPrivateKey
+}
+
+// isTargetNotFound checks if error is errcodes.ErrorSvcTargetNotFound
+func isTargetNotFound(err error) bool {
+	var typedErr apperrors.AppError
+	return errors.As(err, &typedErr) && typedErr.ErrorCode == errcodes.ErrorSvcTargetNotFound
+}
+
+// isBlobNotFound checks if error is errcodes.ErrorFsBlobNotFound
+func isBlobNotFound(err error) bool {
+	var typedErr apperrors.AppError
+	return errors.As(err, &typedErr) && typedErr.ErrorCode == errcodes.ErrorFsBlobNotFound
+}
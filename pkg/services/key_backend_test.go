@@ -0,0 +1,126 @@
+package services_test
+
+import (
+	"context"
+	"encoding/hex"
+	"sync"
+	"testing"
+
+	"github.com/shuvava/ota-tuf-server/pkg/data"
+	"github.com/shuvava/ota-tuf-server/pkg/encryption"
+)
+
+const memBackendName = data.KeyBackend("mem")
+
+// memBackend is encryption.Backend keeping keys in memory
+type memBackend struct {
+	mu   sync.Mutex
+	keys map[string]data.Key
+}
+
+func (b *memBackend) GenerateKey(keyType data.KeyType, _ string) (*data.Key, string, error) {
+	key, err := encryption.NewKey(keyType)
+	if err != nil {
+		return nil, "", err
+	}
+	priv, err := key.MarshalAllData()
+	if err != nil {
+		return nil, "", err
+	}
+	pub, err := encryption.PublicKey(priv)
+	if err != nil {
+		return nil, "", err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.keys == nil {
+		b.keys = map[string]data.Key{}
+	}
+	ref := hex.EncodeToString([]byte{byte(len(b.keys))})
+	b.keys[ref] = *priv
+	return pub, ref, nil
+}
+
+func (b *memBackend) Signer(ref string, _ *data.Key) (encryption.Signer, error) {
+	b.mu.Lock()
+	key := b.keys[ref]
+	b.mu.Unlock()
+	return encryption.UnmarshalSigner(&key)
+}
+
+func TestKeyBackend(t *testing.T) {
+	ctx := context.Background()
+	encryption.RegisterBackend(memBackendName, &memBackend{})
+	newBackendRepo := func(t *testing.T, s *testServices) data.RepoID {
+		t.Helper()
+		repo := data.NewRepo(data.NewRepoID(), data.KeyTypeEd25519)
+		for _, role := range []data.RoleType{data.RoleTypeRoot, data.RoleTypeTargets} {
+			cfg := repo.Roles[role]
+			cfg.Backend = memBackendName
+			repo.Roles[role] = cfg
+		}
+		if err := s.keySvc.CreateNewRepository(ctx, repo); err != nil {
+			t.Fatalf("unable to create repository: %v", err)
+		}
+		return repo.RepoID
+	}
+	t.Run("should keep only key handle of backend keys", func(t *testing.T) {
+		s := newTestServices()
+		repoID := newBackendRepo(t, s)
+		keys, _ := s.keyRepo.FindByRepoId(ctx, repoID)
+		for _, key := range keys {
+			inBackend := key.Role == data.RoleTypeRoot || key.Role == data.RoleTypeTargets
+			if inBackend && (key.Handle == nil || encryption.HasPrivateKey(&key.Key)) {
+				t.Errorf("key of role %s should be referenced by handle only", key.Role)
+			}
+			if !inBackend && (key.Handle != nil || !encryption.HasPrivateKey(&key.Key)) {
+				t.Errorf("key of role %s should be stored locally", key.Role)
+			}
+		}
+	})
+	t.Run("root should be signed by backend key", func(t *testing.T) {
+		s := newTestServices()
+		repoID := newBackendRepo(t, s)
+		signed, err := s.rootSvc.GetSignedRoot(ctx, repoID)
+		if err != nil {
+			t.Fatalf("unable to get root: %v", err)
+		}
+		keys, _ := s.keyRepo.FindByRole(ctx, repoID, data.RoleTypeRoot)
+		if got := verifiedBy(t, signed.Content, map[data.KeyID]data.Key{keys[0].KeyID: keys[0].Key}); !got[keys[0].KeyID] {
+			t.Error("root should be signed by backend key")
+		}
+	})
+	t.Run("backend key should not be exportable", func(t *testing.T) {
+		s := newTestServices()
+		repoID := newBackendRepo(t, s)
+		keys, _ := s.keyRepo.FindByRole(ctx, repoID, data.RoleTypeTargets)
+		if _, err := s.keySvc.ExportPrivateKey(ctx, repoID, keys[0].KeyID); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+	t.Run("deleting private key should drop key handle", func(t *testing.T) {
+		s := newTestServices()
+		repoID := newBackendRepo(t, s)
+		keys, _ := s.keyRepo.FindByRole(ctx, repoID, data.RoleTypeTargets)
+		if err := s.keySvc.DeletePrivateKey(ctx, repoID, keys[0].KeyID); err != nil {
+			t.Fatalf("unable to delete private key: %v", err)
+		}
+		key, _ := s.keyRepo.FindByKeyID(ctx, repoID, keys[0].KeyID)
+		if key.Handle != nil {
+			t.Error("key handle should be deleted")
+		}
+		if _, err := s.keySvc.SignRolePayload(ctx, repoID, data.RoleTypeTargets, []byte(`{}`)); err == nil {
+			t.Error("expected signing error, got nil")
+		}
+	})
+	t.Run("should fail for unknown backend", func(t *testing.T) {
+		s := newTestServices()
+		repo := data.NewRepo(data.NewRepoID(), data.KeyTypeEd25519)
+		cfg := repo.Roles[data.RoleTypeRoot]
+		cfg.Backend = "unknown"
+		repo.Roles[data.RoleTypeRoot] = cfg
+		if err := s.keySvc.CreateNewRepository(ctx, repo); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+}
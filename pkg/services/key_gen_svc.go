@@ -0,0 +1,247 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/shuvava/go-logging/logger"
+	"github.com/shuvava/go-ota-svc-common/apperrors"
+
+	"github.com/shuvava/ota-tuf-server/internal/db"
+	"github.com/shuvava/ota-tuf-server/pkg/data"
+	"github.com/shuvava/ota-tuf-server/pkg/errcodes"
+)
+
+const (
+	// keyGenQueueSize is the number of requests waiting for a free worker
+	keyGenQueueSize = 100
+	// keyGenSweepInterval is the interval of picking up requests missed by the queue
+	keyGenSweepInterval = 30 * time.Second
+)
+
+// KeyGenService generates keys of new repositories in background by pool of workers.
+// Requests are persisted, so requests of crashed or stopped server are processed after restart.
+type KeyGenService struct {
+	log     logger.Logger
+	reqRepo db.KeyGenRequestRepository
+	keySvc  *RepositoryService
+	workers int
+	// defaultBackend is the key backend of roles requested without backend
+	defaultBackend data.KeyBackend
+	queue          chan data.RepoID
+	mu             sync.Mutex
+	// pending is the set of requests in the queue or in processing
+	pending map[data.RepoID]struct{}
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// NewKeyGenService creates new instance of services.KeyGenService,
+// keys of roles requested without backend are kept by defaultBackend
+func NewKeyGenService(l logger.Logger, reqRepo db.KeyGenRequestRepository, keySvc *RepositoryService,
+	workers int, defaultBackend data.KeyBackend) *KeyGenService {
+	log := l.SetOperation("key-gen-service")
+	if workers < 1 {
+		workers = 1
+	}
+	return &KeyGenService{
+		log:            log,
+		reqRepo:        reqRepo,
+		keySvc:         keySvc,
+		workers:        workers,
+		defaultBackend: defaultBackend,
+		queue:          make(chan data.RepoID, keyGenQueueSize),
+		pending:        make(map[data.RepoID]struct{}),
+	}
+}
+
+// Start starts workers processing key generation requests
+func (svc *KeyGenService) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	svc.cancel = cancel
+	for i := 0; i < svc.workers; i++ {
+		svc.wg.Add(1)
+		go svc.worker(ctx)
+	}
+	svc.wg.Add(1)
+	go svc.sweeper(ctx)
+	svc.log.WithField("Workers", svc.workers).
+		Info("Key generation workers started")
+}
+
+// Stop stops workers and waits until requests in processing are completed
+func (svc *KeyGenService) Stop() {
+	if svc.cancel == nil {
+		return
+	}
+	svc.cancel()
+	svc.wg.Wait()
+	svc.log.Info("Key generation workers stopped")
+}
+
+// RequestKeyGeneration persists request to generate keys of the new repository and queues it for processing.
+// Repeated request with the same configuration returns the existing one.
+func (svc *KeyGenService) RequestKeyGeneration(ctx context.Context, repo data.Repo) (*data.KeyGenRequest, error) {
+	if err := repo.Validate(); err != nil {
+		return nil, err
+	}
+	repo = svc.withDefaultBackend(repo)
+	if err := validateBackends(repo); err != nil {
+		return nil, err
+	}
+	if err := validateKeyEncoding(repo); err != nil {
+		return nil, err
+	}
+	req := data.NewKeyGenRequest(repo)
+	err := svc.reqRepo.Create(ctx, req)
+	if isAlreadyExist(err) {
+		existing, err := svc.reqRepo.FindByRepoID(ctx, repo.RepoID)
+		if err != nil {
+			return nil, err
+		}
+		if !sameRepoConfig(existing.Repo, repo) {
+			return nil, apperrors.NewAppError(apperrors.ErrorSvcEntityExists,
+				"repository '"+repo.RepoID.String()+"' already requested with different configuration")
+		}
+		return existing, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	svc.enqueue(repo.RepoID)
+	return &req, nil
+}
+
+// GetKeyGenRequest returns key generation request of the repository
+func (svc *KeyGenService) GetKeyGenRequest(ctx context.Context, repoID data.RepoID) (*data.KeyGenRequest, error) {
+	return svc.reqRepo.FindByRepoID(ctx, repoID)
+}
+
+// RetryKeyGeneration queues failed key generation request of the repository for processing again
+func (svc *KeyGenService) RetryKeyGeneration(ctx context.Context, repoID data.RepoID) (*data.KeyGenRequest, error) {
+	req, err := svc.reqRepo.FindByRepoID(ctx, repoID)
+	if err != nil {
+		return nil, err
+	}
+	if req.Status != data.KeyGenStatusError {
+		return nil, apperrors.NewAppError(errcodes.ErrorSvcKeyGenStatus,
+			"key generation request in status '"+string(req.Status)+"' can not be retried")
+	}
+	req.Status = data.KeyGenStatusRequested
+	req.Error = ""
+	req.UpdatedAt = time.Now().UTC()
+	if err = svc.reqRepo.Update(ctx, *req); err != nil {
+		return nil, err
+	}
+	svc.enqueue(repoID)
+	return req, nil
+}
+
+// enqueue adds request to the queue unless it is already queued
+func (svc *KeyGenService) enqueue(repoID data.RepoID) {
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+	if _, ok := svc.pending[repoID]; ok {
+		return
+	}
+	select {
+	case svc.queue <- repoID:
+		svc.pending[repoID] = struct{}{}
+	default:
+		svc.log.WithField("RepoID", repoID).
+			Warn("Key generation queue is full, request is postponed")
+	}
+}
+
+// done removes request from the set of pending requests
+func (svc *KeyGenService) done(repoID data.RepoID) {
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+	delete(svc.pending, repoID)
+}
+
+func (svc *KeyGenService) worker(ctx context.Context) {
+	defer svc.wg.Done()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case repoID := <-svc.queue:
+			// processing is not interrupted by Stop to not leave repository half-created
+			svc.process(context.Background(), repoID)
+			svc.done(repoID)
+		}
+	}
+}
+
+// sweeper periodically queues requests which were not queued on creation
+func (svc *KeyGenService) sweeper(ctx context.Context) {
+	defer svc.wg.Done()
+	ticker := time.NewTicker(keyGenSweepInterval)
+	defer ticker.Stop()
+	for {
+		reqs, err := svc.reqRepo.FindByStatus(ctx, data.KeyGenStatusRequested)
+		if err != nil {
+			svc.log.WithError(err).
+				Error("Failed to look up key generation requests")
+		}
+		for _, req := range reqs {
+			svc.enqueue(req.Repo.RepoID)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// process generates keys of the repository and records the outcome
+func (svc *KeyGenService) process(ctx context.Context, repoID data.RepoID) {
+	log := svc.log.WithContext(ctx).
+		WithField("RepoID", repoID)
+	req, err := svc.reqRepo.FindByRepoID(ctx, repoID)
+	if err != nil {
+		log.WithError(err).
+			Error("Failed to look up key generation request")
+		return
+	}
+	if req.Status != data.KeyGenStatusRequested {
+		return
+	}
+	err = svc.keySvc.CreateNewRepository(ctx, req.Repo)
+	req.Attempts++
+	req.UpdatedAt = time.Now().UTC()
+	if err != nil {
+		log.WithError(err).
+			WithField("Attempts", req.Attempts).
+			Error("Key generation failed")
+		req.Status = data.KeyGenStatusError
+		req.Error = err.Error()
+	} else {
+		log.Info("Key generation completed")
+		req.Status = data.KeyGenStatusGenerated
+		req.Error = ""
+	}
+	if err = svc.reqRepo.Update(ctx, *req); err != nil {
+		log.WithError(err).
+			Error("Failed to update key generation request")
+	}
+}
+
+// withDefaultBackend returns copy of the repository with default key backend set to roles without backend
+func (svc *KeyGenService) withDefaultBackend(repo data.Repo) data.Repo {
+	if svc.defaultBackend.IsLocal() {
+		return repo
+	}
+	roles := make(map[data.RoleType]data.RoleConfig, len(repo.Roles))
+	for role, cfg := range repo.Roles {
+		if cfg.Backend == "" {
+			cfg.Backend = svc.defaultBackend
+		}
+		roles[role] = cfg
+	}
+	repo.Roles = roles
+	return repo
+}
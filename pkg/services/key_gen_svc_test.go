@@ -0,0 +1,125 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shuvava/go-logging/logger"
+
+	"github.com/shuvava/ota-tuf-server/pkg/data"
+	"github.com/shuvava/ota-tuf-server/pkg/encryption"
+	"github.com/shuvava/ota-tuf-server/pkg/services"
+)
+
+// waitKeyGen waits until key generation request leaves REQUESTED status
+func waitKeyGen(t *testing.T, svc *services.KeyGenService, repoID data.RepoID) *data.KeyGenRequest {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		req, err := svc.GetKeyGenRequest(context.Background(), repoID)
+		if err != nil {
+			t.Fatalf("unable to get key generation request: %v", err)
+		}
+		if req.Status != data.KeyGenStatusRequested {
+			return req
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("key generation request was not processed in time")
+	return nil
+}
+
+func TestKeyGenService(t *testing.T) {
+	ctx := context.Background()
+	newKeyGenService := func(t *testing.T, s *testServices) *services.KeyGenService {
+		svc := services.NewKeyGenService(logger.NewNopLogger(), &memKeyGenRequestRepo{}, s.keySvc, 2, "")
+		svc.Start()
+		t.Cleanup(svc.Stop)
+		return svc
+	}
+	t.Run("should generate keys in background", func(t *testing.T) {
+		s := newTestServices()
+		svc := newKeyGenService(t, s)
+		repo := data.NewRepo(data.NewRepoID(), data.KeyTypeEd25519)
+		req, err := svc.RequestKeyGeneration(ctx, repo)
+		if err != nil {
+			t.Fatalf("unable to request key generation: %v", err)
+		}
+		if req.Status != data.KeyGenStatusRequested {
+			t.Errorf("expected status %s, got %s", data.KeyGenStatusRequested, req.Status)
+		}
+		req = waitKeyGen(t, svc, repo.RepoID)
+		if req.Status != data.KeyGenStatusGenerated {
+			t.Fatalf("expected status %s, got %s: %s", data.KeyGenStatusGenerated, req.Status, req.Error)
+		}
+		if _, err = s.rootSvc.GetSignedRootVersion(ctx, repo.RepoID, 1); err != nil {
+			t.Errorf("root should be published: %v", err)
+		}
+		if req, err = svc.RequestKeyGeneration(ctx, repo); err != nil || req.Status != data.KeyGenStatusGenerated {
+			t.Errorf("repeated request should return existing one, got %v, %v", req, err)
+		}
+		repo.KeyType = data.KeyTypeRSA
+		if _, err = svc.RequestKeyGeneration(ctx, repo); err == nil {
+			t.Error("expected error on request with different configuration, got nil")
+		}
+	})
+	t.Run("should keep keys of roles without backend in default backend", func(t *testing.T) {
+		encryption.RegisterBackend(memBackendName, &memBackend{})
+		s := newTestServices()
+		svc := services.NewKeyGenService(logger.NewNopLogger(), &memKeyGenRequestRepo{}, s.keySvc, 1, memBackendName)
+		svc.Start()
+		t.Cleanup(svc.Stop)
+		repo := data.NewRepo(data.NewRepoID(), data.KeyTypeEd25519)
+		cfg := repo.Roles[data.RoleTypeTimestamp]
+		cfg.Backend = data.KeyBackendLocal
+		repo.Roles[data.RoleTypeTimestamp] = cfg
+		if _, err := svc.RequestKeyGeneration(ctx, repo); err != nil {
+			t.Fatalf("unable to request key generation: %v", err)
+		}
+		if req := waitKeyGen(t, svc, repo.RepoID); req.Status != data.KeyGenStatusGenerated {
+			t.Fatalf("expected status %s, got %s: %s", data.KeyGenStatusGenerated, req.Status, req.Error)
+		}
+		keys, _ := s.keyRepo.FindByRepoId(ctx, repo.RepoID)
+		for _, key := range keys {
+			if inBackend := key.Role != data.RoleTypeTimestamp; inBackend != (key.Handle != nil) {
+				t.Errorf("unexpected key handle %v of role %s", key.Handle, key.Role)
+			}
+		}
+	})
+	t.Run("should reject invalid configuration", func(t *testing.T) {
+		svc := newKeyGenService(t, newTestServices())
+		repo := data.NewRepo(data.NewRepoID(), data.KeyTypeEd25519)
+		repo.Roles[data.RoleTypeRoot] = data.RoleConfig{Threshold: 2, KeyCount: 1}
+		if _, err := svc.RequestKeyGeneration(ctx, repo); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+	t.Run("should retry failed request", func(t *testing.T) {
+		s := newTestServices()
+		svc := newKeyGenService(t, s)
+		repo := data.NewRepo(data.NewRepoID(), data.KeyTypeEd25519)
+		// repository record with different configuration fails the generation
+		conflicting := data.NewRepo(repo.RepoID, data.KeyTypeRSA)
+		_ = s.repoRepo.Create(ctx, conflicting)
+		if _, err := svc.RequestKeyGeneration(ctx, repo); err != nil {
+			t.Fatalf("unable to request key generation: %v", err)
+		}
+		req := waitKeyGen(t, svc, repo.RepoID)
+		if req.Status != data.KeyGenStatusError || req.Error == "" || req.Attempts != 1 {
+			t.Fatalf("expected failed request, got %+v", req)
+		}
+
+		delete(s.repoRepo.repos, repo.RepoID)
+		if _, err := svc.RetryKeyGeneration(ctx, repo.RepoID); err != nil {
+			t.Fatalf("unable to retry key generation: %v", err)
+		}
+		req = waitKeyGen(t, svc, repo.RepoID)
+		if req.Status != data.KeyGenStatusGenerated || req.Attempts != 2 {
+			t.Errorf("expected generated request, got %+v", req)
+		}
+		if _, err := svc.RetryKeyGeneration(ctx, repo.RepoID); err == nil {
+			t.Error("expected error on retry of generated request, got nil")
+		}
+	})
+}
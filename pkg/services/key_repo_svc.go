@@ -4,49 +4,233 @@ import (
 	"context"
 
 	"github.com/shuvava/go-logging/logger"
+	"github.com/shuvava/go-ota-svc-common/apperrors"
 
 	"github.com/shuvava/ota-tuf-server/internal/db"
 	"github.com/shuvava/ota-tuf-server/pkg/data"
 	"github.com/shuvava/ota-tuf-server/pkg/encryption"
+	"github.com/shuvava/ota-tuf-server/pkg/errcodes"
 )
 
 type RepositoryService struct {
-	log logger.Logger
-	db  db.KeyRepository
+	log      logger.Logger
+	db       db.KeyRepository
+	repoRepo db.RepoRepository
+	rootSvc  *RootRoleService
 }
 
 // NewRepositoryService creates new instance of services.RepositoryService
-func NewRepositoryService(l logger.Logger, db db.KeyRepository) *RepositoryService {
+func NewRepositoryService(l logger.Logger, db db.KeyRepository, repoRepo db.RepoRepository, rootSvc *RootRoleService) *RepositoryService {
 	log := l.SetOperation("repository-service")
 	return &RepositoryService{
-		log: log,
-		db:  db,
+		log:      log,
+		db:       db,
+		repoRepo: repoRepo,
+		rootSvc:  rootSvc,
 	}
 }
 
-// CreateNewRepository initializes new repository by creating and persisting new key pair for data.TopLevelRoles
-func (svc *RepositoryService) CreateNewRepository(ctx context.Context, repoID data.RepoID, keyType data.KeyType) error {
-	keys := make([]data.RepoKey, len(data.TopLevelRoles))
-	i := 0
+// CreateNewRepository initializes new repository by creating and persisting configured number of key pairs for data.TopLevelRoles
+// and publishing the first version of root role metadata.
+// Creation is all-or-nothing, everything created by the failed call is removed.
+// Call with the configuration of existing repository completes its creation if needed and succeeds.
+func (svc *RepositoryService) CreateNewRepository(ctx context.Context, repo data.Repo) error {
+	log := svc.log.WithContext(ctx).
+		WithField("RepoID", repo.RepoID)
+	if err := repo.Validate(); err != nil {
+		return err
+	}
+	repoID := repo.RepoID
+	existing, err := svc.repoRepo.FindByID(ctx, repoID)
+	if err != nil && !isNotFound(err) {
+		return err
+	}
+	if existing != nil && !sameRepoConfig(*existing, repo) {
+		return apperrors.NewAppError(apperrors.ErrorSvcEntityExists,
+			"repository '"+repoID.String()+"' already exists with different configuration")
+	}
+	currentKeys, err := svc.db.FindByRepoId(ctx, repoID)
+	if err != nil {
+		return err
+	}
+	// keys are generated before anything is persisted, generation is the most likely step to fail
+	var keys []data.RepoKey
 	for role := range data.TopLevelRoles {
-		key, err := encryption.NewKey(keyType)
-		if err != nil {
-			return err
+		missing := repo.Roles[role].KeyCount - len(filterKeysByRole(currentKeys, role))
+		for i := 0; i < missing; i++ {
+			key, err := generateRepoKey(repoID, role, repo.RoleKeyType(role), repo.Roles[role].Backend)
+			if err != nil {
+				return err
+			}
+			keys = append(keys, *key)
 		}
-		keySerialized, err := key.MarshalAllData()
-		keys[i] = data.RepoKey{
-			RepoID: repoID,
-			Role:   role,
-			KeyID:  data.NewKeyID(repoID, role),
-			Key:    *keySerialized,
+	}
+
+	var created []data.RepoKey
+	repoCreated := false
+	rollback := func() {
+		for _, key := range created {
+			if err := svc.db.Delete(ctx, repoID, key.KeyID); err != nil {
+				log.WithError(err).
+					WithField("KeyID", key.KeyID).
+					Error("Failed to delete key of failed repository creation")
+			}
 		}
-		i += 1
+		if repoCreated {
+			if err := svc.repoRepo.Delete(ctx, repoID); err != nil {
+				log.WithError(err).
+					Error("Failed to delete failed repository")
+			}
+		}
+	}
+	if existing == nil {
+		if err = svc.repoRepo.Create(ctx, repo); err != nil {
+			return err
+		}
+		repoCreated = true
 	}
 	for _, key := range keys {
-		err := svc.db.Create(ctx, key)
-		if err != nil {
+		if err = svc.db.Create(ctx, key); err != nil {
+			rollback()
 			return err
 		}
+		created = append(created, key)
+	}
+	_, err = svc.rootSvc.GetSignedRootVersion(ctx, repoID, 1)
+	if isNotFound(err) {
+		_, err = svc.rootSvc.CreateRoot(ctx, repoID)
+		if isAlreadyExist(err) {
+			// version was published by concurrent request
+			err = nil
+		}
 	}
+	if err != nil {
+		rollback()
+		return err
+	}
+	log.WithField("Count", len(created)).
+		Info("Repository created")
 	return nil
 }
+
+// SignRolePayload signs canonical form of JSON payload with all private keys of the repository role
+func (svc *RepositoryService) SignRolePayload(ctx context.Context, repoID data.RepoID, role data.RoleType, payload []byte) ([]data.Signature, error) {
+	log := svc.log.WithContext(ctx).
+		WithField("RepoID", repoID).
+		WithField("Role", role)
+	keys, err := svc.db.FindByRole(ctx, repoID, role)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return nil, apperrors.NewAppError(errcodes.ErrorSvcRepoNotFound, "repository '"+repoID.String()+"' does not exist")
+	}
+	signers := privateKeys(keys)
+	if len(signers) == 0 {
+		return nil, apperrors.NewAppError(errcodes.ErrorDataSigningNoPrivateKey, "role '"+string(role)+"' does not have private keys")
+	}
+	var enc data.KeyEncoding
+	repo, err := svc.repoRepo.FindByID(ctx, repoID)
+	if err != nil && !isNotFound(err) {
+		return nil, err
+	}
+	if repo != nil {
+		enc = repo.KeyEncoding
+	}
+	sigs, err := encryption.SignJSONWithEncoding(payload, signers, enc)
+	if err != nil {
+		log.WithError(err).
+			Warn("Role payload signing failed")
+		return nil, err
+	}
+	log.WithField("Count", len(sigs)).
+		Debug("Role payload signed")
+	return sigs, nil
+}
+
+// sameRepoConfig checks if repositories have the same keys configuration
+func sameRepoConfig(a, b data.Repo) bool {
+	if a.KeyType != b.KeyType || a.KeyEncoding != b.KeyEncoding {
+		return false
+	}
+	for role := range data.TopLevelRoles {
+		if a.Roles[role] != b.Roles[role] {
+			return false
+		}
+	}
+	return true
+}
+
+// validateBackends checks that key backends of the repository roles are available
+func validateBackends(repo data.Repo) error {
+	for role, cfg := range repo.Roles {
+		if cfg.Backend.IsLocal() {
+			continue
+		}
+		if _, err := encryption.GetBackend(cfg.Backend); err != nil {
+			return apperrors.CreateError(apperrors.ErrorDataValidation,
+				"role '"+string(role)+"' has invalid key backend", err)
+		}
+	}
+	return nil
+}
+
+// validateKeyEncoding checks that keys of the repository roles can be published in the key encoding of the repository
+func validateKeyEncoding(repo data.Repo) error {
+	for role := range data.TopLevelRoles {
+		if _, err := encryption.SignatureMethod(repo.RoleKeyType(role), repo.KeyEncoding); err != nil {
+			return apperrors.CreateError(apperrors.ErrorDataValidation,
+				"role '"+string(role)+"' has key type unsupported by the key encoding", err)
+		}
+	}
+	return nil
+}
+
+// generateRepoKey generates a new key of the repository role,
+// keys of not local backend are generated in the backend and only referenced by the repository key
+func generateRepoKey(repoID data.RepoID, role data.RoleType, keyType data.KeyType, backendName data.KeyBackend) (*data.RepoKey, error) {
+	if !backendName.IsLocal() {
+		backend, err := encryption.GetBackend(backendName)
+		if err != nil {
+			return nil, err
+		}
+		pub, ref, err := backend.GenerateKey(keyType, repoID.String()+"/"+string(role))
+		if err != nil {
+			return nil, err
+		}
+		key, err := newRepoKey(repoID, role, *pub)
+		if err != nil {
+			return nil, err
+		}
+		key.Handle = &data.KeyHandle{Backend: backendName, Ref: ref}
+		return key, nil
+	}
+	key, err := encryption.NewKey(keyType)
+	if err != nil {
+		return nil, err
+	}
+	keySerialized, err := key.MarshalAllData()
+	if err != nil {
+		return nil, err
+	}
+	return newRepoKey(repoID, role, *keySerialized)
+}
+
+// newRepoKey creates data.RepoKey of the repository role from key data,
+// keys of foreign encodings are stored in the native one
+func newRepoKey(repoID data.RepoID, role data.RoleType, key data.Key) (*data.RepoKey, error) {
+	native, err := encryption.DecodeKey(&key)
+	if err != nil {
+		return nil, err
+	}
+	keyID, err := encryption.ComputeKeyID(native)
+	if err != nil {
+		return nil, err
+	}
+	return &data.RepoKey{
+		RepoID: repoID,
+		Role:   role,
+		KeyID:  keyID,
+		Key:    *native,
+	}, nil
+}
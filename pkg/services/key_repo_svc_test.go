@@ -0,0 +1,150 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/shuvava/go-logging/logger"
+	"github.com/shuvava/go-ota-svc-common/apperrors"
+
+	"github.com/shuvava/ota-tuf-server/pkg/data"
+	"github.com/shuvava/ota-tuf-server/pkg/encryption"
+	"github.com/shuvava/ota-tuf-server/pkg/services"
+)
+
+// failingKeyRepo fails creation of the key with the number failOn
+type failingKeyRepo struct {
+	*memKeyRepo
+	failOn int
+	calls  int
+}
+
+func (r *failingKeyRepo) Create(ctx context.Context, obj data.RepoKey) error {
+	r.calls++
+	if r.calls == r.failOn {
+		return apperrors.NewAppError(apperrors.ErrorDbOperation, "insert failed")
+	}
+	return r.memKeyRepo.Create(ctx, obj)
+}
+
+func TestCreateNewRepository(t *testing.T) {
+	ctx := context.Background()
+	t.Run("should remove everything on partial failure", func(t *testing.T) {
+		log := logger.NewNopLogger()
+		keyRepo := &failingKeyRepo{memKeyRepo: &memKeyRepo{}, failOn: 3}
+		repoRepo := &memRepoRepo{}
+		roleRepo := &memSignedRoleRepo{}
+		rootSvc := services.NewRootRoleService(log, keyRepo, repoRepo, roleRepo)
+		keySvc := services.NewRepositoryService(log, keyRepo, repoRepo, rootSvc)
+		repo := data.NewRepo(data.NewRepoID(), data.KeyTypeEd25519)
+
+		if err := keySvc.CreateNewRepository(ctx, repo); err == nil {
+			t.Fatal("expected error, got nil")
+		}
+		if keys, _ := keyRepo.FindByRepoId(ctx, repo.RepoID); len(keys) != 0 {
+			t.Errorf("expected no keys, got %d", len(keys))
+		}
+		if exists, _ := repoRepo.Exists(ctx, repo.RepoID); exists {
+			t.Error("repository record should be removed")
+		}
+
+		if err := keySvc.CreateNewRepository(ctx, repo); err != nil {
+			t.Fatalf("retry should succeed, got %v", err)
+		}
+		if keys, _ := keyRepo.FindByRepoId(ctx, repo.RepoID); len(keys) != len(data.TopLevelRoles) {
+			t.Errorf("expected %d keys, got %d", len(data.TopLevelRoles), len(keys))
+		}
+	})
+	t.Run("should be idempotent for the same configuration", func(t *testing.T) {
+		s := newTestServices()
+		repoID := s.createTestRepo(t)
+		keys, _ := s.keyRepo.FindByRepoId(ctx, repoID)
+		repo := data.NewRepo(repoID, data.KeyTypeEd25519)
+		if err := s.keySvc.CreateNewRepository(ctx, repo); err != nil {
+			t.Fatalf("repeated creation should succeed, got %v", err)
+		}
+		if got, _ := s.keyRepo.FindByRepoId(ctx, repoID); len(got) != len(keys) {
+			t.Errorf("expected %d keys, got %d", len(keys), len(got))
+		}
+		if versions, _ := s.rootSvc.ListRootVersions(ctx, repoID); len(versions) != 1 {
+			t.Errorf("expected 1 root version, got %v", versions)
+		}
+	})
+	t.Run("should complete interrupted creation", func(t *testing.T) {
+		s := newTestServices()
+		repo := data.NewRepo(data.NewRepoID(), data.KeyTypeEd25519)
+		_ = s.repoRepo.Create(ctx, repo)
+		if err := s.keySvc.CreateNewRepository(ctx, repo); err != nil {
+			t.Fatalf("unable to complete creation: %v", err)
+		}
+		if _, err := s.rootSvc.GetSignedRootVersion(ctx, repo.RepoID, 1); err != nil {
+			t.Errorf("root should be published: %v", err)
+		}
+	})
+	t.Run("should reject different configuration of existing repository", func(t *testing.T) {
+		s := newTestServices()
+		repoID := s.createTestRepo(t)
+		repo := data.NewRepo(repoID, data.KeyTypeEd25519)
+		repo.Roles[data.RoleTypeTargets] = data.RoleConfig{Threshold: 1, KeyCount: 2}
+		if err := s.keySvc.CreateNewRepository(ctx, repo); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+	t.Run("should generate keys of the type configured for the role", func(t *testing.T) {
+		s := newTestServices()
+		repo := data.NewRepo(data.NewRepoID(), data.KeyTypeEd25519)
+		repo.Roles[data.RoleTypeRoot] = data.RoleConfig{Threshold: 1, KeyCount: 1, KeyType: data.KeyTypeECDSAP384}
+		if err := s.keySvc.CreateNewRepository(ctx, repo); err != nil {
+			t.Fatalf("unable to create repository: %v", err)
+		}
+		keys, _ := s.keyRepo.FindByRepoId(ctx, repo.RepoID)
+		for _, key := range keys {
+			if want := repo.RoleKeyType(key.Role); key.Key.Type != want {
+				t.Errorf("expected %s key of role %s, got %s", want, key.Role, key.Key.Type)
+			}
+		}
+		signed, err := s.rootSvc.GetSignedRoot(ctx, repo.RepoID)
+		if err != nil {
+			t.Fatalf("unable to get root: %v", err)
+		}
+		if method := signed.Content.Signatures[0].Method; method != data.SignatureMethodECDSAP384 {
+			t.Errorf("expected signature method %s, got %s", data.SignatureMethodECDSAP384, method)
+		}
+	})
+	t.Run("should publish root in the key encoding of the repository", func(t *testing.T) {
+		s := newTestServices()
+		repo := data.NewRepo(data.NewRepoID(), data.KeyTypeEd25519)
+		repo.KeyEncoding = data.KeyEncodingAktualizr
+		if err := s.keySvc.CreateNewRepository(ctx, repo); err != nil {
+			t.Fatalf("unable to create repository: %v", err)
+		}
+		signed, err := s.rootSvc.GetSignedRoot(ctx, repo.RepoID)
+		if err != nil {
+			t.Fatalf("unable to get root: %v", err)
+		}
+		var root data.RootRole
+		if err = signed.Content.DecodeSigned(&root); err != nil {
+			t.Fatalf("unable to decode root: %v", err)
+		}
+		for keyID, key := range root.Keys {
+			if key.Type != "ED25519" {
+				t.Errorf("expected ED25519 key %s, got %s", keyID, key.Type)
+			}
+			if published, _ := encryption.PublishedKeyID(&key); published != keyID {
+				t.Errorf("expected key id %s computed from encoded key, got %s", published, keyID)
+			}
+		}
+		if err = encryption.VerifyPayload(&signed.Content, root.Keys, root.Roles[data.RoleTypeRoot]); err != nil {
+			t.Errorf("expected valid root, got %v", err)
+		}
+		payload := []byte(`{"_type":"targets"}`)
+		sigs, err := s.keySvc.SignRolePayload(ctx, repo.RepoID, data.RoleTypeTargets, payload)
+		if err != nil {
+			t.Fatalf("unable to sign payload: %v", err)
+		}
+		targets := data.SignedPayload{Signatures: sigs, Signed: payload}
+		if err = encryption.VerifyPayload(&targets, root.Keys, root.Roles[data.RoleTypeTargets]); err != nil {
+			t.Errorf("expected signatures by published key ids, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,389 @@
+package services_test
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shuvava/go-logging/logger"
+	"github.com/shuvava/go-ota-svc-common/apperrors"
+
+	"github.com/shuvava/ota-tuf-server/pkg/data"
+	"github.com/shuvava/ota-tuf-server/pkg/services"
+)
+
+// testServices are services sharing in-memory repositories
+type testServices struct {
+	keyRepo    *memKeyRepo
+	repoRepo   *memRepoRepo
+	roleRepo   *memSignedRoleRepo
+	rootSvc    *services.RootRoleService
+	keySvc     *services.RepositoryService
+	targetsSvc *services.TargetsService
+}
+
+func newTestServices() *testServices {
+	log := logger.NewNopLogger()
+	keyRepo := &memKeyRepo{}
+	repoRepo := &memRepoRepo{}
+	roleRepo := &memSignedRoleRepo{}
+	rootSvc := services.NewRootRoleService(log, keyRepo, repoRepo, roleRepo)
+	keySvc := services.NewRepositoryService(log, keyRepo, repoRepo, rootSvc)
+	return &testServices{
+		keyRepo:    keyRepo,
+		repoRepo:   repoRepo,
+		roleRepo:   roleRepo,
+		rootSvc:    rootSvc,
+		keySvc:     keySvc,
+		targetsSvc: services.NewTargetsService(log, roleRepo, keySvc),
+	}
+}
+
+// createTestRepo creates a new repository with ed25519 keys
+func (s *testServices) createTestRepo(t *testing.T) data.RepoID {
+	t.Helper()
+	repo := data.NewRepo(data.NewRepoID(), data.KeyTypeEd25519)
+	if err := s.keySvc.CreateNewRepository(context.Background(), repo); err != nil {
+		t.Fatalf("unable to create repository: %v", err)
+	}
+	return repo.RepoID
+}
+
+// memKeyRepo is in-memory implementation of db.KeyRepository
+type memKeyRepo struct {
+	mu   sync.Mutex
+	keys []data.RepoKey
+}
+
+func (r *memKeyRepo) Create(_ context.Context, obj data.RepoKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, key := range r.keys {
+		if key.RepoID == obj.RepoID && key.KeyID == obj.KeyID {
+			return apperrors.NewAppError(apperrors.ErrorDbAlreadyExist, "key already exists")
+		}
+	}
+	r.keys = append(r.keys, obj)
+	return nil
+}
+
+func (r *memKeyRepo) FindAll(_ context.Context) ([]data.RepoKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]data.RepoKey(nil), r.keys...), nil
+}
+
+func (r *memKeyRepo) FindByRepoId(_ context.Context, repoID data.RepoID) ([]data.RepoKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var res []data.RepoKey
+	for _, key := range r.keys {
+		if key.RepoID == repoID {
+			res = append(res, key)
+		}
+	}
+	return res, nil
+}
+
+func (r *memKeyRepo) FindByRole(ctx context.Context, repoID data.RepoID, role data.RoleType) ([]data.RepoKey, error) {
+	keys, _ := r.FindByRepoId(ctx, repoID)
+	var res []data.RepoKey
+	for _, key := range keys {
+		if key.Role == role {
+			res = append(res, key)
+		}
+	}
+	return res, nil
+}
+
+func (r *memKeyRepo) FindByKeyID(ctx context.Context, repoID data.RepoID, keyID data.KeyID) (*data.RepoKey, error) {
+	keys, _ := r.FindByRepoId(ctx, repoID)
+	for _, key := range keys {
+		if key.KeyID == keyID {
+			return &key, nil
+		}
+	}
+	return nil, apperrors.NewAppError(apperrors.ErrorDbNoDocumentFound, "document not found")
+}
+
+func (r *memKeyRepo) Update(_ context.Context, obj data.RepoKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, key := range r.keys {
+		if key.RepoID == obj.RepoID && key.KeyID == obj.KeyID {
+			r.keys[i] = obj
+			return nil
+		}
+	}
+	return apperrors.NewAppError(apperrors.ErrorDbNoDocumentFound, "document not found")
+}
+
+func (r *memKeyRepo) MarkExported(_ context.Context, repoID data.RepoID, keyID data.KeyID) (*data.RepoKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, key := range r.keys {
+		if key.RepoID == repoID && key.KeyID == keyID && !key.Exported {
+			r.keys[i].Exported = true
+			res := r.keys[i]
+			return &res, nil
+		}
+	}
+	return nil, apperrors.NewAppError(apperrors.ErrorDbNoDocumentFound, "document not found")
+}
+
+func (r *memKeyRepo) Delete(_ context.Context, repoID data.RepoID, keyID data.KeyID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, key := range r.keys {
+		if key.RepoID == repoID && key.KeyID == keyID {
+			r.keys = append(r.keys[:i], r.keys[i+1:]...)
+			return nil
+		}
+	}
+	return apperrors.NewAppError(apperrors.ErrorDbNoDocumentFound, "document not found")
+}
+
+func (r *memKeyRepo) Exists(ctx context.Context, repoID data.RepoID, keyID data.KeyID) (bool, error) {
+	_, err := r.FindByKeyID(ctx, repoID, keyID)
+	return err == nil, nil
+}
+
+// memRepoRepo is in-memory implementation of db.RepoRepository
+type memRepoRepo struct {
+	mu    sync.Mutex
+	repos map[data.RepoID]data.Repo
+}
+
+func (r *memRepoRepo) Create(_ context.Context, obj data.Repo) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.repos == nil {
+		r.repos = map[data.RepoID]data.Repo{}
+	}
+	if _, ok := r.repos[obj.RepoID]; ok {
+		return apperrors.NewAppError(apperrors.ErrorDbAlreadyExist, "repo already exists")
+	}
+	r.repos[obj.RepoID] = obj
+	return nil
+}
+
+func (r *memRepoRepo) FindByID(_ context.Context, repoID data.RepoID) (*data.Repo, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	repo, ok := r.repos[repoID]
+	if !ok {
+		return nil, apperrors.NewAppError(apperrors.ErrorDbNoDocumentFound, "document not found")
+	}
+	return &repo, nil
+}
+
+func (r *memRepoRepo) Update(_ context.Context, obj data.Repo) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.repos[obj.RepoID]; !ok {
+		return apperrors.NewAppError(apperrors.ErrorDbNoDocumentFound, "document not found")
+	}
+	r.repos[obj.RepoID] = obj
+	return nil
+}
+
+func (r *memRepoRepo) Delete(_ context.Context, repoID data.RepoID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.repos[repoID]; !ok {
+		return apperrors.NewAppError(apperrors.ErrorDbNoDocumentFound, "document not found")
+	}
+	delete(r.repos, repoID)
+	return nil
+}
+
+func (r *memRepoRepo) Exists(ctx context.Context, repoID data.RepoID) (bool, error) {
+	_, err := r.FindByID(ctx, repoID)
+	return err == nil, nil
+}
+
+// memSignedRoleRepo is in-memory implementation of db.SignedRoleRepository
+type memSignedRoleRepo struct {
+	mu    sync.Mutex
+	roles []data.SignedRole
+}
+
+func (r *memSignedRoleRepo) Create(_ context.Context, obj data.SignedRole) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, role := range r.roles {
+		if role.RepoID == obj.RepoID && role.Role == obj.Role && role.Version == obj.Version {
+			return apperrors.NewAppError(apperrors.ErrorDbAlreadyExist, "version already exists")
+		}
+	}
+	r.roles = append(r.roles, obj)
+	return nil
+}
+
+func (r *memSignedRoleRepo) find(repoID data.RepoID, roleType data.RoleType) []data.SignedRole {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var res []data.SignedRole
+	for _, role := range r.roles {
+		if role.RepoID == repoID && role.Role == roleType {
+			res = append(res, role)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Version < res[j].Version })
+	return res
+}
+
+func (r *memSignedRoleRepo) FindVersion(_ context.Context, repoID data.RepoID, roleType data.RoleType, version int) (*data.SignedRole, error) {
+	for _, role := range r.find(repoID, roleType) {
+		if role.Version == version {
+			return &role, nil
+		}
+	}
+	return nil, apperrors.NewAppError(apperrors.ErrorDbNoDocumentFound, "document not found")
+}
+
+func (r *memSignedRoleRepo) FindLatest(_ context.Context, repoID data.RepoID, roleType data.RoleType) (*data.SignedRole, error) {
+	roles := r.find(repoID, roleType)
+	if len(roles) == 0 {
+		return nil, apperrors.NewAppError(apperrors.ErrorDbNoDocumentFound, "document not found")
+	}
+	return &roles[len(roles)-1], nil
+}
+
+func (r *memSignedRoleRepo) ListVersions(_ context.Context, repoID data.RepoID, roleType data.RoleType) ([]int, error) {
+	var res []int
+	for _, role := range r.find(repoID, roleType) {
+		res = append(res, role.Version)
+	}
+	return res, nil
+}
+
+func (r *memSignedRoleRepo) FindExpiring(_ context.Context, roleType data.RoleType, before time.Time) ([]data.SignedRole, error) {
+	r.mu.Lock()
+	latest := map[data.RepoID]data.SignedRole{}
+	for _, role := range r.roles {
+		if role.Role == roleType && role.Version > latest[role.RepoID].Version {
+			latest[role.RepoID] = role
+		}
+	}
+	r.mu.Unlock()
+	var res []data.SignedRole
+	for _, role := range latest {
+		if role.ExpiresAt.Before(before) {
+			res = append(res, role)
+		}
+	}
+	return res, nil
+}
+
+// memKeyGenRequestRepo is in-memory implementation of db.KeyGenRequestRepository
+type memKeyGenRequestRepo struct {
+	mu   sync.Mutex
+	reqs map[data.RepoID]data.KeyGenRequest
+}
+
+func (r *memKeyGenRequestRepo) Create(_ context.Context, obj data.KeyGenRequest) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.reqs == nil {
+		r.reqs = map[data.RepoID]data.KeyGenRequest{}
+	}
+	if _, ok := r.reqs[obj.Repo.RepoID]; ok {
+		return apperrors.NewAppError(apperrors.ErrorDbAlreadyExist, "request already exists")
+	}
+	r.reqs[obj.Repo.RepoID] = obj
+	return nil
+}
+
+func (r *memKeyGenRequestRepo) FindByRepoID(_ context.Context, repoID data.RepoID) (*data.KeyGenRequest, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	req, ok := r.reqs[repoID]
+	if !ok {
+		return nil, apperrors.NewAppError(apperrors.ErrorDbNoDocumentFound, "document not found")
+	}
+	return &req, nil
+}
+
+func (r *memKeyGenRequestRepo) FindByStatus(_ context.Context, status data.KeyGenStatus) ([]data.KeyGenRequest, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var res []data.KeyGenRequest
+	for _, req := range r.reqs {
+		if req.Status == status {
+			res = append(res, req)
+		}
+	}
+	return res, nil
+}
+
+func (r *memKeyGenRequestRepo) Update(_ context.Context, obj data.KeyGenRequest) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.reqs[obj.Repo.RepoID]; !ok {
+		return apperrors.NewAppError(apperrors.ErrorDbNoDocumentFound, "document not found")
+	}
+	r.reqs[obj.Repo.RepoID] = obj
+	return nil
+}
+
+// memResignRecordRepo is in-memory implementation of db.ResignRecordRepository
+type memResignRecordRepo struct {
+	mu      sync.Mutex
+	records []data.ResignRecord
+}
+
+func (r *memResignRecordRepo) Create(_ context.Context, obj data.ResignRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.records = append(r.records, obj)
+	return nil
+}
+
+func (r *memResignRecordRepo) FindByRepoID(_ context.Context, repoID data.RepoID) ([]data.ResignRecord, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var res []data.ResignRecord
+	for i := len(r.records) - 1; i >= 0; i-- {
+		if r.records[i].RepoID == repoID {
+			res = append(res, r.records[i])
+		}
+	}
+	return res, nil
+}
+
+type memLease struct {
+	owner     string
+	expiresAt time.Time
+}
+
+// memLeaseRepo is in-memory implementation of db.LeaseRepository
+type memLeaseRepo struct {
+	mu     sync.Mutex
+	leases map[string]memLease
+}
+
+func (r *memLeaseRepo) Acquire(_ context.Context, name, owner string, ttl time.Duration) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.leases == nil {
+		r.leases = map[string]memLease{}
+	}
+	now := time.Now()
+	if lease, ok := r.leases[name]; ok && lease.owner != owner && lease.expiresAt.After(now) {
+		return false, nil
+	}
+	r.leases[name] = memLease{owner: owner, expiresAt: now.Add(ttl)}
+	return true, nil
+}
+
+func (r *memLeaseRepo) Release(_ context.Context, name, owner string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if lease, ok := r.leases[name]; ok && lease.owner == owner {
+		delete(r.leases, name)
+	}
+	return nil
+}
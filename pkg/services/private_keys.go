@@ -0,0 +1,75 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/shuvava/go-ota-svc-common/apperrors"
+
+	"github.com/shuvava/ota-tuf-server/pkg/data"
+	"github.com/shuvava/ota-tuf-server/pkg/encryption"
+	"github.com/shuvava/ota-tuf-server/pkg/errcodes"
+)
+
+// DeletePrivateKey removes private part of the repository key, public part remains usable to verify signatures.
+// Signing with the key has to be done offline afterwards.
+func (svc *RepositoryService) DeletePrivateKey(ctx context.Context, repoID data.RepoID, keyID data.KeyID) error {
+	log := svc.log.WithContext(ctx).
+		WithField("RepoID", repoID).
+		WithField("KeyID", keyID)
+	key, err := svc.db.FindByKeyID(ctx, repoID, keyID)
+	if err != nil {
+		return err
+	}
+	if !encryption.CanSign(key) {
+		return nil
+	}
+	pub, err := encryption.PublicKey(&key.Key)
+	if err != nil {
+		return err
+	}
+	key.Key = *pub
+	key.Handle = nil
+	if err = svc.db.Update(ctx, *key); err != nil {
+		return err
+	}
+	log.WithField("Role", key.Role).
+		Info("Private key deleted")
+	return nil
+}
+
+// ExportPrivateKey returns the repository key including private part for offline escrow.
+// The key can be exported only once, concurrent requests export the key once as well.
+func (svc *RepositoryService) ExportPrivateKey(ctx context.Context, repoID data.RepoID, keyID data.KeyID) (*data.Key, error) {
+	log := svc.log.WithContext(ctx).
+		WithField("RepoID", repoID).
+		WithField("KeyID", keyID)
+	key, err := svc.db.FindByKeyID(ctx, repoID, keyID)
+	if err != nil {
+		return nil, err
+	}
+	if key.Exported {
+		return nil, apperrors.NewAppError(errcodes.ErrorSvcKeyExported,
+			"private part of key '"+keyID.String()+"' was already exported")
+	}
+	if key.Handle != nil || !encryption.HasPrivateKey(&key.Key) {
+		return nil, apperrors.NewAppError(errcodes.ErrorDataSigningNoPrivateKey,
+			"key '"+keyID.String()+"' does not have exportable private part")
+	}
+	if key, err = svc.db.MarkExported(ctx, repoID, keyID); err != nil {
+		var typedErr apperrors.AppError
+		if errors.As(err, &typedErr) && typedErr.ErrorCode == apperrors.ErrorDbNoDocumentFound {
+			return nil, apperrors.NewAppError(errcodes.ErrorSvcKeyExported,
+				"private part of key '"+keyID.String()+"' was already exported")
+		}
+		return nil, err
+	}
+	// private part may be deleted between the look up and the update
+	if key.Handle != nil || !encryption.HasPrivateKey(&key.Key) {
+		return nil, apperrors.NewAppError(errcodes.ErrorDataSigningNoPrivateKey,
+			"key '"+keyID.String()+"' does not have exportable private part")
+	}
+	log.WithField("Role", key.Role).
+		Warn("Private key exported")
+	return &key.Key, nil
+}
@@ -0,0 +1,97 @@
+package services_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/shuvava/ota-tuf-server/pkg/data"
+	"github.com/shuvava/ota-tuf-server/pkg/encryption"
+)
+
+func TestDeletePrivateKey(t *testing.T) {
+	ctx := context.Background()
+	t.Run("should keep public part of the key", func(t *testing.T) {
+		s := newTestServices()
+		repoID := s.createTestRepo(t)
+		keys, _ := s.keyRepo.FindByRole(ctx, repoID, data.RoleTypeRoot)
+		keyID := keys[0].KeyID
+		if err := s.keySvc.DeletePrivateKey(ctx, repoID, keyID); err != nil {
+			t.Fatalf("unable to delete private key: %v", err)
+		}
+		key, _ := s.keyRepo.FindByKeyID(ctx, repoID, keyID)
+		if encryption.HasPrivateKey(&key.Key) {
+			t.Error("private part should be deleted")
+		}
+		if got, _ := encryption.ComputeKeyID(&key.Key); got != keyID {
+			t.Errorf("expected key id %s, got %s", keyID, got)
+		}
+		if _, err := s.keySvc.SignRolePayload(ctx, repoID, data.RoleTypeRoot, []byte(`{}`)); err == nil {
+			t.Error("expected signing error, got nil")
+		}
+		if err := s.keySvc.DeletePrivateKey(ctx, repoID, keyID); err != nil {
+			t.Errorf("repeated delete should succeed, got %v", err)
+		}
+	})
+	t.Run("should fail for unknown key", func(t *testing.T) {
+		s := newTestServices()
+		repoID := s.createTestRepo(t)
+		if err := s.keySvc.DeletePrivateKey(ctx, repoID, newOfflineKey(t).KeyID); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+}
+
+func TestExportPrivateKey(t *testing.T) {
+	ctx := context.Background()
+	t.Run("should export private key only once", func(t *testing.T) {
+		s := newTestServices()
+		repoID := s.createTestRepo(t)
+		keys, _ := s.keyRepo.FindByRole(ctx, repoID, data.RoleTypeTargets)
+		keyID := keys[0].KeyID
+		key, err := s.keySvc.ExportPrivateKey(ctx, repoID, keyID)
+		if err != nil {
+			t.Fatalf("unable to export private key: %v", err)
+		}
+		if _, err = encryption.UnmarshalSigner(key); err != nil {
+			t.Errorf("exported key should be usable for signing: %v", err)
+		}
+		if _, err = s.keySvc.ExportPrivateKey(ctx, repoID, keyID); err == nil {
+			t.Error("expected error on repeated export, got nil")
+		}
+	})
+	t.Run("should export private key once to concurrent requests", func(t *testing.T) {
+		s := newTestServices()
+		repoID := s.createTestRepo(t)
+		keys, _ := s.keyRepo.FindByRole(ctx, repoID, data.RoleTypeTargets)
+		keyID := keys[0].KeyID
+		var (
+			wg       sync.WaitGroup
+			exported int32
+		)
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := s.keySvc.ExportPrivateKey(ctx, repoID, keyID); err == nil {
+					atomic.AddInt32(&exported, 1)
+				}
+			}()
+		}
+		wg.Wait()
+		if exported != 1 {
+			t.Errorf("expected key exported once, got %d", exported)
+		}
+	})
+	t.Run("should fail for key without private part", func(t *testing.T) {
+		s := newTestServices()
+		repoID := s.createTestRepo(t)
+		keys, _ := s.keyRepo.FindByRole(ctx, repoID, data.RoleTypeTargets)
+		keyID := keys[0].KeyID
+		_ = s.keySvc.DeletePrivateKey(ctx, repoID, keyID)
+		if _, err := s.keySvc.ExportPrivateKey(ctx, repoID, keyID); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+}
@@ -0,0 +1,206 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/shuvava/go-logging/logger"
+
+	"github.com/shuvava/ota-tuf-server/internal/db"
+	"github.com/shuvava/ota-tuf-server/pkg/data"
+)
+
+const (
+	// defaultResignInterval is the interval of scanning repositories for expiring metadata
+	defaultResignInterval = 10 * time.Minute
+	// defaultResignThreshold is the fraction of metadata lifetime which may elapse before it is re-signed
+	defaultResignThreshold = 0.5
+	// defaultResignLeaseTTL is the time a repository is locked for by the instance re-signing its metadata
+	defaultResignLeaseTTL = 5 * time.Minute
+	// resignLeasePrefix is the prefix of lease names of repositories
+	resignLeasePrefix = "resign:"
+)
+
+// resignRoles are roles re-signed online in the order of scanning,
+// re-signing of snapshot publishes timestamp, so snapshot goes first
+var resignRoles = []data.RoleType{data.RoleTypeSnapshot, data.RoleTypeTimestamp}
+
+// ResignSchedule is the schedule of background re-signing, defaults are used for zero values
+type ResignSchedule struct {
+	// Interval is the interval of scanning repositories for expiring metadata
+	Interval time.Duration
+	// Threshold is the fraction of metadata lifetime which may elapse before it is re-signed, between 0 and 1
+	Threshold float64
+	// LeaseTTL is the time a repository is locked for by the instance re-signing its metadata
+	LeaseTTL time.Duration
+}
+
+// ResignService re-signs snapshot and timestamp role metadata of repositories in background before it expires.
+// Server instances sharing database coordinate by leases, so metadata of a repository is re-signed by one instance.
+type ResignService struct {
+	log        logger.Logger
+	roleRepo   db.SignedRoleRepository
+	recordRepo db.ResignRecordRepository
+	leaseRepo  db.LeaseRepository
+	targetsSvc *TargetsService
+	// owner is the id of the server instance holding leases
+	owner    string
+	schedule ResignSchedule
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+// NewResignService creates new instance of services.ResignService,
+// owner identifies the server instance among instances sharing database
+func NewResignService(l logger.Logger, roleRepo db.SignedRoleRepository, recordRepo db.ResignRecordRepository,
+	leaseRepo db.LeaseRepository, targetsSvc *TargetsService, owner string, schedule ResignSchedule) *ResignService {
+	log := l.SetOperation("resign-service")
+	if schedule.Interval <= 0 {
+		schedule.Interval = defaultResignInterval
+	}
+	if schedule.Threshold <= 0 || schedule.Threshold >= 1 {
+		schedule.Threshold = defaultResignThreshold
+	}
+	if schedule.LeaseTTL <= 0 {
+		schedule.LeaseTTL = defaultResignLeaseTTL
+	}
+	return &ResignService{
+		log:        log,
+		roleRepo:   roleRepo,
+		recordRepo: recordRepo,
+		leaseRepo:  leaseRepo,
+		targetsSvc: targetsSvc,
+		owner:      owner,
+		schedule:   schedule,
+	}
+}
+
+// Start starts periodic re-signing of expiring metadata
+func (svc *ResignService) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	svc.cancel = cancel
+	svc.wg.Add(1)
+	go svc.scheduler(ctx)
+	svc.log.WithField("Owner", svc.owner).
+		WithField("Interval", svc.schedule.Interval).
+		Info("Metadata re-signing started")
+}
+
+// Stop stops periodic re-signing and waits until the current scan is completed
+func (svc *ResignService) Stop() {
+	if svc.cancel == nil {
+		return
+	}
+	svc.cancel()
+	svc.wg.Wait()
+	svc.log.Info("Metadata re-signing stopped")
+}
+
+// GetResignRecords returns outcomes of re-signing of the repository metadata, the newest records first
+func (svc *ResignService) GetResignRecords(ctx context.Context, repoID data.RepoID) ([]data.ResignRecord, error) {
+	return svc.recordRepo.FindByRepoID(ctx, repoID)
+}
+
+// Scan re-signs metadata of all repositories which passed the threshold of its lifetime
+func (svc *ResignService) Scan(ctx context.Context) {
+	log := svc.log.WithContext(ctx)
+	expiring := make(map[data.RepoID]data.RoleType)
+	for _, role := range resignRoles {
+		roles, err := svc.roleRepo.FindExpiring(ctx, role, svc.resignBefore(role))
+		if err != nil {
+			log.WithError(err).
+				WithField("Role", role).
+				Error("Failed to look up expiring metadata")
+			continue
+		}
+		for _, obj := range roles {
+			if _, ok := expiring[obj.RepoID]; !ok {
+				expiring[obj.RepoID] = obj.Role
+			}
+		}
+	}
+	for repoID, role := range expiring {
+		if ctx.Err() != nil {
+			return
+		}
+		svc.resign(ctx, repoID, role)
+	}
+}
+
+func (svc *ResignService) scheduler(ctx context.Context) {
+	defer svc.wg.Done()
+	ticker := time.NewTicker(svc.schedule.Interval)
+	defer ticker.Stop()
+	for {
+		svc.Scan(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// resign re-signs role metadata of the repository holding the lease of the repository and records the outcome
+func (svc *ResignService) resign(ctx context.Context, repoID data.RepoID, role data.RoleType) {
+	log := svc.log.WithContext(ctx).
+		WithField("RepoID", repoID).
+		WithField("Role", role)
+	lease := resignLeasePrefix + repoID.String()
+	ok, err := svc.leaseRepo.Acquire(ctx, lease, svc.owner, svc.schedule.LeaseTTL)
+	if err != nil {
+		log.WithError(err).
+			Error("Failed to acquire repository lease")
+		return
+	}
+	if !ok {
+		log.Debug("Repository is re-signed by another instance")
+		return
+	}
+	defer func() {
+		// lease is released even if the scan is stopped
+		if err := svc.leaseRepo.Release(context.Background(), lease, svc.owner); err != nil {
+			log.WithError(err).
+				Warn("Failed to release repository lease")
+		}
+	}()
+	// metadata may be re-signed by another instance after the scan
+	latest, err := svc.roleRepo.FindLatest(ctx, repoID, role)
+	if err != nil {
+		log.WithError(err).
+			Error("Failed to look up metadata")
+		return
+	}
+	if !latest.ExpiresAt.Before(svc.resignBefore(role)) {
+		return
+	}
+	rec := data.ResignRecord{
+		RepoID: repoID,
+		Role:   role,
+		Owner:  svc.owner,
+	}
+	obj, err := svc.targetsSvc.ResignRole(ctx, repoID, role)
+	if err != nil {
+		log.WithError(err).
+			Error("Metadata re-signing failed")
+		rec.Error = err.Error()
+	} else {
+		log.WithField("Version", obj.Version).
+			Info("Metadata re-signed")
+		rec.Version = obj.Version
+	}
+	rec.CreatedAt = time.Now().UTC()
+	if err = svc.recordRepo.Create(ctx, rec); err != nil {
+		log.WithError(err).
+			Error("Failed to record metadata re-signing")
+	}
+}
+
+// resignBefore returns the time metadata of the role expiring before has to be re-signed,
+// the time is computed from the lifetime of the role metadata published now
+func (svc *ResignService) resignBefore(role data.RoleType) time.Time {
+	now := time.Now().UTC()
+	lifetime := data.DefaultExpires(role).Sub(now)
+	return now.Add(time.Duration(float64(lifetime) * (1 - svc.schedule.Threshold)))
+}
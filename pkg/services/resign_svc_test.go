@@ -0,0 +1,153 @@
+package services_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shuvava/go-logging/logger"
+
+	"github.com/shuvava/ota-tuf-server/pkg/data"
+	"github.com/shuvava/ota-tuf-server/pkg/services"
+)
+
+func TestResignService(t *testing.T) {
+	ctx := context.Background()
+	newResignService := func(s *testServices, records *memResignRecordRepo, leases *memLeaseRepo, owner string) *services.ResignService {
+		return services.NewResignService(logger.NewNopLogger(), s.roleRepo, records, leases, s.targetsSvc, owner,
+			services.ResignSchedule{Threshold: 0.5})
+	}
+	// newPublishedRepo creates repository with published snapshot and timestamp
+	newPublishedRepo := func(t *testing.T, s *testServices) data.RepoID {
+		t.Helper()
+		repoID := s.createTestRepo(t)
+		if _, err := s.targetsSvc.GetSignedTimestamp(ctx, repoID); err != nil {
+			t.Fatalf("unable to get timestamp: %v", err)
+		}
+		return repoID
+	}
+	// extendLifetime makes metadata of the role published with default lifetime pass the threshold
+	extendLifetime := func(t *testing.T, role data.RoleType) {
+		t.Helper()
+		data.SetExpiresPeriod(role, 30*24*time.Hour)
+		t.Cleanup(func() { data.SetExpiresPeriod(role, 0) })
+	}
+	latestVersion := func(t *testing.T, s *testServices, repoID data.RepoID, role data.RoleType) int {
+		t.Helper()
+		obj, err := s.roleRepo.FindLatest(ctx, repoID, role)
+		if err != nil {
+			t.Fatalf("unable to get %s: %v", role, err)
+		}
+		return obj.Version
+	}
+
+	t.Run("should re-sign expiring timestamp", func(t *testing.T) {
+		s := newTestServices()
+		repoID := newPublishedRepo(t, s)
+		extendLifetime(t, data.RoleTypeTimestamp)
+		records := &memResignRecordRepo{}
+		svc := newResignService(s, records, &memLeaseRepo{}, "instance-1")
+		svc.Scan(ctx)
+		if v := latestVersion(t, s, repoID, data.RoleTypeTimestamp); v != 2 {
+			t.Errorf("expected timestamp version 2, got %d", v)
+		}
+		if v := latestVersion(t, s, repoID, data.RoleTypeSnapshot); v != 1 {
+			t.Errorf("expected snapshot version 1, got %d", v)
+		}
+		timestamp, _ := s.targetsSvc.GetSignedTimestamp(ctx, repoID)
+		s.verifyRole(t, timestamp)
+		recs, _ := svc.GetResignRecords(ctx, repoID)
+		if len(recs) != 1 || recs[0].Role != data.RoleTypeTimestamp || recs[0].Version != 2 ||
+			recs[0].Owner != "instance-1" || recs[0].Error != "" {
+			t.Errorf("expected record of timestamp version 2, got %v", recs)
+		}
+
+		svc.Scan(ctx)
+		if v := latestVersion(t, s, repoID, data.RoleTypeTimestamp); v != 2 {
+			t.Errorf("expected re-signed timestamp to be kept, got version %d", v)
+		}
+	})
+	t.Run("should re-sign expiring snapshot with timestamp", func(t *testing.T) {
+		s := newTestServices()
+		repoID := newPublishedRepo(t, s)
+		extendLifetime(t, data.RoleTypeSnapshot)
+		records := &memResignRecordRepo{}
+		svc := newResignService(s, records, &memLeaseRepo{}, "instance-1")
+		svc.Scan(ctx)
+		snapshot, _ := s.targetsSvc.GetSignedSnapshot(ctx, repoID)
+		if snapshot.Version != 2 {
+			t.Errorf("expected snapshot version 2, got %d", snapshot.Version)
+		}
+		s.verifyRole(t, snapshot)
+		timestamp, _ := s.targetsSvc.GetSignedTimestamp(ctx, repoID)
+		var timestampRole data.TimestampRole
+		if err := timestamp.Content.DecodeSigned(&timestampRole); err != nil {
+			t.Fatalf("unable to decode timestamp: %v", err)
+		}
+		assertListed(t, timestampRole.Meta, snapshot)
+		if recs, _ := svc.GetResignRecords(ctx, repoID); len(recs) != 1 || recs[0].Role != data.RoleTypeSnapshot {
+			t.Errorf("expected one record of snapshot, got %v", recs)
+		}
+	})
+	t.Run("should skip repository leased by another instance", func(t *testing.T) {
+		s := newTestServices()
+		repoID := newPublishedRepo(t, s)
+		extendLifetime(t, data.RoleTypeTimestamp)
+		leases := &memLeaseRepo{}
+		if ok, _ := leases.Acquire(ctx, "resign:"+repoID.String(), "instance-2", time.Minute); !ok {
+			t.Fatal("unable to acquire lease")
+		}
+		records := &memResignRecordRepo{}
+		newResignService(s, records, leases, "instance-1").Scan(ctx)
+		if v := latestVersion(t, s, repoID, data.RoleTypeTimestamp); v != 1 {
+			t.Errorf("expected timestamp version 1, got %d", v)
+		}
+		if len(records.records) != 0 {
+			t.Errorf("expected no records, got %v", records.records)
+		}
+	})
+	t.Run("should re-sign repository once by concurrent instances", func(t *testing.T) {
+		s := newTestServices()
+		repoID := newPublishedRepo(t, s)
+		extendLifetime(t, data.RoleTypeTimestamp)
+		records := &memResignRecordRepo{}
+		leases := &memLeaseRepo{}
+		var wg sync.WaitGroup
+		for _, owner := range []string{"instance-1", "instance-2", "instance-3"} {
+			wg.Add(1)
+			go func(svc *services.ResignService) {
+				defer wg.Done()
+				svc.Scan(ctx)
+			}(newResignService(s, records, leases, owner))
+		}
+		wg.Wait()
+		if v := latestVersion(t, s, repoID, data.RoleTypeTimestamp); v != 2 {
+			t.Errorf("expected timestamp version 2, got %d", v)
+		}
+		if len(records.records) != 1 {
+			t.Errorf("expected one record, got %v", records.records)
+		}
+	})
+	t.Run("should record failure", func(t *testing.T) {
+		s := newTestServices()
+		repoID := newPublishedRepo(t, s)
+		extendLifetime(t, data.RoleTypeTimestamp)
+		keys, _ := s.keyRepo.FindByRole(ctx, repoID, data.RoleTypeTimestamp)
+		for _, key := range keys {
+			_ = s.keyRepo.Delete(ctx, repoID, key.KeyID)
+		}
+		records := &memResignRecordRepo{}
+		newResignService(s, records, &memLeaseRepo{}, "instance-1").Scan(ctx)
+		if len(records.records) != 1 || records.records[0].Error == "" || records.records[0].Version != 0 {
+			t.Errorf("expected record of failure, got %v", records.records)
+		}
+	})
+	t.Run("should not re-sign offline roles", func(t *testing.T) {
+		s := newTestServices()
+		repoID := newPublishedRepo(t, s)
+		if _, err := s.targetsSvc.ResignRole(ctx, repoID, data.RoleTypeRoot); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+}
@@ -0,0 +1,207 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/shuvava/go-ota-svc-common/apperrors"
+
+	"github.com/shuvava/ota-tuf-server/pkg/data"
+	"github.com/shuvava/ota-tuf-server/pkg/encryption"
+)
+
+// GetUnsignedRoot returns signed portion of the next version of root role metadata of the repository.
+// It is signed offline and uploaded back by PublishSignedRoot.
+func (svc *RootRoleService) GetUnsignedRoot(ctx context.Context, repoID data.RepoID) (*data.RootRole, error) {
+	repo, err := svc.getRepo(ctx, repoID)
+	if err != nil {
+		return nil, err
+	}
+	latest, err := svc.GetSignedRoot(ctx, repoID)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := svc.getRepoKeys(ctx, repoID)
+	if err != nil {
+		return nil, err
+	}
+	return newRootRole(*repo, keys, latest.Version+1)
+}
+
+// PublishSignedRoot publishes the next version of root role metadata signed offline.
+// The root must be signed by threshold of root keys of both the previous and the new version.
+// Keys of the new root unknown to the repository are stored without private part,
+// keys missing in the new root are retired.
+func (svc *RootRoleService) PublishSignedRoot(ctx context.Context, repoID data.RepoID, payload data.SignedPayload) (*data.SignedRole, error) {
+	log := svc.log.WithContext(ctx).
+		WithField("RepoID", repoID)
+	var root data.RootRole
+	if err := payload.DecodeSigned(&root); err != nil {
+		return nil, err
+	}
+	if err := root.Validate(); err != nil {
+		return nil, err
+	}
+	if root.IsExpired(time.Now()) {
+		return nil, apperrors.NewAppError(apperrors.ErrorDataValidation, "tuf: root is expired")
+	}
+	latest, err := svc.GetSignedRoot(ctx, repoID)
+	if err != nil {
+		return nil, err
+	}
+	if root.Version != latest.Version+1 {
+		return nil, apperrors.NewAppError(apperrors.ErrorDataValidation,
+			fmt.Sprintf("tuf: expected root version %d, got %d", latest.Version+1, root.Version))
+	}
+	var prev data.RootRole
+	if err = latest.Content.DecodeSigned(&prev); err != nil {
+		return nil, err
+	}
+	if err = encryption.VerifyPayload(&payload, prev.Keys, prev.Roles[data.RoleTypeRoot]); err != nil {
+		return nil, err
+	}
+	if err = encryption.VerifyPayload(&payload, root.Keys, root.Roles[data.RoleTypeRoot]); err != nil {
+		return nil, err
+	}
+
+	keys, err := svc.getRepoKeys(ctx, repoID)
+	if err != nil {
+		return nil, err
+	}
+	nextKeys, err := rootRepoKeys(repoID, root)
+	if err != nil {
+		return nil, err
+	}
+	current := make(map[data.KeyID]data.RepoKey, len(keys))
+	for _, key := range keys {
+		current[key.KeyID] = key
+	}
+	var (
+		created []data.RepoKey
+		moved   []data.RepoKey
+	)
+	rollback := func() {
+		for _, key := range created {
+			if err := svc.keyRepo.Delete(ctx, repoID, key.KeyID); err != nil {
+				log.WithError(err).
+					WithField("KeyID", key.KeyID).
+					Error("Failed to delete key of rejected root")
+			}
+		}
+	}
+	for _, key := range nextKeys {
+		old, ok := current[key.KeyID]
+		if ok {
+			delete(current, key.KeyID)
+			if old.Role != key.Role {
+				// key keeps its private part if any
+				old.Role = key.Role
+				moved = append(moved, old)
+			}
+			continue
+		}
+		if err = svc.keyRepo.Create(ctx, key); err != nil {
+			rollback()
+			return nil, err
+		}
+		created = append(created, key)
+	}
+
+	obj := data.SignedRole{
+		RepoID:    repoID,
+		Role:      data.RoleTypeRoot,
+		Version:   root.Version,
+		ExpiresAt: root.Expires,
+		Content:   payload,
+	}
+	if err = svc.roleRepo.Create(ctx, obj); err != nil {
+		rollback()
+		return nil, err
+	}
+	log = log.WithField("Version", root.Version)
+	log.Info("Offline signed root role published")
+
+	// root is already published, the rest is best effort
+	for _, key := range moved {
+		if err = svc.keyRepo.Update(ctx, key); err != nil {
+			log.WithError(err).
+				WithField("KeyID", key.KeyID).
+				Error("Failed to move key to new role")
+		}
+	}
+	for keyID := range current {
+		if err = svc.keyRepo.Delete(ctx, repoID, keyID); err != nil {
+			log.WithError(err).
+				WithField("KeyID", keyID).
+				Error("Failed to retire key")
+		}
+	}
+	if err = svc.saveRepoConfig(ctx, repoID, root); err != nil {
+		log.WithError(err).
+			Error("Failed to update repository configuration")
+	}
+	return &obj, nil
+}
+
+// saveRepoConfig persists thresholds and number of keys of roles defined in root role metadata
+func (svc *RootRoleService) saveRepoConfig(ctx context.Context, repoID data.RepoID, root data.RootRole) error {
+	repo, err := svc.getRepo(ctx, repoID)
+	if err != nil {
+		return err
+	}
+	for role, roleKeys := range root.Roles {
+		cfg := repo.Roles[role]
+		cfg.Threshold = roleKeys.Threshold
+		cfg.KeyCount = len(roleKeys.KeyIDs)
+		repo.Roles[role] = cfg
+	}
+	err = svc.repoRepo.Update(ctx, *repo)
+	if isNotFound(err) {
+		return svc.repoRepo.Create(ctx, *repo)
+	}
+	return err
+}
+
+// rootRepoKeys returns public keys of top-level roles of root role metadata in the native encoding.
+// Published key ids are checked against the published keys, repository keys get ids of the native form.
+func rootRepoKeys(repoID data.RepoID, root data.RootRole) ([]data.RepoKey, error) {
+	roles := make(map[data.KeyID]data.RoleType, len(root.Keys))
+	res := make([]data.RepoKey, 0, len(root.Keys))
+	for role := range data.TopLevelRoles {
+		for _, keyID := range root.Roles[role].KeyIDs {
+			if other, ok := roles[keyID]; ok {
+				if other == role {
+					continue
+				}
+				return nil, apperrors.NewAppError(apperrors.ErrorDataValidation,
+					fmt.Sprintf("key '%s' is shared by roles '%s' and '%s', it is not supported", keyID, other, role))
+			}
+			key := root.Keys[keyID]
+			published, err := encryption.PublishedKeyID(&key)
+			if err != nil {
+				return nil, err
+			}
+			if published != keyID {
+				return nil, apperrors.NewAppError(apperrors.ErrorDataValidation,
+					fmt.Sprintf("key id '%s' does not match key of role '%s', expected '%s'", keyID, role, published))
+			}
+			pub, err := encryption.PublicKey(&key)
+			if err != nil {
+				return nil, err
+			}
+			nativeID, err := encryption.ComputeKeyID(pub)
+			if err != nil {
+				return nil, err
+			}
+			roles[keyID] = role
+			res = append(res, data.RepoKey{
+				RepoID: repoID,
+				Role:   role,
+				KeyID:  nativeID,
+				Key:    *pub,
+			})
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,170 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/shuvava/ota-tuf-server/pkg/data"
+	"github.com/shuvava/ota-tuf-server/pkg/encryption"
+)
+
+// newOfflineKey generates key which private part is kept outside of the server
+func newOfflineKey(t *testing.T) data.RepoKey {
+	t.Helper()
+	key, err := encryption.NewKey(data.KeyTypeEd25519)
+	if err != nil {
+		t.Fatalf("unable to generate key: %v", err)
+	}
+	dtKey, _ := key.MarshalAllData()
+	keyID, err := encryption.ComputeKeyID(dtKey)
+	if err != nil {
+		t.Fatalf("unable to compute key id: %v", err)
+	}
+	return data.RepoKey{Role: data.RoleTypeRoot, KeyID: keyID, Key: *dtKey}
+}
+
+func TestPublishSignedRoot(t *testing.T) {
+	ctx := context.Background()
+	// prepareRoot returns the next root with root key replaced by offline one and server root key
+	prepareRoot := func(t *testing.T, s *testServices, repoID data.RepoID, offline data.RepoKey) (*data.RootRole, data.RepoKey) {
+		t.Helper()
+		root, err := s.rootSvc.GetUnsignedRoot(ctx, repoID)
+		if err != nil {
+			t.Fatalf("unable to get unsigned root: %v", err)
+		}
+		serverKeys, _ := s.keyRepo.FindByRole(ctx, repoID, data.RoleTypeRoot)
+		serverKey := serverKeys[0]
+		pub, _ := encryption.UnmarshalKey(&offline.Key)
+		offlinePub, _ := pub.MarshalPublicData()
+		delete(root.Keys, serverKey.KeyID)
+		root.Keys[offline.KeyID] = *offlinePub
+		root.Roles[data.RoleTypeRoot].KeyIDs = []data.KeyID{offline.KeyID}
+		return root, serverKey
+	}
+	t.Run("should publish root signed by old and new root keys", func(t *testing.T) {
+		s := newTestServices()
+		repoID := s.createTestRepo(t)
+		offline := newOfflineKey(t)
+		root, serverKey := prepareRoot(t, s, repoID, offline)
+		if root.Version != 2 {
+			t.Fatalf("expected unsigned version 2, got %d", root.Version)
+		}
+		signed, err := encryption.SignPayload(root, []data.RepoKey{serverKey, offline})
+		if err != nil {
+			t.Fatalf("unable to sign root: %v", err)
+		}
+		published, err := s.rootSvc.PublishSignedRoot(ctx, repoID, *signed)
+		if err != nil {
+			t.Fatalf("unable to publish root: %v", err)
+		}
+		if published.Version != 2 {
+			t.Errorf("expected version 2, got %d", published.Version)
+		}
+		if exists, _ := s.keyRepo.Exists(ctx, repoID, serverKey.KeyID); exists {
+			t.Error("server root key should be retired")
+		}
+		stored, err := s.keyRepo.FindByKeyID(ctx, repoID, offline.KeyID)
+		if err != nil {
+			t.Fatalf("offline key is not stored: %v", err)
+		}
+		if encryption.HasPrivateKey(&stored.Key) {
+			t.Error("offline key should be stored without private part")
+		}
+		next, err := s.rootSvc.GetUnsignedRoot(ctx, repoID)
+		if err != nil {
+			t.Fatalf("unable to get unsigned root: %v", err)
+		}
+		if next.Version != 3 || next.Roles[data.RoleTypeRoot].KeyIDs[0] != offline.KeyID {
+			t.Errorf("unexpected next root %+v", next.Roles[data.RoleTypeRoot])
+		}
+	})
+	t.Run("should reject root not signed by previous root keys", func(t *testing.T) {
+		s := newTestServices()
+		repoID := s.createTestRepo(t)
+		offline := newOfflineKey(t)
+		root, _ := prepareRoot(t, s, repoID, offline)
+		signed, _ := encryption.SignPayload(root, []data.RepoKey{offline})
+		if _, err := s.rootSvc.PublishSignedRoot(ctx, repoID, *signed); err == nil {
+			t.Fatal("expected error, got nil")
+		}
+		if exists, _ := s.keyRepo.Exists(ctx, repoID, offline.KeyID); exists {
+			t.Error("key of rejected root should not be stored")
+		}
+	})
+	t.Run("should reject root not signed by new root keys", func(t *testing.T) {
+		s := newTestServices()
+		repoID := s.createTestRepo(t)
+		root, serverKey := prepareRoot(t, s, repoID, newOfflineKey(t))
+		signed, _ := encryption.SignPayload(root, []data.RepoKey{serverKey})
+		if _, err := s.rootSvc.PublishSignedRoot(ctx, repoID, *signed); err == nil {
+			t.Fatal("expected error, got nil")
+		}
+	})
+	t.Run("should reject unexpected version", func(t *testing.T) {
+		s := newTestServices()
+		repoID := s.createTestRepo(t)
+		offline := newOfflineKey(t)
+		root, serverKey := prepareRoot(t, s, repoID, offline)
+		root.Version = 3
+		signed, _ := encryption.SignPayload(root, []data.RepoKey{serverKey, offline})
+		if _, err := s.rootSvc.PublishSignedRoot(ctx, repoID, *signed); err == nil {
+			t.Fatal("expected error, got nil")
+		}
+	})
+	t.Run("should store keys of foreign encoding by native key ids", func(t *testing.T) {
+		s := newTestServices()
+		repo := data.NewRepo(data.NewRepoID(), data.KeyTypeEd25519)
+		repo.KeyEncoding = data.KeyEncodingAktualizr
+		if err := s.keySvc.CreateNewRepository(ctx, repo); err != nil {
+			t.Fatalf("unable to create repository: %v", err)
+		}
+		root, err := s.rootSvc.GetUnsignedRoot(ctx, repo.RepoID)
+		if err != nil {
+			t.Fatalf("unable to get unsigned root: %v", err)
+		}
+		serverKeys, _ := s.keyRepo.FindByRole(ctx, repo.RepoID, data.RoleTypeRoot)
+		offline := newOfflineKey(t)
+		pub, _ := encryption.EncodePublicKey(&offline.Key, data.KeyEncodingAktualizr)
+		publishedID, _ := encryption.PublishedKeyID(pub)
+		root.Keys[publishedID] = *pub
+		root.Roles[data.RoleTypeRoot].KeyIDs = append(root.Roles[data.RoleTypeRoot].KeyIDs, publishedID)
+		signed, _ := encryption.SignPayloadWithEncoding(root, []data.RepoKey{serverKeys[0], offline}, data.KeyEncodingAktualizr)
+		if _, err = s.rootSvc.PublishSignedRoot(ctx, repo.RepoID, *signed); err != nil {
+			t.Fatalf("unable to publish root: %v", err)
+		}
+		if exists, _ := s.keyRepo.Exists(ctx, repo.RepoID, offline.KeyID); !exists {
+			t.Error("offline key should be stored by native key id")
+		}
+		if exists, _ := s.keyRepo.Exists(ctx, repo.RepoID, serverKeys[0].KeyID); !exists {
+			t.Error("server root key should be kept")
+		}
+	})
+	t.Run("should reject key id not matching the key", func(t *testing.T) {
+		s := newTestServices()
+		repoID := s.createTestRepo(t)
+		offline := newOfflineKey(t)
+		root, serverKey := prepareRoot(t, s, repoID, offline)
+		// signatures are valid, the key is published under id of another key
+		other := newOfflineKey(t)
+		otherPub, _ := encryption.PublicKey(&other.Key)
+		root.Keys[offline.KeyID] = *otherPub
+		other.KeyID = offline.KeyID
+		signed, _ := encryption.SignPayload(root, []data.RepoKey{serverKey, other})
+		if _, err := s.rootSvc.PublishSignedRoot(ctx, repoID, *signed); err == nil {
+			t.Fatal("expected error, got nil")
+		}
+	})
+	t.Run("should reject modified signed portion", func(t *testing.T) {
+		s := newTestServices()
+		repoID := s.createTestRepo(t)
+		offline := newOfflineKey(t)
+		root, serverKey := prepareRoot(t, s, repoID, offline)
+		signed, _ := encryption.SignPayload(root, []data.RepoKey{serverKey, offline})
+		root.ConsistentSnapshot = true
+		signed.Signed, _ = json.Marshal(root)
+		if _, err := s.rootSvc.PublishSignedRoot(ctx, repoID, *signed); err == nil {
+			t.Fatal("expected error, got nil")
+		}
+	})
+}
@@ -0,0 +1,139 @@
+package services
+
+import (
+	"context"
+
+	"github.com/shuvava/go-ota-svc-common/apperrors"
+
+	"github.com/shuvava/ota-tuf-server/pkg/data"
+	"github.com/shuvava/ota-tuf-server/pkg/errcodes"
+)
+
+// RotateKeysRequest is the request to replace all keys of a repository role
+type RotateKeysRequest struct {
+	// Role is the role which keys are replaced
+	Role data.RoleType
+	// KeyType is the type of generated keys, key type of the repository role is used if empty
+	KeyType data.KeyType
+	// Keys is the list of new keys, new keys are generated if empty
+	Keys []data.Key
+}
+
+// RotateKeys replaces all keys of the repository role by new ones and publishes the next version of root role metadata.
+// Root role metadata is signed by both old and new root keys, old keys are retired after publishing.
+func (svc *RootRoleService) RotateKeys(ctx context.Context, repoID data.RepoID, req RotateKeysRequest) (*data.SignedRole, error) {
+	log := svc.log.WithContext(ctx).
+		WithField("RepoID", repoID).
+		WithField("Role", req.Role)
+	repo, err := svc.getRepo(ctx, repoID)
+	if err != nil {
+		return nil, err
+	}
+	latest, err := svc.GetSignedRoot(ctx, repoID)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := svc.getRepoKeys(ctx, repoID)
+	if err != nil {
+		return nil, err
+	}
+	oldRoleKeys := filterKeysByRole(keys, req.Role)
+	newRoleKeys, err := svc.newRotationKeys(*repo, req, len(oldRoleKeys))
+	if err != nil {
+		return nil, err
+	}
+	if len(newRoleKeys) < repo.Threshold(req.Role) {
+		return nil, apperrors.NewAppError(apperrors.ErrorDataValidation,
+			"number of new keys is less than threshold of role '"+string(req.Role)+"'")
+	}
+
+	// signers of the next version are old root keys and new root keys in case of root rotation
+	signers := privateKeys(filterKeysByRole(keys, data.RoleTypeRoot))
+	if len(signers) < repo.Threshold(data.RoleTypeRoot) {
+		return nil, apperrors.NewAppError(errcodes.ErrorDataSigningNoPrivateKey,
+			"not enough private root keys to sign root role, it should be signed offline")
+	}
+	nextKeys := make([]data.RepoKey, 0, len(keys)+len(newRoleKeys))
+	for _, key := range keys {
+		if key.Role != req.Role {
+			nextKeys = append(nextKeys, key)
+		}
+	}
+	nextKeys = append(nextKeys, newRoleKeys...)
+	if req.Role == data.RoleTypeRoot {
+		newRootKeys := privateKeys(newRoleKeys)
+		if len(newRootKeys) < repo.Threshold(data.RoleTypeRoot) {
+			return nil, apperrors.NewAppError(errcodes.ErrorDataSigningNoPrivateKey,
+				"not enough private root keys to sign root role, it should be signed offline")
+		}
+		signers = append(signers, newRootKeys...)
+	}
+
+	var created []data.RepoKey
+	rollback := func() {
+		for _, key := range created {
+			if err := svc.keyRepo.Delete(ctx, repoID, key.KeyID); err != nil {
+				log.WithError(err).
+					WithField("KeyID", key.KeyID).
+					Error("Failed to delete key of failed rotation")
+			}
+		}
+	}
+	for _, key := range newRoleKeys {
+		if err = svc.keyRepo.Create(ctx, key); err != nil {
+			rollback()
+			return nil, err
+		}
+		created = append(created, key)
+	}
+	root, err := svc.publishVersion(ctx, *repo, nextKeys, signers, latest.Version+1)
+	if err != nil {
+		rollback()
+		return nil, err
+	}
+	for _, key := range oldRoleKeys {
+		if err = svc.keyRepo.Delete(ctx, repoID, key.KeyID); err != nil {
+			// root is already published, retired key is not trusted anymore
+			log.WithError(err).
+				WithField("KeyID", key.KeyID).
+				Error("Failed to retire key")
+		}
+	}
+	log.WithField("Version", root.Version).
+		Info("Role keys rotated")
+	return root, nil
+}
+
+// newRotationKeys returns keys provided in the request or generates count new keys
+func (svc *RootRoleService) newRotationKeys(repo data.Repo, req RotateKeysRequest, count int) ([]data.RepoKey, error) {
+	res := make([]data.RepoKey, 0, count)
+	if len(req.Keys) > 0 {
+		for _, key := range req.Keys {
+			repoKey, err := newRepoKey(repo.RepoID, req.Role, key)
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, *repoKey)
+		}
+		return res, nil
+	}
+	keyType := req.KeyType
+	if keyType == "" {
+		keyType = repo.RoleKeyType(req.Role)
+	}
+	if keyType == "" {
+		keyType = data.KeyTypeRSA
+	}
+	cfg := repo.Roles[req.Role]
+	if cfg.KeyCount > count {
+		count = cfg.KeyCount
+	}
+	for i := 0; i < count; i++ {
+		key, err := generateRepoKey(repo.RepoID, req.Role, keyType, cfg.Backend)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, *key)
+	}
+	return res, nil
+}
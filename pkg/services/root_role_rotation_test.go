@@ -0,0 +1,181 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/shuvava/ota-tuf-server/pkg/cjson"
+	"github.com/shuvava/ota-tuf-server/pkg/data"
+	"github.com/shuvava/ota-tuf-server/pkg/encryption"
+	"github.com/shuvava/ota-tuf-server/pkg/services"
+)
+
+// verifiedBy returns ids of keys which signatures of the payload are valid
+func verifiedBy(t *testing.T, payload data.SignedPayload, keys map[data.KeyID]data.Key) map[data.KeyID]bool {
+	t.Helper()
+	msg, err := cjson.Canonicalize(payload.Signed)
+	if err != nil {
+		t.Fatalf("unable to canonicalize payload: %v", err)
+	}
+	res := map[data.KeyID]bool{}
+	for _, sig := range payload.Signatures {
+		key, ok := keys[sig.KeyID]
+		if !ok {
+			continue
+		}
+		verifier, err := encryption.UnmarshalKey(&key)
+		if err != nil {
+			t.Fatalf("unable to unmarshal key: %v", err)
+		}
+		if verifier.Verify(msg, sig.Signature) == nil {
+			res[sig.KeyID] = true
+		}
+	}
+	return res
+}
+
+func TestRotateKeys(t *testing.T) {
+	ctx := context.Background()
+	t.Run("root rotation should be signed by old and new root keys", func(t *testing.T) {
+		s := newTestServices()
+		repoID := s.createTestRepo(t)
+		v1, err := s.rootSvc.GetSignedRoot(ctx, repoID)
+		if err != nil {
+			t.Fatalf("unable to get root: %v", err)
+		}
+		var oldRoot data.RootRole
+		if err = v1.Content.DecodeSigned(&oldRoot); err != nil {
+			t.Fatalf("unable to decode root: %v", err)
+		}
+
+		v2, err := s.rootSvc.RotateKeys(ctx, repoID, services.RotateKeysRequest{Role: data.RoleTypeRoot})
+		if err != nil {
+			t.Fatalf("unable to rotate keys: %v", err)
+		}
+		if v2.Version != 2 {
+			t.Errorf("expected version 2, got %d", v2.Version)
+		}
+		var newRoot data.RootRole
+		if err = v2.Content.DecodeSigned(&newRoot); err != nil {
+			t.Fatalf("unable to decode root: %v", err)
+		}
+		oldKeyID := oldRoot.Roles[data.RoleTypeRoot].KeyIDs[0]
+		newKeyID := newRoot.Roles[data.RoleTypeRoot].KeyIDs[0]
+		if oldKeyID == newKeyID {
+			t.Fatal("root key was not replaced")
+		}
+		verified := verifiedBy(t, v2.Content, map[data.KeyID]data.Key{
+			oldKeyID: oldRoot.Keys[oldKeyID],
+			newKeyID: newRoot.Keys[newKeyID],
+		})
+		if !verified[oldKeyID] || !verified[newKeyID] {
+			t.Errorf("expected signatures of old and new root keys, got %v", verified)
+		}
+		if exists, _ := s.keyRepo.Exists(ctx, repoID, oldKeyID); exists {
+			t.Error("old root key should be retired")
+		}
+	})
+	t.Run("targets rotation should keep root keys", func(t *testing.T) {
+		s := newTestServices()
+		repoID := s.createTestRepo(t)
+		v1, _ := s.rootSvc.GetSignedRoot(ctx, repoID)
+		var oldRoot data.RootRole
+		_ = v1.Content.DecodeSigned(&oldRoot)
+
+		v2, err := s.rootSvc.RotateKeys(ctx, repoID, services.RotateKeysRequest{Role: data.RoleTypeTargets})
+		if err != nil {
+			t.Fatalf("unable to rotate keys: %v", err)
+		}
+		var newRoot data.RootRole
+		_ = v2.Content.DecodeSigned(&newRoot)
+		if newRoot.Roles[data.RoleTypeRoot].KeyIDs[0] != oldRoot.Roles[data.RoleTypeRoot].KeyIDs[0] {
+			t.Error("root key should not be changed")
+		}
+		if newRoot.Roles[data.RoleTypeTargets].KeyIDs[0] == oldRoot.Roles[data.RoleTypeTargets].KeyIDs[0] {
+			t.Error("targets key should be changed")
+		}
+		if err = newRoot.Validate(); err != nil {
+			t.Errorf("new root is invalid: %v", err)
+		}
+	})
+	t.Run("rotation should re-sign metadata of the role and roles describing it", func(t *testing.T) {
+		for _, role := range []data.RoleType{data.RoleTypeTargets, data.RoleTypeSnapshot, data.RoleTypeTimestamp} {
+			t.Run(string(role), func(t *testing.T) {
+				s := newTestServices()
+				repoID := s.createTestRepo(t)
+				if _, err := s.targetsSvc.GetSignedTimestamp(ctx, repoID); err != nil {
+					t.Fatalf("unable to get timestamp: %v", err)
+				}
+				if _, err := s.rootSvc.RotateKeys(ctx, repoID, services.RotateKeysRequest{Role: role}); err != nil {
+					t.Fatalf("unable to rotate keys: %v", err)
+				}
+				if err := s.targetsSvc.RefreshRotatedRole(ctx, repoID, role); err != nil {
+					t.Fatalf("unable to refresh metadata: %v", err)
+				}
+				targets, err := s.targetsSvc.GetSignedTargets(ctx, repoID)
+				if err != nil {
+					t.Fatalf("unable to get targets: %v", err)
+				}
+				s.verifyRole(t, targets)
+				snapshot, err := s.targetsSvc.GetSignedSnapshot(ctx, repoID)
+				if err != nil {
+					t.Fatalf("unable to get snapshot: %v", err)
+				}
+				s.verifyRole(t, snapshot)
+				timestamp, err := s.targetsSvc.GetSignedTimestamp(ctx, repoID)
+				if err != nil {
+					t.Fatalf("unable to get timestamp: %v", err)
+				}
+				s.verifyRole(t, timestamp)
+				var snapshotRole data.SnapshotRole
+				_ = snapshot.Content.DecodeSigned(&snapshotRole)
+				assertListed(t, snapshotRole.Meta, targets)
+				var timestampRole data.TimestampRole
+				_ = timestamp.Content.DecodeSigned(&timestampRole)
+				assertListed(t, timestampRole.Meta, snapshot)
+			})
+		}
+	})
+	t.Run("should accept provided keys", func(t *testing.T) {
+		s := newTestServices()
+		repoID := s.createTestRepo(t)
+		key, _ := encryption.GenerateEd25519Key()
+		pub, _ := key.MarshalPublicData()
+		keyID, _ := encryption.ComputeKeyID(pub)
+
+		v2, err := s.rootSvc.RotateKeys(ctx, repoID, services.RotateKeysRequest{
+			Role: data.RoleTypeSnapshot,
+			Keys: []data.Key{*pub},
+		})
+		if err != nil {
+			t.Fatalf("unable to rotate keys: %v", err)
+		}
+		var newRoot data.RootRole
+		_ = v2.Content.DecodeSigned(&newRoot)
+		if newRoot.Roles[data.RoleTypeSnapshot].KeyIDs[0] != keyID {
+			t.Errorf("expected key %s, got %v", keyID, newRoot.Roles[data.RoleTypeSnapshot].KeyIDs)
+		}
+	})
+	t.Run("root rotation to public only keys should fail", func(t *testing.T) {
+		s := newTestServices()
+		repoID := s.createTestRepo(t)
+		key, _ := encryption.GenerateEd25519Key()
+		pub, _ := key.MarshalPublicData()
+
+		_, err := s.rootSvc.RotateKeys(ctx, repoID, services.RotateKeysRequest{
+			Role: data.RoleTypeRoot,
+			Keys: []data.Key{*pub},
+		})
+		if err == nil {
+			t.Fatal("expected error, got nil")
+		}
+		versions, _ := s.rootSvc.ListRootVersions(ctx, repoID)
+		if len(versions) != 1 {
+			t.Errorf("expected only version 1, got %v", versions)
+		}
+		keys, _ := s.keyRepo.FindByRole(ctx, repoID, data.RoleTypeRoot)
+		if len(keys) != 1 {
+			t.Errorf("expected 1 root key, got %d", len(keys))
+		}
+	})
+}
@@ -0,0 +1,191 @@
+package services
+
+import (
+	"context"
+
+	"github.com/shuvava/go-logging/logger"
+	"github.com/shuvava/go-ota-svc-common/apperrors"
+
+	"github.com/shuvava/ota-tuf-server/internal/db"
+	"github.com/shuvava/ota-tuf-server/pkg/data"
+	"github.com/shuvava/ota-tuf-server/pkg/encryption"
+	"github.com/shuvava/ota-tuf-server/pkg/errcodes"
+)
+
+// RootRoleService builds, signs and publishes root role metadata of repositories
+type RootRoleService struct {
+	log      logger.Logger
+	keyRepo  db.KeyRepository
+	repoRepo db.RepoRepository
+	roleRepo db.SignedRoleRepository
+}
+
+// NewRootRoleService creates new instance of services.RootRoleService
+func NewRootRoleService(l logger.Logger, keyRepo db.KeyRepository, repoRepo db.RepoRepository, roleRepo db.SignedRoleRepository) *RootRoleService {
+	log := l.SetOperation("root-role-service")
+	return &RootRoleService{
+		log:      log,
+		keyRepo:  keyRepo,
+		repoRepo: repoRepo,
+		roleRepo: roleRepo,
+	}
+}
+
+// CreateRoot publishes the first version of root role metadata of the repository
+func (svc *RootRoleService) CreateRoot(ctx context.Context, repoID data.RepoID) (*data.SignedRole, error) {
+	keys, err := svc.getRepoKeys(ctx, repoID)
+	if err != nil {
+		return nil, err
+	}
+	repo, err := svc.getRepo(ctx, repoID)
+	if err != nil {
+		return nil, err
+	}
+	return svc.publishVersion(ctx, *repo, keys, privateKeys(filterKeysByRole(keys, data.RoleTypeRoot)), 1)
+}
+
+// GetSignedRoot returns the latest published version of root role metadata of the repository.
+// The first version is published if the repository does not have any yet.
+func (svc *RootRoleService) GetSignedRoot(ctx context.Context, repoID data.RepoID) (*data.SignedRole, error) {
+	root, err := svc.roleRepo.FindLatest(ctx, repoID, data.RoleTypeRoot)
+	if err == nil {
+		return root, nil
+	}
+	if !isNotFound(err) {
+		return nil, err
+	}
+	root, err = svc.CreateRoot(ctx, repoID)
+	if isAlreadyExist(err) {
+		// version was published by concurrent request
+		return svc.roleRepo.FindLatest(ctx, repoID, data.RoleTypeRoot)
+	}
+	return root, err
+}
+
+// GetSignedRootVersion returns published version of root role metadata of the repository
+func (svc *RootRoleService) GetSignedRootVersion(ctx context.Context, repoID data.RepoID, version int) (*data.SignedRole, error) {
+	return svc.roleRepo.FindVersion(ctx, repoID, data.RoleTypeRoot, version)
+}
+
+// ListRootVersions returns all published versions of root role metadata of the repository
+func (svc *RootRoleService) ListRootVersions(ctx context.Context, repoID data.RepoID) ([]int, error) {
+	versions, err := svc.roleRepo.ListVersions(ctx, repoID, data.RoleTypeRoot)
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) == 0 {
+		return nil, apperrors.NewAppError(errcodes.ErrorSvcRepoNotFound, "repository '"+repoID.String()+"' does not exist")
+	}
+	return versions, nil
+}
+
+// getRepoKeys returns all keys of the repository or error if repository does not exist
+func (svc *RootRoleService) getRepoKeys(ctx context.Context, repoID data.RepoID) ([]data.RepoKey, error) {
+	keys, err := svc.keyRepo.FindByRepoId(ctx, repoID)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return nil, apperrors.NewAppError(errcodes.ErrorSvcRepoNotFound, "repository '"+repoID.String()+"' does not exist")
+	}
+	return keys, nil
+}
+
+// getRepo returns repository configuration,
+// repositories created before configuration was persisted get the default one
+func (svc *RootRoleService) getRepo(ctx context.Context, repoID data.RepoID) (*data.Repo, error) {
+	repo, err := svc.repoRepo.FindByID(ctx, repoID)
+	if isNotFound(err) {
+		defRepo := data.NewRepo(repoID, "")
+		return &defRepo, nil
+	}
+	return repo, err
+}
+
+// publishVersion builds root role metadata of provided version from keys, signs it by signers and persists it
+func (svc *RootRoleService) publishVersion(ctx context.Context, repo data.Repo, keys, signers []data.RepoKey, version int) (*data.SignedRole, error) {
+	log := svc.log.WithContext(ctx)
+	repoID := repo.RepoID
+	if len(signers) < repo.Threshold(data.RoleTypeRoot) {
+		return nil, apperrors.NewAppError(errcodes.ErrorDataSigningNoPrivateKey,
+			"not enough private root keys to sign root role, it should be signed offline")
+	}
+	root, err := newRootRole(repo, keys, version)
+	if err != nil {
+		return nil, err
+	}
+	signed, err := encryption.SignPayloadWithEncoding(root, signers, repo.KeyEncoding)
+	if err != nil {
+		return nil, apperrors.CreateErrorAndLogIt(log, errcodes.ErrorDataSigning, "Failed to sign root role", err)
+	}
+	obj := data.SignedRole{
+		RepoID:    repoID,
+		Role:      data.RoleTypeRoot,
+		Version:   version,
+		ExpiresAt: root.Expires,
+		Content:   *signed,
+	}
+	if err = svc.roleRepo.Create(ctx, obj); err != nil {
+		return nil, err
+	}
+	log.WithField("RepoID", repoID).
+		WithField("Version", version).
+		Info("Root role published")
+	return &obj, nil
+}
+
+// newRootRole creates root role metadata of provided version from repository keys,
+// public keys are published in the key encoding of the repository under ids computed from the encoded form
+func newRootRole(repo data.Repo, keys []data.RepoKey, version int) (*data.RootRole, error) {
+	root := data.RootRole{
+		RoleHeader:         data.NewRoleHeader(data.RoleTypeRoot, version),
+		ConsistentSnapshot: false,
+		Keys:               make(map[data.KeyID]data.Key, len(keys)),
+		Roles:              make(map[data.RoleType]*data.RoleKeys, len(data.TopLevelRoles)),
+	}
+	for role := range data.TopLevelRoles {
+		root.Roles[role] = &data.RoleKeys{
+			KeyIDs:    []data.KeyID{},
+			Threshold: repo.Threshold(role),
+		}
+	}
+	for _, key := range keys {
+		pub, err := encryption.EncodePublicKey(&key.Key, repo.KeyEncoding)
+		if err != nil {
+			return nil, err
+		}
+		keyID, err := encryption.PublishedKeyID(pub)
+		if err != nil {
+			return nil, err
+		}
+		root.Keys[keyID] = *pub
+		roleKeys, ok := root.Roles[key.Role]
+		if !ok {
+			continue
+		}
+		roleKeys.KeyIDs = append(roleKeys.KeyIDs, keyID)
+	}
+	return &root, nil
+}
+
+// privateKeys returns keys usable for signing, either containing private part or held by a key backend
+func privateKeys(keys []data.RepoKey) []data.RepoKey {
+	var res []data.RepoKey
+	for _, key := range keys {
+		if encryption.CanSign(&key) {
+			res = append(res, key)
+		}
+	}
+	return res
+}
+
+// filterKeysByRole returns keys belonging to the role
+func filterKeysByRole(keys []data.RepoKey, role data.RoleType) []data.RepoKey {
+	var res []data.RepoKey
+	for _, key := range keys {
+		if key.Role == role {
+			res = append(res, key)
+		}
+	}
+	return res
+}
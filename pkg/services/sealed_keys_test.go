@@ -0,0 +1,103 @@
+package services_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"testing"
+
+	"github.com/shuvava/go-logging/logger"
+
+	"github.com/shuvava/ota-tuf-server/internal/db"
+	"github.com/shuvava/ota-tuf-server/internal/keyring"
+	"github.com/shuvava/ota-tuf-server/pkg/data"
+	"github.com/shuvava/ota-tuf-server/pkg/encryption"
+	"github.com/shuvava/ota-tuf-server/pkg/services"
+)
+
+func TestSealedKeyRepository(t *testing.T) {
+	ctx := context.Background()
+	log := logger.NewNopLogger()
+	newMasterKey := func() []byte {
+		key := make([]byte, keyring.MasterKeySize)
+		_, _ = rand.Read(key)
+		return key
+	}
+	oldKey, newKey := newMasterKey(), newMasterKey()
+	oldRing, _ := keyring.New("old", map[string][]byte{"old": oldKey})
+
+	inner := &memKeyRepo{}
+	sealed := db.NewSealedKeyRepository(log, inner, oldRing)
+	repoRepo := &memRepoRepo{}
+	rootSvc := services.NewRootRoleService(log, sealed, repoRepo, &memSignedRoleRepo{})
+	keySvc := services.NewRepositoryService(log, sealed, repoRepo, rootSvc)
+	repo := data.NewRepo(data.NewRepoID(), data.KeyTypeEd25519)
+	if err := keySvc.CreateNewRepository(ctx, repo); err != nil {
+		t.Fatalf("unable to create repository: %v", err)
+	}
+
+	t.Run("should store private keys sealed", func(t *testing.T) {
+		stored, _ := inner.FindByRepoId(ctx, repo.RepoID)
+		for _, key := range stored {
+			if encryption.HasPrivateKey(&key.Key) {
+				t.Errorf("key %s is stored in clear", key.KeyID)
+			}
+			if _, err := encryption.UnmarshalKey(&key.Key); err != nil {
+				t.Errorf("public part of key %s is not usable: %v", key.KeyID, err)
+			}
+		}
+		keys, _ := sealed.FindByRepoId(ctx, repo.RepoID)
+		for _, key := range keys {
+			if !encryption.HasPrivateKey(&key.Key) {
+				t.Errorf("key %s is not opened", key.KeyID)
+			}
+		}
+		if _, err := keySvc.SignRolePayload(ctx, repo.RepoID, data.RoleTypeTargets, []byte(`{}`)); err != nil {
+			t.Errorf("unable to sign with sealed key: %v", err)
+		}
+	})
+	t.Run("should rewrap data keys on master key rotation", func(t *testing.T) {
+		before, _ := sealed.FindByRepoId(ctx, repo.RepoID)
+		newRing, _ := keyring.New("new", map[string][]byte{"old": oldKey, "new": newKey})
+		rotated := db.NewSealedKeyRepository(log, inner, newRing)
+		cnt, err := rotated.RotateMasterKey(ctx)
+		if err != nil {
+			t.Fatalf("unable to rotate master key: %v", err)
+		}
+		if cnt != len(before) {
+			t.Errorf("expected %d rewrapped keys, got %d", len(before), cnt)
+		}
+		onlyNew, _ := keyring.New("new", map[string][]byte{"new": newKey})
+		after, err := db.NewSealedKeyRepository(log, inner, onlyNew).FindByRepoId(ctx, repo.RepoID)
+		if err != nil {
+			t.Fatalf("unable to open keys by new master key: %v", err)
+		}
+		for i := range after {
+			if !bytes.Equal(after[i].Key.Value, before[i].Key.Value) {
+				t.Errorf("key %s was changed", after[i].KeyID)
+			}
+		}
+		if cnt, _ = rotated.RotateMasterKey(ctx); cnt != 0 {
+			t.Errorf("repeated rotation should not update keys, got %d", cnt)
+		}
+	})
+	t.Run("should seal keys stored in clear", func(t *testing.T) {
+		plainRepo := data.NewRepo(data.NewRepoID(), data.KeyTypeEd25519)
+		plainSvc := services.NewRepositoryService(log, inner, repoRepo,
+			services.NewRootRoleService(log, inner, repoRepo, &memSignedRoleRepo{}))
+		if err := plainSvc.CreateNewRepository(ctx, plainRepo); err != nil {
+			t.Fatalf("unable to create repository: %v", err)
+		}
+		newRing, _ := keyring.New("new", map[string][]byte{"new": newKey})
+		rotated := db.NewSealedKeyRepository(log, inner, newRing)
+		if cnt, err := rotated.RotateMasterKey(ctx); err != nil || cnt != len(data.TopLevelRoles) {
+			t.Errorf("expected %d sealed keys, got %d, %v", len(data.TopLevelRoles), cnt, err)
+		}
+		stored, _ := inner.FindByRepoId(ctx, plainRepo.RepoID)
+		for _, key := range stored {
+			if encryption.HasPrivateKey(&key.Key) {
+				t.Errorf("key %s is stored in clear", key.KeyID)
+			}
+		}
+	})
+}
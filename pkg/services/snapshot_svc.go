@@ -0,0 +1,173 @@
+package services
+
+import (
+	"context"
+
+	"github.com/shuvava/go-ota-svc-common/apperrors"
+
+	"github.com/shuvava/ota-tuf-server/pkg/data"
+)
+
+// GetSignedSnapshot returns the latest published version of snapshot role metadata of the repository.
+// The next version is published if the latest one does not list the latest version of targets role metadata.
+func (svc *TargetsService) GetSignedSnapshot(ctx context.Context, repoID data.RepoID) (*data.SignedRole, error) {
+	targets, err := svc.GetSignedTargets(ctx, repoID)
+	if err != nil {
+		return nil, err
+	}
+	snapshot, err := svc.latestListing(ctx, repoID, data.RoleTypeSnapshot, targets)
+	if snapshot != nil || err != nil {
+		return snapshot, err
+	}
+	return svc.publishSnapshot(ctx, repoID, targets)
+}
+
+// GetSignedTimestamp returns the latest published version of timestamp role metadata of the repository.
+// The next version is published if the latest one does not point to the latest version of snapshot role metadata.
+func (svc *TargetsService) GetSignedTimestamp(ctx context.Context, repoID data.RepoID) (*data.SignedRole, error) {
+	snapshot, err := svc.GetSignedSnapshot(ctx, repoID)
+	if err != nil {
+		return nil, err
+	}
+	timestamp, err := svc.latestListing(ctx, repoID, data.RoleTypeTimestamp, snapshot)
+	if timestamp != nil || err != nil {
+		return timestamp, err
+	}
+	return svc.publishTimestamp(ctx, repoID, snapshot)
+}
+
+// ResignRole publishes the next version of snapshot or timestamp role metadata of the repository
+// with the same content and renewed expiration, the next version of snapshot is followed by the next version of timestamp
+func (svc *TargetsService) ResignRole(ctx context.Context, repoID data.RepoID, role data.RoleType) (*data.SignedRole, error) {
+	var listed data.RoleType
+	switch role {
+	case data.RoleTypeSnapshot:
+		listed = data.RoleTypeTargets
+	case data.RoleTypeTimestamp:
+		listed = data.RoleTypeSnapshot
+	default:
+		return nil, apperrors.NewAppError(apperrors.ErrorDataValidation,
+			"role '"+string(role)+"' metadata can not be re-signed online")
+	}
+	latest, err := svc.roleRepo.FindLatest(ctx, repoID, listed)
+	if err != nil {
+		return nil, err
+	}
+	if role == data.RoleTypeSnapshot {
+		return svc.publishSnapshot(ctx, repoID, latest)
+	}
+	return svc.publishTimestamp(ctx, repoID, latest)
+}
+
+// RefreshRotatedRole publishes the next version of role metadata signed by keys of the role after their rotation,
+// metadata of roles describing it is published as well: targets are listed by snapshot, snapshot by timestamp.
+// Roles without private keys are skipped, they are signed offline by the new keys.
+func (svc *TargetsService) RefreshRotatedRole(ctx context.Context, repoID data.RepoID, role data.RoleType) error {
+	var err error
+	switch role {
+	case data.RoleTypeTargets:
+		_, err = svc.update(ctx, repoID, func(*data.TargetsRole) error { return nil })
+	case data.RoleTypeSnapshot, data.RoleTypeTimestamp:
+		_, err = svc.ResignRole(ctx, repoID, role)
+		if isNotFound(err) {
+			// metadata is published signed by the new keys on the first request of it
+			err = nil
+		}
+	}
+	if isNoPrivateKey(err) {
+		svc.log.WithContext(ctx).
+			WithField("RepoID", repoID).
+			WithField("Role", role).
+			Info("Rotated role metadata has to be signed offline")
+		return nil
+	}
+	return err
+}
+
+// latestListing returns the latest version of snapshot or timestamp role metadata
+// if it lists the version of provided metadata, nil is returned otherwise
+func (svc *TargetsService) latestListing(ctx context.Context, repoID data.RepoID, role data.RoleType, listed *data.SignedRole) (*data.SignedRole, error) {
+	latest, err := svc.roleRepo.FindLatest(ctx, repoID, role)
+	if isNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var meta struct {
+		Meta map[string]data.MetaFileMeta `json:"meta"`
+	}
+	if err = latest.Content.DecodeSigned(&meta); err != nil {
+		return nil, err
+	}
+	if meta.Meta[listed.Role.MetaFileName()].Version != listed.Version {
+		return nil, nil
+	}
+	return latest, nil
+}
+
+// publishSnapshot publishes the next version of snapshot role metadata listing targets role metadata
+// and timestamp role metadata pointing to it
+func (svc *TargetsService) publishSnapshot(ctx context.Context, repoID data.RepoID, targets *data.SignedRole) (*data.SignedRole, error) {
+	meta, err := metaFileMeta(targets)
+	if err != nil {
+		return nil, err
+	}
+	version, err := svc.nextRoleVersion(ctx, repoID, data.RoleTypeSnapshot)
+	if err != nil {
+		return nil, err
+	}
+	snapshot := data.NewSnapshotRole(version, meta)
+	obj, err := svc.publishRole(ctx, repoID, snapshot.RoleHeader, snapshot)
+	if isAlreadyExist(err) {
+		// version was published by concurrent request
+		return svc.roleRepo.FindLatest(ctx, repoID, data.RoleTypeSnapshot)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if _, err = svc.publishTimestamp(ctx, repoID, obj); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// publishTimestamp publishes the next version of timestamp role metadata pointing to snapshot role metadata
+func (svc *TargetsService) publishTimestamp(ctx context.Context, repoID data.RepoID, snapshot *data.SignedRole) (*data.SignedRole, error) {
+	meta, err := metaFileMeta(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	version, err := svc.nextRoleVersion(ctx, repoID, data.RoleTypeTimestamp)
+	if err != nil {
+		return nil, err
+	}
+	timestamp := data.NewTimestampRole(version, meta)
+	obj, err := svc.publishRole(ctx, repoID, timestamp.RoleHeader, timestamp)
+	if isAlreadyExist(err) {
+		// version was published by concurrent request
+		return svc.roleRepo.FindLatest(ctx, repoID, data.RoleTypeTimestamp)
+	}
+	return obj, err
+}
+
+// nextRoleVersion returns the version following the latest published version of the role metadata
+func (svc *TargetsService) nextRoleVersion(ctx context.Context, repoID data.RepoID, role data.RoleType) (int, error) {
+	latest, err := svc.roleRepo.FindLatest(ctx, repoID, role)
+	if isNotFound(err) {
+		return 1, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return latest.Version + 1, nil
+}
+
+// metaFileMeta returns description of the metadata file as it is served to clients
+func metaFileMeta(signed *data.SignedRole) (data.MetaFileMeta, error) {
+	content, err := signed.Content.FileContent()
+	if err != nil {
+		return data.MetaFileMeta{}, err
+	}
+	return data.NewMetaFileMeta(signed.Version, content), nil
+}
@@ -0,0 +1,140 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/shuvava/ota-tuf-server/pkg/data"
+	"github.com/shuvava/ota-tuf-server/pkg/encryption"
+)
+
+// verifyRole checks that metadata is signed by keys of the role of the latest root
+func (s *testServices) verifyRole(t *testing.T, signed *data.SignedRole) {
+	t.Helper()
+	rootRole, err := s.rootSvc.GetSignedRoot(context.Background(), signed.RepoID)
+	if err != nil {
+		t.Fatalf("unable to get root: %v", err)
+	}
+	var root data.RootRole
+	if err = rootRole.Content.DecodeSigned(&root); err != nil {
+		t.Fatalf("unable to decode root: %v", err)
+	}
+	if err = encryption.VerifyPayload(&signed.Content, root.Keys, root.Roles[signed.Role]); err != nil {
+		t.Errorf("expected %s signed by %s keys, got %v", signed.Role, signed.Role, err)
+	}
+}
+
+// assertListed checks that meta of snapshot or timestamp metadata describes the file of listed metadata
+func assertListed(t *testing.T, meta map[string]data.MetaFileMeta, listed *data.SignedRole) {
+	t.Helper()
+	content, err := listed.Content.FileContent()
+	if err != nil {
+		t.Fatalf("unable to marshal metadata: %v", err)
+	}
+	want := data.NewMetaFileMeta(listed.Version, content)
+	got, ok := meta[listed.Role.MetaFileName()]
+	if !ok {
+		t.Fatalf("expected %s listed", listed.Role.MetaFileName())
+	}
+	if got.Version != want.Version || got.Length != want.Length ||
+		got.Hashes[data.HashAlgorithmSHA256].String() != want.Hashes[data.HashAlgorithmSHA256].String() ||
+		got.Hashes[data.HashAlgorithmSHA512].String() != want.Hashes[data.HashAlgorithmSHA512].String() {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestSnapshotAndTimestamp(t *testing.T) {
+	ctx := context.Background()
+	meta := data.TargetFileMeta{Length: 5, Hashes: data.NewHashes([]byte("hello"))}
+
+	t.Run("should regenerate snapshot and timestamp on targets change", func(t *testing.T) {
+		s := newTestServices()
+		repoID := s.createTestRepo(t)
+		for _, path := range []string{"a.bin", "b.bin"} {
+			if _, err := s.targetsSvc.PutTarget(ctx, repoID, path, meta); err != nil {
+				t.Fatalf("unable to add target: %v", err)
+			}
+		}
+		targets, _ := s.targetsSvc.GetSignedTargets(ctx, repoID)
+		snapshot, err := s.targetsSvc.GetSignedSnapshot(ctx, repoID)
+		if err != nil {
+			t.Fatalf("unable to get snapshot: %v", err)
+		}
+		timestamp, err := s.targetsSvc.GetSignedTimestamp(ctx, repoID)
+		if err != nil {
+			t.Fatalf("unable to get timestamp: %v", err)
+		}
+		if snapshot.Version != 2 || timestamp.Version != 2 {
+			t.Errorf("expected snapshot and timestamp version 2, got %d and %d", snapshot.Version, timestamp.Version)
+		}
+		s.verifyRole(t, snapshot)
+		s.verifyRole(t, timestamp)
+
+		var snapshotRole data.SnapshotRole
+		if err = snapshot.Content.DecodeSigned(&snapshotRole); err != nil {
+			t.Fatalf("unable to decode snapshot: %v", err)
+		}
+		if err = snapshotRole.Validate(); err != nil {
+			t.Errorf("expected valid snapshot, got %v", err)
+		}
+		assertListed(t, snapshotRole.Meta, targets)
+
+		var timestampRole data.TimestampRole
+		if err = timestamp.Content.DecodeSigned(&timestampRole); err != nil {
+			t.Fatalf("unable to decode timestamp: %v", err)
+		}
+		if err = timestampRole.Validate(); err != nil {
+			t.Errorf("expected valid timestamp, got %v", err)
+		}
+		assertListed(t, timestampRole.Meta, snapshot)
+	})
+	t.Run("should publish snapshot and timestamp of a new repository", func(t *testing.T) {
+		s := newTestServices()
+		repoID := s.createTestRepo(t)
+		timestamp, err := s.targetsSvc.GetSignedTimestamp(ctx, repoID)
+		if err != nil {
+			t.Fatalf("unable to get timestamp: %v", err)
+		}
+		if timestamp.Version != 1 {
+			t.Errorf("expected version 1, got %d", timestamp.Version)
+		}
+		s.verifyRole(t, timestamp)
+	})
+	t.Run("should republish stale snapshot", func(t *testing.T) {
+		s := newTestServices()
+		repoID := s.createTestRepo(t)
+		if _, err := s.targetsSvc.GetSignedTimestamp(ctx, repoID); err != nil {
+			t.Fatalf("unable to get timestamp: %v", err)
+		}
+		// targets published without snapshot, e.g. snapshot signing failed
+		targets := data.NewTargetsRole(2)
+		targets.Targets["a.bin"] = meta
+		payload, _ := json.Marshal(targets)
+		sigs, err := s.keySvc.SignRolePayload(ctx, repoID, data.RoleTypeTargets, payload)
+		if err != nil {
+			t.Fatalf("unable to sign targets: %v", err)
+		}
+		stale := data.SignedRole{
+			RepoID:    repoID,
+			Role:      data.RoleTypeTargets,
+			Version:   2,
+			ExpiresAt: targets.Expires,
+			Content:   data.SignedPayload{Signatures: sigs, Signed: payload},
+		}
+		if err = s.roleRepo.Create(ctx, stale); err != nil {
+			t.Fatalf("unable to create targets: %v", err)
+		}
+		timestamp, err := s.targetsSvc.GetSignedTimestamp(ctx, repoID)
+		if err != nil {
+			t.Fatalf("unable to get timestamp: %v", err)
+		}
+		snapshot, _ := s.targetsSvc.GetSignedSnapshot(ctx, repoID)
+		if snapshot.Version != 2 || timestamp.Version != 2 {
+			t.Errorf("expected snapshot and timestamp version 2, got %d and %d", snapshot.Version, timestamp.Version)
+		}
+		var snapshotRole data.SnapshotRole
+		_ = snapshot.Content.DecodeSigned(&snapshotRole)
+		assertListed(t, snapshotRole.Meta, &stale)
+	})
+}
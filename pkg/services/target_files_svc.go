@@ -0,0 +1,261 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/sha512"
+	"hash"
+	"io"
+	"os"
+
+	"github.com/shuvava/go-logging/logger"
+	"github.com/shuvava/go-ota-svc-common/apperrors"
+
+	"github.com/shuvava/ota-tuf-server/pkg/data"
+	"github.com/shuvava/ota-tuf-server/pkg/errcodes"
+	"github.com/shuvava/ota-tuf-server/pkg/storage"
+)
+
+// spoolPattern is the pattern of temporary files keeping uploaded content until its digests are known
+const spoolPattern = "tuf-target-*"
+
+// TargetFileService keeps content of target files in blob storage,
+// descriptions of stored files are published in targets role metadata
+type TargetFileService struct {
+	log        logger.Logger
+	targetsSvc *TargetsService
+	storage    storage.Storage
+}
+
+// NewTargetFileService creates new instance of services.TargetFileService
+func NewTargetFileService(l logger.Logger, targetsSvc *TargetsService, store storage.Storage) *TargetFileService {
+	log := l.SetOperation("target-file-service")
+	return &TargetFileService{
+		log:        log,
+		targetsSvc: targetsSvc,
+		storage:    store,
+	}
+}
+
+// UploadTarget stores content of the target file and publishes the next version of targets role metadata
+// describing it by length and digests computed while content is stored, size is the content length or -1 if it is unknown.
+// Content is spooled to a temporary file until its digests are known and stored under key of its digest,
+// so content described by the previous version of metadata is served until the next version is published.
+func (svc *TargetFileService) UploadTarget(ctx context.Context, repoID data.RepoID, path string, r io.Reader, size int64) (*data.SignedRole, error) {
+	if _, err := targetBlobKey(repoID, path, nil); err != nil {
+		return nil, err
+	}
+	// fails if the repository does not exist, so content of unknown repository is not stored
+	prev, err := svc.getTarget(ctx, repoID, path)
+	if err != nil && !isTargetNotFound(err) {
+		return nil, err
+	}
+	spool, meta, err := spoolTarget(r)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = spool.Close()
+		_ = os.Remove(spool.Name())
+	}()
+	if size >= 0 && meta.Length != size {
+		return nil, apperrors.NewAppError(apperrors.ErrorDataValidation, "target file length does not match content length")
+	}
+	key, err := targetBlobKey(repoID, path, meta.Hashes)
+	if err != nil {
+		return nil, err
+	}
+	created, err := svc.putBlob(ctx, key, spool, meta.Length)
+	if err != nil {
+		return nil, err
+	}
+	signed, err := svc.targetsSvc.PutTarget(ctx, repoID, path, meta)
+	if err != nil {
+		// content stored before by another upload of the same content is kept
+		if created {
+			svc.deleteUnservedBlob(ctx, repoID, path, key)
+		}
+		return nil, err
+	}
+	svc.log.WithContext(ctx).
+		WithField("RepoID", repoID).
+		WithField("Path", path).
+		WithField("Length", meta.Length).
+		Info("Target file stored")
+	if prev != nil {
+		if prevKey, err := targetBlobKey(repoID, path, prev.Hashes); err == nil && prevKey != key {
+			svc.deleteUnservedBlob(ctx, repoID, path, prevKey)
+		}
+	}
+	return signed, nil
+}
+
+// OpenTarget returns content of the target file described by the latest version of targets role metadata
+func (svc *TargetFileService) OpenTarget(ctx context.Context, repoID data.RepoID, path string) (storage.Blob, error) {
+	meta, err := svc.getTarget(ctx, repoID, path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := targetBlobKey(repoID, path, meta.Hashes)
+	if err != nil {
+		return nil, err
+	}
+	return svc.storage.Open(ctx, key)
+}
+
+// DeleteTarget removes the target file from targets role metadata of the repository and its content from storage
+func (svc *TargetFileService) DeleteTarget(ctx context.Context, repoID data.RepoID, path string) (*data.SignedRole, error) {
+	meta, err := svc.getTarget(ctx, repoID, path)
+	if err != nil && !isTargetNotFound(err) {
+		return nil, err
+	}
+	signed, err := svc.targetsSvc.DeleteTarget(ctx, repoID, path)
+	if err != nil {
+		return nil, err
+	}
+	if meta == nil {
+		return signed, nil
+	}
+	key, err := targetBlobKey(repoID, path, meta.Hashes)
+	if err != nil {
+		// target paths not valid as blob keys and targets added without content are never stored
+		return signed, nil
+	}
+	// target is already removed from metadata, the content is never served again
+	svc.deleteBlob(ctx, repoID, path, key)
+	return signed, nil
+}
+
+// getTarget returns description of the target file in the latest version of targets role metadata
+func (svc *TargetFileService) getTarget(ctx context.Context, repoID data.RepoID, path string) (*data.TargetFileMeta, error) {
+	signed, err := svc.targetsSvc.GetSignedTargets(ctx, repoID)
+	if err != nil {
+		return nil, err
+	}
+	var targets data.TargetsRole
+	if err = signed.Content.DecodeSigned(&targets); err != nil {
+		return nil, err
+	}
+	meta, ok := targets.Targets[path]
+	if !ok {
+		return nil, apperrors.NewAppError(errcodes.ErrorSvcTargetNotFound, "target '"+path+"' does not exist")
+	}
+	return &meta, nil
+}
+
+// putBlob stores content under the key if it is not stored yet, content of the key is the content of its digest,
+// returns true if the content is stored by this call
+func (svc *TargetFileService) putBlob(ctx context.Context, key string, r io.Reader, size int64) (bool, error) {
+	blob, err := svc.storage.Open(ctx, key)
+	if err == nil {
+		_ = blob.Close()
+		return false, nil
+	}
+	if !isBlobNotFound(err) {
+		return false, err
+	}
+	if err = svc.storage.Put(ctx, key, r, size); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// deleteUnservedBlob removes content unless it is described by the latest version of targets role metadata,
+// the same content may be published by concurrent upload of the target file
+func (svc *TargetFileService) deleteUnservedBlob(ctx context.Context, repoID data.RepoID, path, key string) {
+	meta, err := svc.getTarget(ctx, repoID, path)
+	if err != nil && !isTargetNotFound(err) {
+		// failure leaves unreferenced content in storage only
+		return
+	}
+	if meta != nil {
+		if servedKey, err := targetBlobKey(repoID, path, meta.Hashes); err == nil && servedKey == key {
+			return
+		}
+	}
+	svc.deleteBlob(ctx, repoID, path, key)
+}
+
+// deleteBlob removes content not served anymore, failure leaves unreferenced content in storage only
+func (svc *TargetFileService) deleteBlob(ctx context.Context, repoID data.RepoID, path, key string) {
+	if err := svc.storage.Delete(ctx, key); err != nil {
+		svc.log.WithContext(ctx).
+			WithField("RepoID", repoID).
+			WithField("Path", path).
+			WithField("Key", key).
+			WithError(err).
+			Warn("Failed to delete target file content")
+	}
+}
+
+// targetBlobKey returns key of the target file content in storage, content is kept under key of its SHA-256 digest
+// in the directory of the target file, the key is validated without digest if hashes are nil
+func targetBlobKey(repoID data.RepoID, path string, hashes data.Hashes) (string, error) {
+	if err := data.ValidateTargetPath(path); err != nil {
+		return "", err
+	}
+	key := repoID.String() + "/" + path
+	if hashes != nil {
+		digest, ok := hashes[data.HashAlgorithmSHA256]
+		if !ok {
+			return "", apperrors.NewAppError(errcodes.ErrorFsBlobNotFound, "content of target '"+path+"' is not stored")
+		}
+		key += "/" + digest.String()
+	}
+	return key, storage.ValidateKey(key)
+}
+
+// hashingReader computes length and digests of content read through it
+type hashingReader struct {
+	r      io.Reader
+	length int64
+	sha256 hash.Hash
+	sha512 hash.Hash
+}
+
+func newHashingReader(r io.Reader) *hashingReader {
+	return &hashingReader{
+		r:      r,
+		sha256: sha256.New(),
+		sha512: sha512.New(),
+	}
+}
+
+func (h *hashingReader) Read(p []byte) (int, error) {
+	n, err := h.r.Read(p)
+	h.length += int64(n)
+	_, _ = h.sha256.Write(p[:n])
+	_, _ = h.sha512.Write(p[:n])
+	return n, err
+}
+
+// meta returns description of the content read so far
+func (h *hashingReader) meta() data.TargetFileMeta {
+	return data.TargetFileMeta{
+		Length: h.length,
+		Hashes: data.Hashes{
+			data.HashAlgorithmSHA256: h.sha256.Sum(nil),
+			data.HashAlgorithmSHA512: h.sha512.Sum(nil),
+		},
+	}
+}
+
+// spoolTarget copies content to a temporary file computing its length and digests,
+// the file is returned positioned at the beginning
+func spoolTarget(r io.Reader) (*os.File, data.TargetFileMeta, error) {
+	f, err := os.CreateTemp("", spoolPattern)
+	if err != nil {
+		return nil, data.TargetFileMeta{}, apperrors.CreateError(apperrors.ErrorFsIOCreate, "failed to create temporary file", err)
+	}
+	hr := newHashingReader(r)
+	_, err = io.Copy(f, hr)
+	if err == nil {
+		_, err = f.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+		return nil, data.TargetFileMeta{}, apperrors.CreateError(errcodes.ErrorFsBlobStorage, "failed to write temporary file", err)
+	}
+	return f, hr.meta(), nil
+}
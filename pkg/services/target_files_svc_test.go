@@ -0,0 +1,228 @@
+package services_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"testing"
+
+	"github.com/shuvava/go-logging/logger"
+
+	"github.com/shuvava/ota-tuf-server/pkg/data"
+	"github.com/shuvava/ota-tuf-server/pkg/services"
+	"github.com/shuvava/ota-tuf-server/pkg/storage"
+	"github.com/shuvava/ota-tuf-server/pkg/storage/local"
+)
+
+func TestTargetFileService(t *testing.T) {
+	ctx := context.Background()
+	content := []byte("firmware image")
+	newTargetFileService := func(t *testing.T, s *testServices) (*services.TargetFileService, *countingStorage) {
+		root := t.TempDir()
+		store, err := local.New(root)
+		if err != nil {
+			t.Fatalf("unable to create storage: %v", err)
+		}
+		counting := &countingStorage{Storage: store, root: root}
+		return services.NewTargetFileService(logger.NewNopLogger(), s.targetsSvc, counting), counting
+	}
+
+	t.Run("should describe uploaded target by computed length and digests", func(t *testing.T) {
+		s := newTestServices()
+		svc, _ := newTargetFileService(t, s)
+		repoID := s.createTestRepo(t)
+		for _, size := range []int64{int64(len(content)), -1} {
+			signed, err := svc.UploadTarget(ctx, repoID, "images/fw.bin", bytes.NewReader(content), size)
+			if err != nil {
+				t.Fatalf("unable to upload target: %v", err)
+			}
+			targets := s.verifyTargets(t, signed)
+			got := targets.Targets["images/fw.bin"]
+			want := data.NewHashes(content)
+			if got.Length != int64(len(content)) ||
+				got.Hashes[data.HashAlgorithmSHA256].String() != want[data.HashAlgorithmSHA256].String() ||
+				got.Hashes[data.HashAlgorithmSHA512].String() != want[data.HashAlgorithmSHA512].String() {
+				t.Errorf("expected length %d and hashes %v, got %v", len(content), want, got)
+			}
+		}
+		snapshot, _ := s.targetsSvc.GetSignedSnapshot(ctx, repoID)
+		targets, _ := s.targetsSvc.GetSignedTargets(ctx, repoID)
+		var snapshotRole data.SnapshotRole
+		if err := snapshot.Content.DecodeSigned(&snapshotRole); err != nil {
+			t.Fatalf("unable to decode snapshot: %v", err)
+		}
+		assertListed(t, snapshotRole.Meta, targets)
+	})
+	t.Run("should return content of uploaded target", func(t *testing.T) {
+		s := newTestServices()
+		svc, _ := newTargetFileService(t, s)
+		repoID := s.createTestRepo(t)
+		if _, err := svc.UploadTarget(ctx, repoID, "fw.bin", bytes.NewReader(content), -1); err != nil {
+			t.Fatalf("unable to upload target: %v", err)
+		}
+		blob, err := svc.OpenTarget(ctx, repoID, "fw.bin")
+		if err != nil {
+			t.Fatalf("unable to open target: %v", err)
+		}
+		defer blob.Close()
+		if got, _ := io.ReadAll(blob); !bytes.Equal(got, content) {
+			t.Errorf("expected %q, got %q", content, got)
+		}
+		if _, err = svc.OpenTarget(ctx, repoID, "missing.bin"); err == nil {
+			t.Error("expected error on missing target, got nil")
+		}
+	})
+	t.Run("should not publish target of incomplete upload", func(t *testing.T) {
+		s := newTestServices()
+		svc, _ := newTargetFileService(t, s)
+		repoID := s.createTestRepo(t)
+		if _, err := svc.UploadTarget(ctx, repoID, "fw.bin", bytes.NewReader(content), int64(len(content))+1); err == nil {
+			t.Error("expected error, got nil")
+		}
+		if _, err := svc.OpenTarget(ctx, repoID, "fw.bin"); err == nil {
+			t.Error("expected target not published, got nil")
+		}
+	})
+	t.Run("should serve previous content until replacing upload is published", func(t *testing.T) {
+		s := newTestServices()
+		svc, store := newTargetFileService(t, s)
+		repoID := s.createTestRepo(t)
+		if _, err := svc.UploadTarget(ctx, repoID, "fw.bin", bytes.NewReader([]byte("old image")), -1); err != nil {
+			t.Fatalf("unable to upload target: %v", err)
+		}
+		if _, err := svc.UploadTarget(ctx, repoID, "fw.bin", bytes.NewReader(content), -1); err != nil {
+			t.Fatalf("unable to upload target: %v", err)
+		}
+		s.deleteTargetsPrivateKey(t, repoID)
+		// targets metadata can't be signed, so the upload is not published
+		if _, err := svc.UploadTarget(ctx, repoID, "fw.bin", bytes.NewReader([]byte("new image")), -1); err == nil {
+			t.Fatal("expected error, got nil")
+		}
+		assertTargetContent(t, svc, repoID, "fw.bin", content)
+		// only content of published target is kept, replaced and not published content is deleted
+		if files := store.files(t); len(files) != 1 {
+			t.Errorf("expected content of one target stored, got %v", files)
+		}
+	})
+	t.Run("should store content once", func(t *testing.T) {
+		s := newTestServices()
+		svc, store := newTargetFileService(t, s)
+		repoID := s.createTestRepo(t)
+		for i := 0; i < 2; i++ {
+			if _, err := svc.UploadTarget(ctx, repoID, "fw.bin", bytes.NewReader(content), int64(len(content))); err != nil {
+				t.Fatalf("unable to upload target: %v", err)
+			}
+		}
+		if store.puts != 1 {
+			t.Errorf("expected content stored once, got %d", store.puts)
+		}
+		assertTargetContent(t, svc, repoID, "fw.bin", content)
+	})
+	t.Run("should keep content published by concurrent upload of the same content", func(t *testing.T) {
+		s := newTestServices()
+		svc, _ := newTargetFileService(t, s)
+		repoID := s.createTestRepo(t)
+		if _, err := svc.UploadTarget(ctx, repoID, "fw.bin", bytes.NewReader([]byte("old image")), -1); err != nil {
+			t.Fatalf("unable to upload target: %v", err)
+		}
+		// the concurrent upload publishes description of the content stored by both uploads
+		meta := data.TargetFileMeta{Length: int64(len(content)), Hashes: data.NewHashes(content)}
+		if _, err := s.targetsSvc.PutTarget(ctx, repoID, "fw.bin", meta); err != nil {
+			t.Fatalf("unable to add target: %v", err)
+		}
+		s.deleteTargetsPrivateKey(t, repoID)
+		if _, err := svc.UploadTarget(ctx, repoID, "fw.bin", bytes.NewReader(content), -1); err == nil {
+			t.Fatal("expected error, got nil")
+		}
+		assertTargetContent(t, svc, repoID, "fw.bin", content)
+	})
+	t.Run("should reject upload to unknown repository", func(t *testing.T) {
+		s := newTestServices()
+		svc, _ := newTargetFileService(t, s)
+		if _, err := svc.UploadTarget(ctx, data.NewRepoID(), "fw.bin", bytes.NewReader(content), -1); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+	t.Run("should reject target path not valid as storage key", func(t *testing.T) {
+		s := newTestServices()
+		svc, _ := newTargetFileService(t, s)
+		repoID := s.createTestRepo(t)
+		if _, err := svc.UploadTarget(ctx, repoID, "a/../../fw.bin", bytes.NewReader(content), -1); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+	t.Run("should delete target content with its description", func(t *testing.T) {
+		s := newTestServices()
+		svc, _ := newTargetFileService(t, s)
+		repoID := s.createTestRepo(t)
+		_, _ = svc.UploadTarget(ctx, repoID, "fw.bin", bytes.NewReader(content), -1)
+		signed, err := svc.DeleteTarget(ctx, repoID, "fw.bin")
+		if err != nil {
+			t.Fatalf("unable to delete target: %v", err)
+		}
+		if targets := s.verifyTargets(t, signed); len(targets.Targets) != 0 {
+			t.Errorf("expected no targets, got %v", targets.Targets)
+		}
+		// description without content is published again
+		meta := data.TargetFileMeta{Length: int64(len(content)), Hashes: data.NewHashes(content)}
+		if _, err = s.targetsSvc.PutTarget(ctx, repoID, "fw.bin", meta); err != nil {
+			t.Fatalf("unable to add target: %v", err)
+		}
+		if _, err = svc.OpenTarget(ctx, repoID, "fw.bin"); err == nil {
+			t.Error("expected content deleted, got nil")
+		}
+	})
+}
+
+// countingStorage is storage.Storage counting stored blobs
+type countingStorage struct {
+	storage.Storage
+	root string
+	puts int
+}
+
+func (s *countingStorage) Put(ctx context.Context, key string, r io.Reader, size int64) error {
+	s.puts++
+	return s.Storage.Put(ctx, key, r, size)
+}
+
+// files returns stored files
+func (s *countingStorage) files(t *testing.T) []string {
+	t.Helper()
+	var files []string
+	err := filepath.WalkDir(s.root, func(path string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			files = append(files, path)
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatalf("unable to list stored files: %v", err)
+	}
+	return files
+}
+
+// deleteTargetsPrivateKey makes targets role metadata of the repository not signable
+func (s *testServices) deleteTargetsPrivateKey(t *testing.T, repoID data.RepoID) {
+	t.Helper()
+	ctx := context.Background()
+	keys, _ := s.keyRepo.FindByRole(ctx, repoID, data.RoleTypeTargets)
+	if err := s.keySvc.DeletePrivateKey(ctx, repoID, keys[0].KeyID); err != nil {
+		t.Fatalf("unable to delete private key: %v", err)
+	}
+}
+
+// assertTargetContent checks served content of the target file
+func assertTargetContent(t *testing.T, svc *services.TargetFileService, repoID data.RepoID, path string, want []byte) {
+	t.Helper()
+	blob, err := svc.OpenTarget(context.Background(), repoID, path)
+	if err != nil {
+		t.Fatalf("unable to open target: %v", err)
+	}
+	defer blob.Close()
+	if got, _ := io.ReadAll(blob); !bytes.Equal(got, want) {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,105 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/shuvava/go-ota-svc-common/apperrors"
+
+	"github.com/shuvava/ota-tuf-server/pkg/data"
+	"github.com/shuvava/ota-tuf-server/pkg/encryption"
+	"github.com/shuvava/ota-tuf-server/pkg/errcodes"
+)
+
+// GetUnsignedTargets returns signed portion of the latest version of targets role metadata of the repository
+// with its checksum. The next version is signed offline and uploaded back by PublishSignedTargets.
+func (svc *TargetsService) GetUnsignedTargets(ctx context.Context, repoID data.RepoID) (*data.UnsignedTargets, error) {
+	latest, err := svc.GetSignedTargets(ctx, repoID)
+	if err != nil {
+		return nil, err
+	}
+	res := &data.UnsignedTargets{}
+	if err = latest.Content.DecodeSigned(&res.Signed); err != nil {
+		return nil, err
+	}
+	if res.Checksum, err = targetsChecksum(latest); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// PublishSignedTargets publishes the next version of targets role metadata signed offline,
+// checksum is the checksum of the version the metadata is based on, it has to be the latest version.
+// The metadata must be signed by threshold of targets keys of the latest root,
+// snapshot and timestamp role metadata are regenerated.
+func (svc *TargetsService) PublishSignedTargets(ctx context.Context, repoID data.RepoID, payload data.SignedPayload, checksum string) (*data.SignedRole, error) {
+	var targets data.TargetsRole
+	if err := payload.DecodeSigned(&targets); err != nil {
+		return nil, err
+	}
+	if err := targets.Validate(); err != nil {
+		return nil, err
+	}
+	if targets.IsExpired(time.Now()) {
+		return nil, apperrors.NewAppError(apperrors.ErrorDataValidation, "tuf: targets are expired")
+	}
+	latest, err := svc.GetSignedTargets(ctx, repoID)
+	if err != nil {
+		return nil, err
+	}
+	latestChecksum, err := targetsChecksum(latest)
+	if err != nil {
+		return nil, err
+	}
+	if checksum != latestChecksum {
+		return nil, apperrors.NewAppError(errcodes.ErrorSvcChecksumMismatch,
+			"targets were changed, the latest version has to be signed")
+	}
+	if targets.Version != latest.Version+1 {
+		return nil, apperrors.NewAppError(apperrors.ErrorDataValidation,
+			fmt.Sprintf("tuf: expected targets version %d, got %d", latest.Version+1, targets.Version))
+	}
+	rootRole, err := svc.roleRepo.FindLatest(ctx, repoID, data.RoleTypeRoot)
+	if err != nil {
+		return nil, err
+	}
+	var root data.RootRole
+	if err = rootRole.Content.DecodeSigned(&root); err != nil {
+		return nil, err
+	}
+	if err = encryption.VerifyPayload(&payload, root.Keys, root.Roles[data.RoleTypeTargets]); err != nil {
+		return nil, err
+	}
+
+	obj := data.SignedRole{
+		RepoID:    repoID,
+		Role:      data.RoleTypeTargets,
+		Version:   targets.Version,
+		ExpiresAt: targets.Expires,
+		Content:   payload,
+	}
+	err = svc.roleRepo.Create(ctx, obj)
+	if isAlreadyExist(err) {
+		return nil, apperrors.NewAppError(errcodes.ErrorSvcChecksumMismatch,
+			"targets were changed, the latest version has to be signed")
+	}
+	if err != nil {
+		return nil, err
+	}
+	svc.log.WithContext(ctx).
+		WithField("RepoID", repoID).
+		WithField("Version", targets.Version).
+		Info("Offline signed targets role published")
+	svc.refreshSnapshot(ctx, repoID, &obj)
+	return &obj, nil
+}
+
+// targetsChecksum returns hex encoded SHA-256 digest of targets metadata file as it is served to clients
+func targetsChecksum(targets *data.SignedRole) (string, error) {
+	content, err := targets.Content.FileContent()
+	if err != nil {
+		return "", err
+	}
+	return data.NewHashes(content)[data.HashAlgorithmSHA256].String(), nil
+}
@@ -0,0 +1,119 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shuvava/go-ota-svc-common/apperrors"
+
+	"github.com/shuvava/ota-tuf-server/pkg/data"
+	"github.com/shuvava/ota-tuf-server/pkg/encryption"
+	"github.com/shuvava/ota-tuf-server/pkg/errcodes"
+)
+
+func TestPublishSignedTargets(t *testing.T) {
+	ctx := context.Background()
+	meta := data.TargetFileMeta{Length: 5, Hashes: data.NewHashes([]byte("hello"))}
+	// prepareTargets returns the next version of unsigned targets with a new target and the checksum of the latest version
+	prepareTargets := func(t *testing.T, s *testServices, repoID data.RepoID) (*data.TargetsRole, string) {
+		t.Helper()
+		unsigned, err := s.targetsSvc.GetUnsignedTargets(ctx, repoID)
+		if err != nil {
+			t.Fatalf("unable to get unsigned targets: %v", err)
+		}
+		targets := unsigned.Signed
+		targets.Version++
+		targets.Expires = time.Now().Add(time.Hour).UTC().Round(time.Second)
+		targets.Targets["offline.bin"] = meta
+		return &targets, unsigned.Checksum
+	}
+	// signTargets signs targets by keys of targets role as it is done offline
+	signTargets := func(t *testing.T, s *testServices, repoID data.RepoID, targets *data.TargetsRole) data.SignedPayload {
+		t.Helper()
+		keys, _ := s.keyRepo.FindByRole(ctx, repoID, data.RoleTypeTargets)
+		signed, err := encryption.SignPayload(targets, keys)
+		if err != nil {
+			t.Fatalf("unable to sign targets: %v", err)
+		}
+		return *signed
+	}
+	assertErrorCode := func(t *testing.T, err error, code apperrors.AppErrorCode) {
+		t.Helper()
+		var typedErr apperrors.AppError
+		if !errors.As(err, &typedErr) || typedErr.ErrorCode != code {
+			t.Errorf("expected %s error, got %v", code, err)
+		}
+	}
+
+	t.Run("should publish offline signed targets and regenerate snapshot", func(t *testing.T) {
+		s := newTestServices()
+		repoID := s.createTestRepo(t)
+		targets, checksum := prepareTargets(t, s, repoID)
+		if targets.Version != 2 {
+			t.Fatalf("expected next version 2, got %d", targets.Version)
+		}
+		published, err := s.targetsSvc.PublishSignedTargets(ctx, repoID, signTargets(t, s, repoID, targets), checksum)
+		if err != nil {
+			t.Fatalf("unable to publish targets: %v", err)
+		}
+		if got := s.verifyTargets(t, published); got.Version != 2 || len(got.Targets) != 1 {
+			t.Errorf("expected version 2 with one target, got %v", got)
+		}
+		latest, _ := s.targetsSvc.GetSignedTargets(ctx, repoID)
+		if latest.Version != 2 {
+			t.Errorf("expected latest version 2, got %d", latest.Version)
+		}
+		snapshot, _ := s.roleRepo.FindLatest(ctx, repoID, data.RoleTypeSnapshot)
+		var snapshotRole data.SnapshotRole
+		if err = snapshot.Content.DecodeSigned(&snapshotRole); err != nil {
+			t.Fatalf("unable to decode snapshot: %v", err)
+		}
+		assertListed(t, snapshotRole.Meta, published)
+		timestamp, _ := s.roleRepo.FindLatest(ctx, repoID, data.RoleTypeTimestamp)
+		var timestampRole data.TimestampRole
+		if err = timestamp.Content.DecodeSigned(&timestampRole); err != nil {
+			t.Fatalf("unable to decode timestamp: %v", err)
+		}
+		assertListed(t, timestampRole.Meta, snapshot)
+	})
+	t.Run("should reject targets based on stale version", func(t *testing.T) {
+		s := newTestServices()
+		repoID := s.createTestRepo(t)
+		targets, checksum := prepareTargets(t, s, repoID)
+		if _, err := s.targetsSvc.PutTarget(ctx, repoID, "online.bin", meta); err != nil {
+			t.Fatalf("unable to add target: %v", err)
+		}
+		_, err := s.targetsSvc.PublishSignedTargets(ctx, repoID, signTargets(t, s, repoID, targets), checksum)
+		assertErrorCode(t, err, errcodes.ErrorSvcChecksumMismatch)
+	})
+	t.Run("should reject targets not signed by targets keys", func(t *testing.T) {
+		s := newTestServices()
+		repoID := s.createTestRepo(t)
+		targets, checksum := prepareTargets(t, s, repoID)
+		signed, _ := encryption.SignPayload(targets, []data.RepoKey{newOfflineKey(t)})
+		if _, err := s.targetsSvc.PublishSignedTargets(ctx, repoID, *signed, checksum); err == nil {
+			t.Error("expected error, got nil")
+		}
+		if latest, _ := s.targetsSvc.GetSignedTargets(ctx, repoID); latest.Version != 1 {
+			t.Errorf("expected latest version 1, got %d", latest.Version)
+		}
+	})
+	t.Run("should reject targets of unexpected version", func(t *testing.T) {
+		s := newTestServices()
+		repoID := s.createTestRepo(t)
+		targets, checksum := prepareTargets(t, s, repoID)
+		targets.Version = 1
+		_, err := s.targetsSvc.PublishSignedTargets(ctx, repoID, signTargets(t, s, repoID, targets), checksum)
+		assertErrorCode(t, err, apperrors.ErrorDataValidation)
+	})
+	t.Run("should reject expired targets", func(t *testing.T) {
+		s := newTestServices()
+		repoID := s.createTestRepo(t)
+		targets, checksum := prepareTargets(t, s, repoID)
+		targets.Expires = time.Now().Add(-time.Hour).UTC().Round(time.Second)
+		_, err := s.targetsSvc.PublishSignedTargets(ctx, repoID, signTargets(t, s, repoID, targets), checksum)
+		assertErrorCode(t, err, apperrors.ErrorDataValidation)
+	})
+}
@@ -0,0 +1,173 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/shuvava/go-logging/logger"
+	"github.com/shuvava/go-ota-svc-common/apperrors"
+
+	"github.com/shuvava/ota-tuf-server/internal/db"
+	"github.com/shuvava/ota-tuf-server/pkg/data"
+	"github.com/shuvava/ota-tuf-server/pkg/errcodes"
+)
+
+// maxPublishAttempts is the number of attempts to publish the next version of metadata
+// when the same version is published by concurrent request
+const maxPublishAttempts = 3
+
+// TargetsService maintains targets role metadata of repositories
+// and snapshot and timestamp role metadata describing it
+type TargetsService struct {
+	log      logger.Logger
+	roleRepo db.SignedRoleRepository
+	keySvc   *RepositoryService
+}
+
+// NewTargetsService creates new instance of services.TargetsService
+func NewTargetsService(l logger.Logger, roleRepo db.SignedRoleRepository, keySvc *RepositoryService) *TargetsService {
+	log := l.SetOperation("targets-service")
+	return &TargetsService{
+		log:      log,
+		roleRepo: roleRepo,
+		keySvc:   keySvc,
+	}
+}
+
+// GetSignedTargets returns the latest published version of targets role metadata of the repository.
+// The first version without targets is published if the repository does not have any yet.
+func (svc *TargetsService) GetSignedTargets(ctx context.Context, repoID data.RepoID) (*data.SignedRole, error) {
+	targets, err := svc.roleRepo.FindLatest(ctx, repoID, data.RoleTypeTargets)
+	if err == nil {
+		return targets, nil
+	}
+	if !isNotFound(err) {
+		return nil, err
+	}
+	targets, err = svc.publish(ctx, repoID, data.NewTargetsRole(1))
+	if isAlreadyExist(err) {
+		// version was published by concurrent request
+		return svc.roleRepo.FindLatest(ctx, repoID, data.RoleTypeTargets)
+	}
+	return targets, err
+}
+
+// PutTarget adds the target file to targets role metadata of the repository or replaces its description,
+// the next version of the metadata is published
+func (svc *TargetsService) PutTarget(ctx context.Context, repoID data.RepoID, path string, meta data.TargetFileMeta) (*data.SignedRole, error) {
+	if err := data.ValidateTargetPath(path); err != nil {
+		return nil, err
+	}
+	if err := meta.Validate(); err != nil {
+		return nil, err
+	}
+	return svc.update(ctx, repoID, func(targets *data.TargetsRole) error {
+		targets.Targets[path] = meta
+		return nil
+	})
+}
+
+// DeleteTarget removes the target file from targets role metadata of the repository,
+// the next version of the metadata is published
+func (svc *TargetsService) DeleteTarget(ctx context.Context, repoID data.RepoID, path string) (*data.SignedRole, error) {
+	return svc.update(ctx, repoID, func(targets *data.TargetsRole) error {
+		if _, ok := targets.Targets[path]; !ok {
+			return apperrors.NewAppError(errcodes.ErrorSvcTargetNotFound, "target '"+path+"' does not exist")
+		}
+		delete(targets.Targets, path)
+		return nil
+	})
+}
+
+// update publishes the next version of targets role metadata changed by modify,
+// the change is reapplied to the new latest version if the version is published by concurrent request
+func (svc *TargetsService) update(ctx context.Context, repoID data.RepoID, modify func(targets *data.TargetsRole) error) (*data.SignedRole, error) {
+	for attempt := 1; ; attempt++ {
+		targets, err := svc.nextVersion(ctx, repoID)
+		if err != nil {
+			return nil, err
+		}
+		if err = modify(targets); err != nil {
+			return nil, err
+		}
+		signed, err := svc.publish(ctx, repoID, targets)
+		if isAlreadyExist(err) && attempt < maxPublishAttempts {
+			continue
+		}
+		return signed, err
+	}
+}
+
+// nextVersion returns signed portion of the next version of targets role metadata,
+// targets of the latest published version are kept
+func (svc *TargetsService) nextVersion(ctx context.Context, repoID data.RepoID) (*data.TargetsRole, error) {
+	latest, err := svc.roleRepo.FindLatest(ctx, repoID, data.RoleTypeTargets)
+	if isNotFound(err) {
+		return data.NewTargetsRole(1), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var targets data.TargetsRole
+	if err = latest.Content.DecodeSigned(&targets); err != nil {
+		return nil, err
+	}
+	next := data.NewTargetsRole(latest.Version + 1)
+	for path, meta := range targets.Targets {
+		next.Targets[path] = meta
+	}
+	next.Delegations = targets.Delegations
+	return next, nil
+}
+
+// publish signs and persists targets role metadata, snapshot and timestamp role metadata are regenerated
+func (svc *TargetsService) publish(ctx context.Context, repoID data.RepoID, targets *data.TargetsRole) (*data.SignedRole, error) {
+	obj, err := svc.publishRole(ctx, repoID, targets.RoleHeader, targets)
+	if err != nil {
+		return nil, err
+	}
+	svc.refreshSnapshot(ctx, repoID, obj)
+	return obj, nil
+}
+
+// refreshSnapshot publishes snapshot and timestamp role metadata listing just published targets role metadata
+func (svc *TargetsService) refreshSnapshot(ctx context.Context, repoID data.RepoID, targets *data.SignedRole) {
+	if _, err := svc.publishSnapshot(ctx, repoID, targets); err != nil {
+		// targets are already published, stale snapshot is republished on the next request of it
+		svc.log.WithContext(ctx).
+			WithField("RepoID", repoID).
+			WithError(err).
+			Warn("Failed to publish snapshot role")
+	}
+}
+
+// publishRole signs role metadata with keys of the role and persists it
+func (svc *TargetsService) publishRole(ctx context.Context, repoID data.RepoID, header data.RoleHeader, role interface{}) (*data.SignedRole, error) {
+	log := svc.log.WithContext(ctx).
+		WithField("RepoID", repoID).
+		WithField("Role", header.Type).
+		WithField("Version", header.Version)
+	payload, err := json.Marshal(role)
+	if err != nil {
+		return nil, apperrors.CreateError(errcodes.ErrorDataSigning, "failed to marshal payload: ", err)
+	}
+	sigs, err := svc.keySvc.SignRolePayload(ctx, repoID, header.Type, payload)
+	if err != nil {
+		return nil, err
+	}
+	obj := data.SignedRole{
+		RepoID:    repoID,
+		Role:      header.Type,
+		Version:   header.Version,
+		ExpiresAt: header.Expires,
+		Content: data.SignedPayload{
+			Signatures: sigs,
+			Signed:     payload,
+		},
+	}
+	if err = svc.roleRepo.Create(ctx, obj); err != nil {
+		return nil, err
+	}
+	log.Info("Role published")
+	return &obj, nil
+}
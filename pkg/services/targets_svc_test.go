@@ -0,0 +1,119 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/shuvava/ota-tuf-server/pkg/data"
+	"github.com/shuvava/ota-tuf-server/pkg/encryption"
+)
+
+// verifyTargets checks that targets metadata is signed by targets keys of the latest root and returns its signed portion
+func (s *testServices) verifyTargets(t *testing.T, signed *data.SignedRole) data.TargetsRole {
+	t.Helper()
+	ctx := context.Background()
+	rootRole, err := s.rootSvc.GetSignedRoot(ctx, signed.RepoID)
+	if err != nil {
+		t.Fatalf("unable to get root: %v", err)
+	}
+	var root data.RootRole
+	if err = rootRole.Content.DecodeSigned(&root); err != nil {
+		t.Fatalf("unable to decode root: %v", err)
+	}
+	if err = encryption.VerifyPayload(&signed.Content, root.Keys, root.Roles[data.RoleTypeTargets]); err != nil {
+		t.Errorf("expected targets signed by targets keys, got %v", err)
+	}
+	var targets data.TargetsRole
+	if err = signed.Content.DecodeSigned(&targets); err != nil {
+		t.Fatalf("unable to decode targets: %v", err)
+	}
+	if err = targets.Validate(); err != nil {
+		t.Errorf("expected valid targets, got %v", err)
+	}
+	if targets.Version != signed.Version {
+		t.Errorf("expected version %d, got %d", signed.Version, targets.Version)
+	}
+	return targets
+}
+
+func TestTargetsService(t *testing.T) {
+	ctx := context.Background()
+	meta := data.TargetFileMeta{
+		Length: 5,
+		Hashes: data.NewHashes([]byte("hello")),
+		Custom: []byte(`{"hardwareIds":["board"]}`),
+	}
+
+	t.Run("should publish empty targets of a new repository", func(t *testing.T) {
+		s := newTestServices()
+		repoID := s.createTestRepo(t)
+		signed, err := s.targetsSvc.GetSignedTargets(ctx, repoID)
+		if err != nil {
+			t.Fatalf("unable to get targets: %v", err)
+		}
+		if targets := s.verifyTargets(t, signed); signed.Version != 1 || len(targets.Targets) != 0 {
+			t.Errorf("expected empty version 1, got version %d with %d targets", signed.Version, len(targets.Targets))
+		}
+	})
+	t.Run("should publish the next version on every change", func(t *testing.T) {
+		s := newTestServices()
+		repoID := s.createTestRepo(t)
+		if _, err := s.targetsSvc.PutTarget(ctx, repoID, "firmware/a.bin", meta); err != nil {
+			t.Fatalf("unable to add target: %v", err)
+		}
+		replaced := meta
+		replaced.Length = 6
+		replaced.Hashes = data.NewHashes([]byte("hello!"))
+		if _, err := s.targetsSvc.PutTarget(ctx, repoID, "firmware/a.bin", replaced); err != nil {
+			t.Fatalf("unable to replace target: %v", err)
+		}
+		if _, err := s.targetsSvc.PutTarget(ctx, repoID, "firmware/b.bin", meta); err != nil {
+			t.Fatalf("unable to add target: %v", err)
+		}
+		signed, err := s.targetsSvc.DeleteTarget(ctx, repoID, "firmware/b.bin")
+		if err != nil {
+			t.Fatalf("unable to delete target: %v", err)
+		}
+		if signed.Version != 4 {
+			t.Errorf("expected version 4, got %d", signed.Version)
+		}
+		targets := s.verifyTargets(t, signed)
+		if len(targets.Targets) != 1 {
+			t.Fatalf("expected 1 target, got %d", len(targets.Targets))
+		}
+		got := targets.Targets["firmware/a.bin"]
+		if got.Length != 6 || got.Hashes[data.HashAlgorithmSHA256].String() != replaced.Hashes[data.HashAlgorithmSHA256].String() {
+			t.Errorf("expected replaced target, got %v", got)
+		}
+		if string(got.Custom) != string(meta.Custom) {
+			t.Errorf("expected custom %s, got %s", meta.Custom, got.Custom)
+		}
+		latest, _ := s.targetsSvc.GetSignedTargets(ctx, repoID)
+		if latest.Version != 4 {
+			t.Errorf("expected latest version 4, got %d", latest.Version)
+		}
+	})
+	t.Run("should reject invalid target", func(t *testing.T) {
+		s := newTestServices()
+		repoID := s.createTestRepo(t)
+		if _, err := s.targetsSvc.PutTarget(ctx, repoID, "/abs.bin", meta); err == nil {
+			t.Error("expected error, got nil")
+		}
+		if _, err := s.targetsSvc.PutTarget(ctx, repoID, "a.bin", data.TargetFileMeta{Length: 5}); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+	t.Run("should fail to delete missing target", func(t *testing.T) {
+		s := newTestServices()
+		repoID := s.createTestRepo(t)
+		if _, err := s.targetsSvc.DeleteTarget(ctx, repoID, "missing.bin"); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+	t.Run("should fail for not existing repository", func(t *testing.T) {
+		s := newTestServices()
+		if _, err := s.targetsSvc.PutTarget(ctx, data.NewRepoID(), "a.bin", meta); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+}
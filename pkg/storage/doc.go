@@ -0,0 +1,2 @@
+// Package storage contains the blob storage API keeping content of target files.
+package storage
@@ -0,0 +1,3 @@
+// Package local implements storage.Storage keeping blobs as files in a local directory.
+// Content is written to a temporary file which is renamed to the blob path when it is complete.
+package local
@@ -0,0 +1,134 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/shuvava/go-ota-svc-common/apperrors"
+
+	"github.com/shuvava/ota-tuf-server/pkg/errcodes"
+	"github.com/shuvava/ota-tuf-server/pkg/storage"
+)
+
+const (
+	dirPerm  = 0o755
+	tempName = ".upload-*"
+)
+
+// Storage is storage.Storage keeping blobs in the root directory
+type Storage struct {
+	root string
+}
+
+// New creates Storage keeping blobs in the root directory, the directory is created if it does not exist
+func New(root string) (*Storage, error) {
+	if root == "" {
+		return nil, apperrors.NewAppError(apperrors.ErrorDataValidation, "storage root directory is not set")
+	}
+	if err := os.MkdirAll(root, dirPerm); err != nil {
+		return nil, apperrors.CreateError(apperrors.ErrorFsIOCreate, "failed to create storage root directory", err)
+	}
+	return &Storage{root: root}, nil
+}
+
+// Put stores content of the blob, size is the content length or -1 if it is unknown.
+// Existing blob is replaced, new content is not visible until it is stored completely.
+func (s *Storage) Put(_ context.Context, key string, r io.Reader, size int64) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(path)
+	if err = os.MkdirAll(dir, dirPerm); err != nil {
+		return apperrors.CreateError(apperrors.ErrorFsIOCreate, "failed to create blob directory", err)
+	}
+	tmp, err := os.CreateTemp(dir, tempName)
+	if err != nil {
+		return apperrors.CreateError(apperrors.ErrorFsIOCreate, "failed to create blob file", err)
+	}
+	defer func() {
+		// no-op if the file is renamed
+		_ = os.Remove(tmp.Name())
+	}()
+	n, err := io.Copy(tmp, r)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return apperrors.CreateError(errcodes.ErrorFsBlobStorage, "failed to write blob", err)
+	}
+	if size >= 0 && n != size {
+		return apperrors.NewAppError(apperrors.ErrorDataValidation,
+			"blob length "+strconv.FormatInt(n, 10)+" does not match expected length "+strconv.FormatInt(size, 10))
+	}
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		return apperrors.CreateError(errcodes.ErrorFsBlobStorage, "failed to store blob", err)
+	}
+	return nil
+}
+
+// Open returns content of the blob
+func (s *Storage) Open(_ context.Context, key string) (storage.Blob, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, apperrors.NewAppError(errcodes.ErrorFsBlobNotFound, "blob '"+key+"' does not exist")
+	}
+	if err != nil {
+		return nil, apperrors.CreateError(apperrors.ErrorFsIOOpen, "failed to open blob", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, apperrors.CreateError(apperrors.ErrorFsIOOpen, "failed to open blob", err)
+	}
+	return &blob{File: f, info: info}, nil
+}
+
+// Delete removes the blob, missing blob is not an error
+func (s *Storage) Delete(_ context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err = os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return apperrors.CreateError(errcodes.ErrorFsBlobStorage, "failed to delete blob", err)
+	}
+	return nil
+}
+
+// path returns path of the blob file
+func (s *Storage) path(key string) (string, error) {
+	if err := storage.ValidateKey(key); err != nil {
+		return "", err
+	}
+	return filepath.Join(s.root, filepath.FromSlash(key)), nil
+}
+
+// blob is storage.Blob of the file
+type blob struct {
+	*os.File
+	info fs.FileInfo
+}
+
+// Size returns the content length
+func (b *blob) Size() int64 {
+	return b.info.Size()
+}
+
+// ModTime returns the time the content was stored
+func (b *blob) ModTime() time.Time {
+	return b.info.ModTime()
+}
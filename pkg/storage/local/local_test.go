@@ -0,0 +1,98 @@
+package local_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shuvava/go-ota-svc-common/apperrors"
+
+	"github.com/shuvava/ota-tuf-server/pkg/errcodes"
+	"github.com/shuvava/ota-tuf-server/pkg/storage/local"
+)
+
+func TestStorage(t *testing.T) {
+	ctx := context.Background()
+	content := []byte("0123456789abcdef")
+	newStorage := func(t *testing.T) (*local.Storage, string) {
+		root := filepath.Join(t.TempDir(), "blobs")
+		s, err := local.New(root)
+		if err != nil {
+			t.Fatalf("unable to create storage: %v", err)
+		}
+		return s, root
+	}
+
+	t.Run("should store and read blob", func(t *testing.T) {
+		s, _ := newStorage(t)
+		for _, size := range []int64{int64(len(content)), -1} {
+			if err := s.Put(ctx, "repo/dir/file.bin", bytes.NewReader(content), size); err != nil {
+				t.Fatalf("unable to put blob: %v", err)
+			}
+			b, err := s.Open(ctx, "repo/dir/file.bin")
+			if err != nil {
+				t.Fatalf("unable to open blob: %v", err)
+			}
+			got, _ := io.ReadAll(b)
+			_ = b.Close()
+			if !bytes.Equal(got, content) || b.Size() != int64(len(content)) {
+				t.Errorf("expected %q of size %d, got %q of size %d", content, len(content), got, b.Size())
+			}
+		}
+	})
+	t.Run("should seek in blob", func(t *testing.T) {
+		s, _ := newStorage(t)
+		_ = s.Put(ctx, "repo/file.bin", bytes.NewReader(content), -1)
+		b, _ := s.Open(ctx, "repo/file.bin")
+		defer b.Close()
+		if _, err := b.Seek(10, io.SeekStart); err != nil {
+			t.Fatalf("unable to seek: %v", err)
+		}
+		got, _ := io.ReadAll(b)
+		if string(got) != "abcdef" {
+			t.Errorf("expected abcdef, got %q", got)
+		}
+	})
+	t.Run("should keep previous content on length mismatch", func(t *testing.T) {
+		s, root := newStorage(t)
+		_ = s.Put(ctx, "repo/file.bin", bytes.NewReader(content), -1)
+		if err := s.Put(ctx, "repo/file.bin", bytes.NewReader([]byte("short")), 10); err == nil {
+			t.Error("expected error, got nil")
+		}
+		got, _ := os.ReadFile(filepath.Join(root, "repo", "file.bin"))
+		if !bytes.Equal(got, content) {
+			t.Errorf("expected %q, got %q", content, got)
+		}
+		entries, _ := os.ReadDir(filepath.Join(root, "repo"))
+		if len(entries) != 1 {
+			t.Errorf("expected temporary file removed, got %d files", len(entries))
+		}
+	})
+	t.Run("should delete blob", func(t *testing.T) {
+		s, _ := newStorage(t)
+		_ = s.Put(ctx, "repo/file.bin", bytes.NewReader(content), -1)
+		if err := s.Delete(ctx, "repo/file.bin"); err != nil {
+			t.Fatalf("unable to delete blob: %v", err)
+		}
+		_, err := s.Open(ctx, "repo/file.bin")
+		var typedErr apperrors.AppError
+		if !errors.As(err, &typedErr) || typedErr.ErrorCode != errcodes.ErrorFsBlobNotFound {
+			t.Errorf("expected %s error, got %v", errcodes.ErrorFsBlobNotFound, err)
+		}
+		if err = s.Delete(ctx, "repo/file.bin"); err != nil {
+			t.Errorf("expected missing blob deleted, got %v", err)
+		}
+	})
+	t.Run("should reject keys outside of root", func(t *testing.T) {
+		s, _ := newStorage(t)
+		for _, key := range []string{"", "/etc/passwd", "repo/../../file.bin", "repo//file.bin", "repo\\file.bin"} {
+			if err := s.Put(ctx, key, bytes.NewReader(content), -1); err == nil {
+				t.Errorf("expected error on key %q, got nil", key)
+			}
+		}
+	})
+}
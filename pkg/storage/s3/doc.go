@@ -0,0 +1,3 @@
+// Package s3 implements storage.Storage keeping blobs in a bucket of Amazon S3 or S3 compatible server, e.g. MinIO.
+// Requests are signed by AWS Signature Version 4, content is uploaded as unsigned payload.
+package s3
@@ -0,0 +1,285 @@
+package s3
+
+import (
+	"context"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shuvava/go-ota-svc-common/apperrors"
+
+	"github.com/shuvava/ota-tuf-server/pkg/errcodes"
+	"github.com/shuvava/ota-tuf-server/pkg/storage"
+)
+
+const (
+	defaultRegion = "us-east-1"
+	// spoolPattern is the pattern of temporary files keeping content of unknown length before upload
+	spoolPattern = "tuf-blob-*"
+)
+
+// Config is S3 bucket configuration
+type Config struct {
+	// Endpoint is the S3 server address, e.g. https://s3.eu-west-1.amazonaws.com or http://minio:9000
+	Endpoint string
+	// Region is the region of the bucket, default is `us-east-1`
+	Region string
+	// Bucket is the name of the bucket
+	Bucket string
+	// AccessKey is the access key id
+	AccessKey string
+	// SecretKey is the secret access key
+	SecretKey string
+	// PathStyle addresses the bucket in the request path instead of the host name,
+	// it is required by most S3 compatible servers
+	PathStyle bool
+}
+
+// Storage is storage.Storage keeping blobs in S3 bucket
+type Storage struct {
+	cfg      Config
+	endpoint *url.URL
+	http     *http.Client
+}
+
+// errorResponse is the response of failed S3 request
+type errorResponse struct {
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+// New creates Storage keeping blobs in S3 bucket
+func New(cfg Config) (*Storage, error) {
+	if cfg.Endpoint == "" || cfg.Bucket == "" {
+		return nil, apperrors.NewAppError(apperrors.ErrorDataValidation, "S3 endpoint and bucket have to be set")
+	}
+	endpoint, err := url.Parse(strings.TrimRight(cfg.Endpoint, "/"))
+	if err != nil || endpoint.Host == "" {
+		return nil, apperrors.NewAppError(apperrors.ErrorDataValidation, "S3 endpoint '"+cfg.Endpoint+"' is invalid")
+	}
+	if cfg.Region == "" {
+		cfg.Region = defaultRegion
+	}
+	// transfers of large blobs are limited by request context only
+	return &Storage{
+		cfg:      cfg,
+		endpoint: endpoint,
+		http:     &http.Client{},
+	}, nil
+}
+
+// Close releases idle connections to S3 server
+func (s *Storage) Close() error {
+	s.http.CloseIdleConnections()
+	return nil
+}
+
+// Put stores content of the blob, size is the content length or -1 if it is unknown.
+// Content of unknown length is spooled to a temporary file, S3 requires length of uploaded object.
+func (s *Storage) Put(ctx context.Context, key string, r io.Reader, size int64) error {
+	if err := storage.ValidateKey(key); err != nil {
+		return err
+	}
+	if size < 0 {
+		spool, n, err := spoolContent(r)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = spool.Close()
+			_ = os.Remove(spool.Name())
+		}()
+		r, size = spool, n
+	}
+	body := io.NopCloser(r)
+	if size == 0 {
+		body = http.NoBody
+	}
+	req, err := s.newRequest(ctx, http.MethodPut, key, body)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", "application/octet-stream")
+	resp, err := s.do(req, UnsignedPayload, http.StatusOK)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// Open returns content of the blob, content is requested on the first read from the current position
+func (s *Storage) Open(ctx context.Context, key string) (storage.Blob, error) {
+	if err := storage.ValidateKey(key); err != nil {
+		return nil, err
+	}
+	req, err := s.newRequest(ctx, http.MethodHead, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.do(req, EmptyPayload, http.StatusOK)
+	if err != nil {
+		return nil, err
+	}
+	_ = resp.Body.Close()
+	modTime, _ := http.ParseTime(resp.Header.Get("Last-Modified"))
+	return &blob{
+		ctx:     ctx,
+		storage: s,
+		key:     key,
+		size:    resp.ContentLength,
+		modTime: modTime,
+	}, nil
+}
+
+// Delete removes the blob, missing blob is not an error
+func (s *Storage) Delete(ctx context.Context, key string) error {
+	if err := storage.ValidateKey(key); err != nil {
+		return err
+	}
+	req, err := s.newRequest(ctx, http.MethodDelete, key, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := s.do(req, EmptyPayload, http.StatusNoContent, http.StatusOK, http.StatusNotFound)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// newRequest creates request of the object
+func (s *Storage) newRequest(ctx context.Context, method, key string, body io.Reader) (*http.Request, error) {
+	u := *s.endpoint
+	path := "/" + key
+	if s.cfg.PathStyle {
+		path = "/" + s.cfg.Bucket + path
+	} else {
+		u.Host = s.cfg.Bucket + "." + u.Host
+	}
+	u.Path = strings.TrimRight(u.Path, "/") + path
+	u.RawPath = encodePath(u.Path)
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, apperrors.CreateError(errcodes.ErrorFsBlobStorage, "failed to create S3 request", err)
+	}
+	return req, nil
+}
+
+// do signs and sends the request, response with status other than expected is returned as error
+func (s *Storage) do(req *http.Request, payloadHash string, expected ...int) (*http.Response, error) {
+	SignRequest(req, s.cfg.AccessKey, s.cfg.SecretKey, s.cfg.Region, payloadHash, time.Now())
+	resp, err := s.http.Do(req)
+	if err != nil {
+		return nil, apperrors.CreateError(errcodes.ErrorFsBlobStorage, "S3 request failed", err)
+	}
+	for _, code := range expected {
+		if resp.StatusCode == code {
+			return resp, nil
+		}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, apperrors.NewAppError(errcodes.ErrorFsBlobNotFound, "blob does not exist")
+	}
+	msg := "S3 request failed with status " + resp.Status
+	var errResp errorResponse
+	if xml.NewDecoder(resp.Body).Decode(&errResp) == nil && errResp.Code != "" {
+		msg += ": " + errResp.Code + " " + errResp.Message
+	}
+	return nil, apperrors.NewAppError(errcodes.ErrorFsBlobStorage, msg)
+}
+
+// spoolContent copies content to a temporary file and returns the file positioned at the beginning
+func spoolContent(r io.Reader) (*os.File, int64, error) {
+	f, err := os.CreateTemp("", spoolPattern)
+	if err != nil {
+		return nil, 0, apperrors.CreateError(apperrors.ErrorFsIOCreate, "failed to create temporary file", err)
+	}
+	n, err := io.Copy(f, r)
+	if err == nil {
+		_, err = f.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+		return nil, 0, apperrors.CreateError(errcodes.ErrorFsBlobStorage, "failed to write temporary file", err)
+	}
+	return f, n, nil
+}
+
+// blob is storage.Blob of S3 object, content is read by ranged GET requests
+type blob struct {
+	ctx     context.Context
+	storage *Storage
+	key     string
+	size    int64
+	modTime time.Time
+	offset  int64
+	body    io.ReadCloser
+}
+
+// Read reads content from the current position
+func (b *blob) Read(p []byte) (int, error) {
+	if b.offset >= b.size {
+		return 0, io.EOF
+	}
+	if b.body == nil {
+		req, err := b.storage.newRequest(b.ctx, http.MethodGet, b.key, nil)
+		if err != nil {
+			return 0, err
+		}
+		req.Header.Set("Range", "bytes="+strconv.FormatInt(b.offset, 10)+"-")
+		resp, err := b.storage.do(req, EmptyPayload, http.StatusPartialContent)
+		if err != nil {
+			return 0, err
+		}
+		b.body = resp.Body
+	}
+	n, err := b.body.Read(p)
+	b.offset += int64(n)
+	return n, err
+}
+
+// Seek sets the position of the next read, content is requested again on the next read
+func (b *blob) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		offset += b.offset
+	case io.SeekEnd:
+		offset += b.size
+	}
+	if offset < 0 {
+		return 0, apperrors.NewAppError(apperrors.ErrorDataValidation, "negative blob position")
+	}
+	if offset != b.offset {
+		_ = b.Close()
+		b.offset = offset
+	}
+	return offset, nil
+}
+
+// Close releases content requested by the last read
+func (b *blob) Close() error {
+	if b.body == nil {
+		return nil
+	}
+	err := b.body.Close()
+	b.body = nil
+	return err
+}
+
+// Size returns the content length
+func (b *blob) Size() int64 {
+	return b.size
+}
+
+// ModTime returns the time the content was stored
+func (b *blob) ModTime() time.Time {
+	return b.modTime
+}
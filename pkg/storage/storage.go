@@ -0,0 +1,43 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/shuvava/go-ota-svc-common/apperrors"
+)
+
+// Storage keeps content of target files by keys, keys are slash separated relative paths
+type Storage interface {
+	// Put stores content of the blob, size is the content length or -1 if it is unknown.
+	// Existing blob is replaced, new content is not visible until it is stored completely.
+	Put(ctx context.Context, key string, r io.Reader, size int64) error
+	// Open returns content of the blob, errcodes.ErrorFsBlobNotFound is returned if the blob does not exist
+	Open(ctx context.Context, key string) (Blob, error)
+	// Delete removes the blob, missing blob is not an error
+	Delete(ctx context.Context, key string) error
+}
+
+// Blob is stored content of target file allowing random access
+type Blob interface {
+	io.ReadSeekCloser
+	// Size returns the content length
+	Size() int64
+	// ModTime returns the time the content was stored
+	ModTime() time.Time
+}
+
+// ValidateKey checks that the key is a relative path without empty, `.` and `..` segments
+func ValidateKey(key string) error {
+	if key == "" || strings.ContainsAny(key, "\\\x00") {
+		return apperrors.NewAppError(apperrors.ErrorDataValidation, "blob key '"+key+"' is invalid")
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return apperrors.NewAppError(apperrors.ErrorDataValidation, "blob key '"+key+"' is invalid")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,7 @@
+#!/usr/bin/env bash
+set -Eeuo pipefail
+
+TUF_REPO_URL=${TUF_REPO_URL:-"http://localhost:8080"}
+uuid=${1:?"Usage: get_resign_records.sh <repoID>"}
+URL="${TUF_REPO_URL}/api/v1/repo/${uuid}/resign_records"
+curl -H "Accept: application/json" "${URL}"
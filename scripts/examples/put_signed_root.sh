@@ -0,0 +1,8 @@
+#!/usr/bin/env bash
+set -Eeuo pipefail
+
+TUF_REPO_URL=${TUF_REPO_URL:-"http://localhost:8080"}
+uuid=${1:?"Usage: put_signed_root.sh <repoID> <root.json>"}
+file=${2:?"Usage: put_signed_root.sh <repoID> <root.json>"}
+URL="${TUF_REPO_URL}/api/v1/root/${uuid}"
+curl -X PUT -H "Content-Type: application/json" --data-binary "@${file}" "${URL}"